@@ -0,0 +1,96 @@
+package intasend
+
+import (
+	"context"
+)
+
+// CallOption customises a single API call
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	ctx      context.Context
+	response *HTTPResponse
+	headers  map[string]string
+}
+
+// WithContext sets the context used for cancellation, deadlines and tracing
+func WithContext(ctx context.Context) CallOption {
+	return func(o *callOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithResponse captures the raw response metadata (status, headers, body,
+// latency and request ID) into dst. dst is filled for API errors too, as long
+// as a response was received.
+func WithResponse(dst *HTTPResponse) CallOption {
+	return func(o *callOptions) {
+		o.response = dst
+	}
+}
+
+// WithHeader adds a custom header to the request
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string)
+		}
+		o.headers[key] = value
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header so that a retried
+// request is applied at most once
+func WithIdempotencyKey(key string) CallOption {
+	return WithHeader("Idempotency-Key", key)
+}
+
+// CallSettings is the outcome of applying a set of CallOptions. It lets code
+// that accepts options without sending a request, such as test fakes, see
+// what a caller asked for.
+type CallSettings struct {
+	Context  context.Context   // never nil
+	Response *HTTPResponse     // set by WithResponse
+	Headers  map[string]string // set by WithHeader and WithIdempotencyKey
+}
+
+// ResolveCallOptions applies opts in order and returns the resulting settings
+func ResolveCallOptions(opts ...CallOption) CallSettings {
+	co := newCallOptions(opts)
+	return CallSettings{Context: co.ctx, Response: co.response, Headers: co.headers}
+}
+
+func newCallOptions(opts []CallOption) *callOptions {
+	co := &callOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(co)
+		}
+	}
+	if co.ctx == nil {
+		co.ctx = context.Background()
+	}
+	return co
+}
+
+// call performs a request described by ro with the per-call options applied
+func (c *Client) call(ro *RequestOptions, result interface{}, opts []CallOption) error {
+	co := newCallOptions(opts)
+	ro.Context = co.ctx
+	if len(co.headers) > 0 {
+		headers := make(map[string]string, len(ro.Headers)+len(co.headers))
+		for k, v := range ro.Headers {
+			headers[k] = v
+		}
+		for k, v := range co.headers {
+			headers[k] = v
+		}
+		ro.Headers = headers
+	}
+
+	resp, err := c.doRequestWithJSON(ro, result)
+	if co.response != nil && resp != nil {
+		*co.response = *resp
+	}
+	return err
+}
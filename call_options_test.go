@@ -0,0 +1,69 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-123")
+		if r.URL.Path == "/api/v1/invoices/MISSING/" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Invoice not found"}`))
+			return
+		}
+		if r.Header.Get("X-Tenant") != "acme" {
+			t.Errorf("Expected X-Tenant header, got %q", r.Header.Get("X-Tenant"))
+		}
+		w.Write([]byte(`{"invoice_id":"INV1","state":"COMPLETE"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient("pk", "token", true, false)
+	client.BaseURL = srv.URL
+
+	var meta HTTPResponse
+	invoice, err := client.GetInvoice("INV1", WithResponse(&meta), WithHeader("X-Tenant", "acme"))
+	if err != nil {
+		t.Fatalf("GetInvoice failed: %v", err)
+	}
+	if invoice.InvoiceID != "INV1" {
+		t.Errorf("Expected INV1, got %s", invoice.InvoiceID)
+	}
+	if meta.StatusCode != http.StatusOK || meta.RequestID() != "req-123" || len(meta.Body) == 0 || meta.Latency <= 0 {
+		t.Errorf("Unexpected response metadata: %+v", meta)
+	}
+
+	var errMeta HTTPResponse
+	_, err = client.GetInvoice("MISSING", WithResponse(&errMeta))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "Invoice not found" || apiErr.RequestID != "req-123" {
+		t.Errorf("Unexpected API error: %+v", apiErr)
+	}
+	if errMeta.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected metadata for error response, got %+v", errMeta)
+	}
+}
+
+func TestWithContextCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient("pk", "token", true, false)
+	client.BaseURL = srv.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ListWallets(nil, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker for one endpoint family
+type CircuitState int
+
+// Circuit breaker states
+const (
+	CircuitClosed   CircuitState = iota // requests flow normally
+	CircuitOpen                         // requests fail fast with ErrCircuitOpen
+	CircuitHalfOpen                     // a limited number of trial requests are let through
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// ErrCircuitOpen is matched (via errors.Is) by errors returned while a circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// errNotSent releases a circuit slot for a request that was never sent
+var errNotSent = errors.New("request not sent")
+
+// CircuitOpenError is returned instead of sending a request while the
+// circuit for its endpoint family is open
+type CircuitOpenError struct {
+	Family     EndpointFamily
+	RetryAfter time.Duration // time left until the circuit lets a trial request through
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open for %s, retry in %s", e.Family, e.RetryAfter.Round(time.Millisecond))
+}
+
+// Is reports whether target is ErrCircuitOpen
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitSettings configures the breaker for one endpoint family
+type CircuitSettings struct {
+	FailureThreshold    int           // consecutive failures that open the circuit
+	Cooldown            time.Duration // how long the circuit stays open before going half-open
+	HalfOpenMaxRequests int           // concurrent trial requests allowed while half-open
+	SuccessThreshold    int           // consecutive trial successes needed to close the circuit
+}
+
+// DefaultCircuitSettings is used when a CircuitBreakerConfig leaves Default unset
+var DefaultCircuitSettings = CircuitSettings{
+	FailureThreshold:    5,
+	Cooldown:            30 * time.Second,
+	HalfOpenMaxRequests: 1,
+	SuccessThreshold:    1,
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker
+type CircuitBreakerConfig struct {
+	Default   CircuitSettings                    // settings for families without an override
+	PerFamily map[EndpointFamily]CircuitSettings // optional per-family overrides
+
+	// OnStateChange is called after a family's circuit changes state. It is
+	// called synchronously from the request path, so it should not block.
+	OnStateChange func(family EndpointFamily, from, to CircuitState)
+
+	// IsFailure decides whether an outcome counts against the circuit. resp
+	// is nil when err is a transport error. By default transport errors and
+	// 5xx responses are failures. Requests cancelled by the caller are ignored.
+	IsFailure func(resp *HTTPResponse, err error) bool
+}
+
+// CircuitBreaker fails requests fast while IntaSend is failing, tracking a
+// separate circuit per endpoint family
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	circuits map[EndpointFamily]*circuit
+}
+
+type circuit struct {
+	settings  CircuitSettings
+	state     CircuitState
+	failures  int
+	successes int
+	inFlight  int
+	openedAt  time.Time
+}
+
+type stateChange struct {
+	family   EndpointFamily
+	from, to CircuitState
+}
+
+// NewCircuitBreaker creates a circuit breaker from the given configuration
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	cfg.Default = cfg.Default.withDefaults()
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = defaultIsFailure
+	}
+	return &CircuitBreaker{
+		cfg:      cfg,
+		now:      time.Now,
+		circuits: make(map[EndpointFamily]*circuit),
+	}
+}
+
+func (s CircuitSettings) withDefaults() CircuitSettings {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = DefaultCircuitSettings.FailureThreshold
+	}
+	if s.Cooldown <= 0 {
+		s.Cooldown = DefaultCircuitSettings.Cooldown
+	}
+	if s.HalfOpenMaxRequests <= 0 {
+		s.HalfOpenMaxRequests = DefaultCircuitSettings.HalfOpenMaxRequests
+	}
+	if s.SuccessThreshold <= 0 {
+		s.SuccessThreshold = DefaultCircuitSettings.SuccessThreshold
+	}
+	return s
+}
+
+func defaultIsFailure(resp *HTTPResponse, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// Allow reports whether a request for the family may be sent. If it may, the
+// returned function must be called exactly once with the request's outcome.
+// Otherwise the error is a *CircuitOpenError.
+func (b *CircuitBreaker) Allow(family EndpointFamily) (func(resp *HTTPResponse, err error), error) {
+	b.mu.Lock()
+	now := b.now()
+	c := b.circuit(family)
+
+	var changes []stateChange
+	if c.state == CircuitOpen {
+		if wait := c.openedAt.Add(c.settings.Cooldown).Sub(now); wait > 0 {
+			b.mu.Unlock()
+			return nil, &CircuitOpenError{Family: family, RetryAfter: wait}
+		}
+		changes = append(changes, b.transition(family, c, CircuitHalfOpen, now))
+	}
+	if c.state == CircuitHalfOpen && c.inFlight >= c.settings.HalfOpenMaxRequests {
+		b.mu.Unlock()
+		b.notify(changes)
+		return nil, &CircuitOpenError{Family: family}
+	}
+	c.inFlight++
+	b.mu.Unlock()
+	b.notify(changes)
+
+	var once sync.Once
+	return func(resp *HTTPResponse, err error) {
+		once.Do(func() { b.record(family, resp, err) })
+	}, nil
+}
+
+// State returns the current state of the family's circuit
+func (b *CircuitBreaker) State(family EndpointFamily) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[family]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(c.settings.Cooldown)) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// Reset closes every circuit, e.g. after an operator confirms IntaSend has recovered
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	var changes []stateChange
+	for family, c := range b.circuits {
+		if c.state != CircuitClosed {
+			changes = append(changes, b.transition(family, c, CircuitClosed, b.now()))
+		}
+	}
+	b.mu.Unlock()
+	b.notify(changes)
+}
+
+func (b *CircuitBreaker) record(family EndpointFamily, resp *HTTPResponse, err error) {
+	b.mu.Lock()
+	now := b.now()
+	c := b.circuit(family)
+	c.inFlight--
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, errNotSent) {
+		// Either the caller gave up or nothing reached IntaSend; neither says
+		// anything about its health
+		b.mu.Unlock()
+		return
+	}
+
+	var changes []stateChange
+	if b.cfg.IsFailure(resp, err) {
+		c.successes = 0
+		c.failures++
+		switch c.state {
+		case CircuitHalfOpen:
+			changes = append(changes, b.transition(family, c, CircuitOpen, now))
+		case CircuitClosed:
+			if c.failures >= c.settings.FailureThreshold {
+				changes = append(changes, b.transition(family, c, CircuitOpen, now))
+			}
+		}
+	} else {
+		c.failures = 0
+		if c.state == CircuitHalfOpen {
+			c.successes++
+			if c.successes >= c.settings.SuccessThreshold {
+				changes = append(changes, b.transition(family, c, CircuitClosed, now))
+			}
+		}
+	}
+	b.mu.Unlock()
+	b.notify(changes)
+}
+
+// transition moves a circuit to a new state; b.mu must be held
+func (b *CircuitBreaker) transition(family EndpointFamily, c *circuit, to CircuitState, now time.Time) stateChange {
+	change := stateChange{family: family, from: c.state, to: to}
+	c.state = to
+	c.failures = 0
+	c.successes = 0
+	if to == CircuitOpen {
+		c.openedAt = now
+	}
+	return change
+}
+
+func (b *CircuitBreaker) notify(changes []stateChange) {
+	if b.cfg.OnStateChange == nil {
+		return
+	}
+	for _, ch := range changes {
+		b.cfg.OnStateChange(ch.family, ch.from, ch.to)
+	}
+}
+
+func (b *CircuitBreaker) circuit(family EndpointFamily) *circuit {
+	c, ok := b.circuits[family]
+	if !ok {
+		settings := b.cfg.Default
+		if override, ok := b.cfg.PerFamily[family]; ok {
+			settings = override.withDefaults()
+		}
+		c = &circuit{settings: settings}
+		b.circuits[family] = c
+	}
+	return c
+}
@@ -0,0 +1,125 @@
+package intasend
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var failing atomic.Bool
+	var hits atomic.Int32
+	failing.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"message":"maintenance"}`))
+			return
+		}
+		w.Write([]byte(`{"invoice_id":"INV1","state":"COMPLETE"}`))
+	}))
+	defer srv.Close()
+
+	var changes []string
+	now := time.Now()
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		Default: CircuitSettings{FailureThreshold: 2, Cooldown: time.Minute},
+		OnStateChange: func(family EndpointFamily, from, to CircuitState) {
+			changes = append(changes, string(family)+":"+from.String()+"->"+to.String())
+		},
+	})
+	breaker.now = func() time.Time { return now }
+
+	client := NewClient("pk", "token", true, false)
+	client.BaseURL = srv.URL
+	client.CircuitBreaker = breaker
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetInvoice("INV1"); err == nil {
+			t.Fatal("Expected API error")
+		}
+	}
+	if got := breaker.State(FamilyInvoices); got != CircuitOpen {
+		t.Fatalf("Expected open circuit, got %s", got)
+	}
+
+	_, err := client.GetInvoice("INV1")
+	var openErr *CircuitOpenError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &openErr) || openErr.Family != FamilyInvoices {
+		t.Fatalf("Expected ErrCircuitOpen for invoices, got %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("Expected open circuit to skip the request, got %d hits", hits.Load())
+	}
+
+	// Other families are unaffected
+	if breaker.State(FamilyWallets) != CircuitClosed {
+		t.Error("Expected wallets circuit to stay closed")
+	}
+
+	// After the cooldown a failed trial reopens the circuit
+	now = now.Add(time.Minute)
+	if _, err := client.GetInvoice("INV1"); errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Expected a trial request after cooldown")
+	}
+	if got := breaker.State(FamilyInvoices); got != CircuitOpen {
+		t.Fatalf("Expected failed trial to reopen the circuit, got %s", got)
+	}
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	failing.Store(false)
+	if _, err := client.GetInvoice("INV1"); err != nil {
+		t.Fatalf("Expected trial to succeed, got %v", err)
+	}
+	if got := breaker.State(FamilyInvoices); got != CircuitClosed {
+		t.Fatalf("Expected closed circuit, got %s", got)
+	}
+
+	want := []string{
+		"invoices:closed->open",
+		"invoices:open->half-open",
+		"invoices:half-open->open",
+		"invoices:open->half-open",
+		"invoices:half-open->closed",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected changes %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Change %d: expected %s, got %s", i, want[i], changes[i])
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenLimit(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		Default: CircuitSettings{FailureThreshold: 1, Cooldown: time.Second},
+	})
+	breaker.now = func() time.Time { return now }
+
+	done, err := breaker.Allow(FamilyCheckout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done(nil, errors.New("connection reset"))
+
+	now = now.Add(time.Second)
+	trial, err := breaker.Allow(FamilyCheckout)
+	if err != nil {
+		t.Fatalf("Expected trial request, got %v", err)
+	}
+	if _, err := breaker.Allow(FamilyCheckout); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected second half-open request to be rejected, got %v", err)
+	}
+	trial(&HTTPResponse{StatusCode: http.StatusOK}, nil)
+	if breaker.State(FamilyCheckout) != CircuitClosed {
+		t.Errorf("Expected closed circuit, got %s", breaker.State(FamilyCheckout))
+	}
+}
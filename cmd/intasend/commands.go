@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+	"github.com/techliana/intasend-sdk-golang/intasendrecon"
+)
+
+func runStatus(a *app, args []string) error {
+	fs := a.flags("status")
+	args, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	status, err := client.GetPaymentStatus(args[0])
+	if err != nil {
+		return err
+	}
+	inv := status.Invoice
+	return a.print(status, fields(
+		"Invoice", inv.InvoiceID,
+		"State", inv.State,
+		"Provider", orDash(inv.Provider),
+		"Amount", money(inv.Value)+" "+inv.Currency,
+		"Charges", money(inv.Charges),
+		"Net amount", orDash(inv.NetAmount),
+		"Account", orDash(inv.Account),
+		"API ref", orDash(inv.APIRef),
+		"Customer", orDash(status.GetCustomerName()),
+		"Failed reason", orDash(status.GetFailureReason()),
+		"Created", orDash(inv.CreatedAt),
+		"Updated", orDash(inv.UpdatedAt),
+	))
+}
+
+func runInvoicesList(a *app, args []string) error {
+	fs := a.flags("invoices list")
+	params := &intasend.ListInvoicesParams{}
+	fs.StringVar(&params.State, "state", "", "filter by state (PENDING, PROCESSING, COMPLETE, FAILED)")
+	fs.StringVar(&params.Currency, "currency", "", "filter by currency")
+	fs.StringVar(&params.APIRef, "api-ref", "", "filter by API reference")
+	page, pageSize := fs.Int("page", 0, "page number"), fs.Int("page-size", 0, "page size")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	params.Page, params.PageSize = optional(*page), optional(*pageSize)
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.ListInvoices(params)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"INVOICE", "STATE", "PROVIDER", "AMOUNT", "CURRENCY", "ACCOUNT", "API REF", "CREATED"}}
+	for _, inv := range result.Results {
+		t.add(inv.InvoiceID, inv.State, inv.Provider, money(inv.Value), inv.Currency, orDash(inv.Account), orDash(inv.APIRef), timestamp(inv.CreatedAt))
+	}
+	t.footer = pageFooter(len(result.Results), result.Count, result.Next)
+	return a.print(result, t)
+}
+
+func runTransactionsList(a *app, args []string) error {
+	fs := a.flags("transactions list")
+	params := &intasend.ListTransactionsParams{}
+	fs.StringVar(&params.DateFrom, "from", "", "start date, YYYY-MM-DD")
+	fs.StringVar(&params.DateTo, "to", "", "end date, YYYY-MM-DD")
+	fs.StringVar(&params.TransType, "type", "", "filter by type (DEPOSIT, WITHDRAWAL, TRANSFER, CHARGE, REFUND)")
+	fs.StringVar(&params.Status, "status", "", "filter by status")
+	fs.StringVar(&params.WalletID, "wallet", "", "filter by wallet ID")
+	fs.StringVar(&params.Currency, "currency", "", "filter by currency")
+	page, pageSize := fs.Int("page", 0, "page number"), fs.Int("page-size", 0, "page size")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := checkDateRange(params.DateFrom, params.DateTo); err != nil {
+		return err
+	}
+	params.Page, params.PageSize = optional(*page), optional(*pageSize)
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.ListTransactions(params)
+	if err != nil {
+		return err
+	}
+	return a.print(result, transactionsTable(result))
+}
+
+func runTransactionsExport(a *app, args []string) error {
+	fs := a.flags("transactions export")
+	params := &intasend.ListTransactionsParams{}
+	fs.StringVar(&params.DateFrom, "from", "", "start date, YYYY-MM-DD")
+	fs.StringVar(&params.DateTo, "to", "", "end date, YYYY-MM-DD")
+	fs.StringVar(&params.TransType, "type", "", "filter by type (DEPOSIT, WITHDRAWAL, TRANSFER, CHARGE, REFUND)")
+	fs.StringVar(&params.Status, "status", "", "filter by status")
+	fs.StringVar(&params.WalletID, "wallet", "", "filter by wallet ID")
+	fs.StringVar(&params.Currency, "currency", "", "filter by currency")
+	opts := &intasend.TransactionExportOptions{}
+	format := fs.String("format", "", "output format: csv, ndjson or parquet")
+	fs.StringVar(&opts.Dir, "dir", ".", "output directory")
+	fs.StringVar(&opts.Prefix, "prefix", "transactions", "file name prefix")
+	fs.IntVar(&opts.FileRows, "file-rows", intasend.DefaultExportFileRows, "maximum rows per file")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	switch opts.Format = intasend.ExportFormat(strings.ToLower(*format)); opts.Format {
+	case intasend.ExportCSV, intasend.ExportNDJSON, intasend.ExportParquet:
+	default:
+		return fmt.Errorf("%w: -format must be csv, ndjson or parquet", errUsage)
+	}
+	if err := checkDateRange(params.DateFrom, params.DateTo); err != nil {
+		return err
+	}
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	result, err := intasend.ExportTransactions(context.Background(), client, params, opts)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"FILE"}}
+	for _, f := range result.Files {
+		t.add(f)
+	}
+	t.footer = fmt.Sprintf("%d transactions in %d file(s)", result.Rows, len(result.Files))
+	return a.print(result, t)
+}
+
+// checkDateRange validates the -from and -to flags
+func checkDateRange(from, to string) error {
+	for name, v := range map[string]string{"-from": from, "-to": to} {
+		if _, err := time.Parse(time.DateOnly, v); v != "" && err != nil {
+			return fmt.Errorf("%w: %s must be a date like 2006-01-02, got %q", errUsage, name, v)
+		}
+	}
+	return nil
+}
+
+func runWalletsList(a *app, args []string) error {
+	fs := a.flags("wallets list")
+	params := &intasend.ListWalletsParams{}
+	fs.StringVar(&params.Currency, "currency", "", "filter by currency")
+	walletType := fs.String("type", "", "filter by wallet type (SETTLEMENT, WORKING)")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	params.WalletType = intasend.WalletTypeFilter(*walletType)
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.ListWallets(params)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"WALLET", "LABEL", "TYPE", "CURRENCY", "CURRENT", "AVAILABLE", "DISBURSE", "UPDATED"}}
+	for _, w := range result.Results {
+		t.add(w.WalletID, orDash(w.Label), w.WalletType, w.Currency, money(w.CurrentBalance), money(w.AvailableBalance), strconv.FormatBool(w.CanDisburse), timestamp(w.UpdatedAt))
+	}
+	t.footer = pageFooter(len(result.Results), result.Count, result.Next)
+	return a.print(result, t)
+}
+
+func runWalletTx(a *app, args []string) error {
+	fs := a.flags("wallet tx")
+	page := fs.Int("page", 0, "page number")
+	args, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.ListWalletTransactions(args[0], &intasend.WalletTransactionsParams{Page: optional(*page)})
+	if err != nil {
+		return err
+	}
+	return a.print(result, transactionsTable(result))
+}
+
+func runWalletVerify(a *app, args []string) error {
+	fs := a.flags("wallet verify")
+	tolerance := fs.Float64("tolerance", intasendrecon.DefaultAmountTolerance, "largest difference ignored")
+	args, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	report, err := intasendrecon.VerifyWallet(context.Background(), client, args[0], *tolerance)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"KIND", "TRANSACTION", "TIME", "EXPECTED", "ACTUAL", "DETAIL"}}
+	for _, an := range report.Anomalies {
+		t.add(string(an.Kind), orDash(an.TransactionID), timestamp(an.Time), money(an.Expected), money(an.Actual), an.Detail)
+	}
+	t.footer = fmt.Sprintf("%d transactions checked (%d pending or failed skipped), opening %s, current %s %s",
+		len(report.History), report.Skipped, money(report.OpeningBalance), money(report.Wallet.CurrentBalance), report.Wallet.Currency)
+	if err := a.print(report, t); err != nil {
+		return err
+	}
+	if !report.OK() {
+		return fmt.Errorf("wallet %s: %d ledger anomalies", args[0], len(report.Anomalies))
+	}
+	return nil
+}
+
+func transactionsTable(result *intasend.TransactionResp) *table {
+	t := &table{header: []string{"TRANSACTION", "TYPE", "STATUS", "VALUE", "CURRENCY", "BALANCE", "INVOICE", "NARRATIVE", "CREATED"}}
+	for _, tx := range result.Results {
+		t.add(tx.TransactionID, tx.TransType, tx.Status, money(tx.Value), tx.Currency, money(tx.RunningBalance), orDash(tx.GetInvoiceID()), orDash(tx.Narrative), timestamp(tx.CreatedAt))
+	}
+	t.footer = pageFooter(len(result.Results), int(result.Count), result.Next)
+	return t
+}
+
+func runCheckoutCreate(a *app, args []string) error {
+	fs := a.flags("checkout create")
+	req := &intasend.PaymentRequest{}
+	fs.Float64Var(&req.Amount, "amount", 0, "amount to collect")
+	currency := fs.String("currency", string(intasend.CurrencyKES), "currency")
+	fs.StringVar(&req.Email, "email", "", "customer email")
+	fs.StringVar(&req.PhoneNumber, "phone", "", "customer phone number")
+	fs.StringVar(&req.CustomerID, "customer", "", "existing customer ID")
+	fs.StringVar(&req.APIRef, "api-ref", "", "your reference for the payment")
+	fs.StringVar(&req.RedirectURL, "redirect-url", "", "URL to return the customer to after paying")
+	fs.StringVar(&req.Comment, "comment", "", "comment shown to the customer")
+	method := fs.String("method", "", "restrict to a payment method (M-PESA, CARD-PAYMENT)")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("%w: -amount is required", errUsage)
+	}
+	req.Currency = intasend.CurrencyType(strings.ToUpper(*currency))
+	req.Method = intasend.PaymentMethodType(*method)
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.CreateCheckoutLink(req)
+	if err != nil {
+		return err
+	}
+	return a.print(resp, fields(
+		"Invoice", resp.ID,
+		"URL", resp.URL,
+		"Amount", money(req.Amount)+" "+string(req.Currency),
+	))
+}
+
+func runPushXB(a *app, args []string) error {
+	fs := a.flags("push xb")
+	req := &intasend.IntaSendXBPushRequest{}
+	fs.StringVar(&req.Amount, "amount", "", "amount to collect")
+	currency := fs.String("currency", "", "currency, UGX or TZS")
+	fs.StringVar(&req.PhoneNumber, "phone", "", "phone number to push to")
+	fs.StringVar(&req.CustomerID, "customer", "", "existing customer ID instead of -phone")
+	fs.StringVar(&req.APIRef, "api-ref", "", "your reference for the payment")
+	fs.StringVar(&req.WalletID, "wallet", "", "wallet to credit")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if req.Amount == "" || *currency == "" {
+		return fmt.Errorf("%w: -amount and -currency are required", errUsage)
+	}
+	req.Currency = intasend.CurrencyType(strings.ToUpper(*currency))
+	client, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.SendIntaSendXBPush(req)
+	if err != nil {
+		return err
+	}
+	return a.print(resp, fields(
+		"Invoice", resp.Invoice.InvoiceID,
+		"State", resp.Invoice.State,
+		"Amount", money(resp.Invoice.Value)+" "+resp.Invoice.Currency,
+		"Phone", resp.Customer.PhoneNumber,
+		"Customer", resp.Customer.CustomerID,
+	))
+}
+
+// optional converts a flag left at 0 into an unset parameter
+func optional(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
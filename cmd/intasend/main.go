@@ -0,0 +1,198 @@
+// Command intasend is an operations tool for the IntaSend API built on the SDK.
+//
+// Usage:
+//
+//	intasend [-profile name] [-config file] [-o table|json] <command> [flags] [args]
+//
+// Commands:
+//
+//	status <invoice>               show the payment status of an invoice
+//	invoices list                  list invoices
+//	transactions list              list transactions, optionally -from/-to YYYY-MM-DD
+//	transactions export            write transactions to CSV, NDJSON or Parquet files
+//	wallets list                   list wallets and their balances
+//	wallet tx <wallet>             list a wallet's transactions
+//	wallet verify <wallet>         check a wallet's running balances
+//	checkout create                create a checkout link
+//	push xb                        send an IntaSend-XB push to a phone
+//	payout send -file batch.csv    send payouts from a CSV or XLSX file
+//
+// Credentials come from the environment or a config file. Without -profile,
+// INTASEND_PUBLISHABLE_KEY, INTASEND_TOKEN and INTASEND_TEST are used when
+// set; otherwise the default profile of the config file is. With -profile
+// NAME, INTASEND_<NAME>_* variables take precedence over the file's profile.
+// The config file is -config, $INTASEND_CONFIG or intasend/config.yaml under
+// the user config directory:
+//
+//	default: sandbox
+//	profiles:
+//	  sandbox:
+//	    publishable_key: ISPubKey_test_...
+//	    token: ISSecretKey_test_...
+//	    test: true
+//	  live:
+//	    publishable_key: ISPubKey_live_...
+//	    token: ISSecretKey_live_...
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// app carries the global options shared by every command
+type app struct {
+	profile    string
+	configPath string
+	output     string
+	stdout     io.Writer
+	stderr     io.Writer
+
+	client *intasend.Client // created on first use
+}
+
+// command is a leaf of the command tree
+type command struct {
+	path  string // e.g. "invoices list"
+	usage string
+	run   func(a *app, args []string) error
+}
+
+var commands = []command{
+	{"status", "status <invoice>", runStatus},
+	{"invoices list", "invoices list [-state S] [-currency C] [-api-ref R] [-page N] [-page-size N]", runInvoicesList},
+	{"transactions list", "transactions list [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-type T] [-status S] [-wallet ID] [-currency C] [-page N] [-page-size N]", runTransactionsList},
+	{"transactions export", "transactions export -format csv|ndjson|parquet [-dir D] [-prefix P] [-file-rows N] [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-type T] [-status S] [-wallet ID] [-currency C]", runTransactionsExport},
+	{"wallets list", "wallets list [-currency C] [-type T]", runWalletsList},
+	{"wallet tx", "wallet tx <wallet> [-page N]", runWalletTx},
+	{"wallet verify", "wallet verify <wallet> [-tolerance N]", runWalletVerify},
+	{"checkout create", "checkout create -amount N [-currency C] [-email E] [-phone P] [-customer ID] [-api-ref R] [-method M] [-redirect-url U]", runCheckoutCreate},
+	{"push xb", "push xb -amount N -currency UGX|TZS (-phone P | -customer ID) [-api-ref R] [-wallet ID]", runPushXB},
+	{"payout send", "payout send -file batch.csv|xlsx [-columns field=header,...] [-sheet S] [-currency C] [-provider P] [-reference R] [-batch-size N] [-wallet W] [-tariffs tariffs.yaml] [-approval] [-run-id ID] [-checkpoints DIR] [-poll D] [-dry-run] [-yes]", runPayoutSend},
+}
+
+// errUsage reports a command line that could not be understood
+var errUsage = errors.New("usage error")
+
+func main() {
+	a := &app{stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(a.main(os.Args[1:]))
+}
+
+// main runs the command line and returns the process exit code
+func (a *app) main(args []string) int {
+	err := a.run(args)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(a.stderr, err)
+		a.usage()
+		return 2
+	default:
+		fmt.Fprintln(a.stderr, "intasend:", err)
+		return 1
+	}
+}
+
+func (a *app) run(args []string) error {
+	fs := flag.NewFlagSet("intasend", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = a.usage
+	fs.StringVar(&a.profile, "profile", "", "credentials profile (default $INTASEND_PROFILE or the config file default)")
+	fs.StringVar(&a.configPath, "config", "", "config file (default $INTASEND_CONFIG or intasend/config.yaml in the user config directory)")
+	a.outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cmd, rest := findCommand(fs.Args())
+	if cmd == nil {
+		if fs.NArg() == 0 {
+			return fmt.Errorf("%w: no command given", errUsage)
+		}
+		return fmt.Errorf("%w: unknown command %q", errUsage, strings.Join(fs.Args(), " "))
+	}
+	return cmd.run(a, rest)
+}
+
+// findCommand matches the longest command path at the start of args
+func findCommand(args []string) (*command, []string) {
+	var best *command
+	bestLen := 0
+	for i := range commands {
+		words := strings.Fields(commands[i].path)
+		if len(words) <= len(args) && len(words) > bestLen && strings.Join(args[:len(words)], " ") == commands[i].path {
+			best, bestLen = &commands[i], len(words)
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return best, args[bestLen:]
+}
+
+func (a *app) usage() {
+	fmt.Fprintln(a.stderr, "usage: intasend [-profile name] [-config file] [-o table|json] <command> [flags] [args]")
+	fmt.Fprintln(a.stderr, "\ncommands:")
+	usages := make([]string, len(commands))
+	for i, c := range commands {
+		usages[i] = c.usage
+	}
+	sort.Strings(usages)
+	for _, u := range usages {
+		fmt.Fprintln(a.stderr, "  "+u)
+	}
+}
+
+// outputFlag registers -o on fs so it is accepted before or after the command
+func (a *app) outputFlag(fs *flag.FlagSet) {
+	if a.output == "" {
+		a.output = "table"
+	}
+	fs.StringVar(&a.output, "o", a.output, "output format: table or json")
+}
+
+// flags returns a flag set for a command that also accepts -o
+func (a *app) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	a.outputFlag(fs)
+	return fs
+}
+
+// parse parses a command's flags, allowing them before and after positional
+// arguments, and checks the number of positional arguments
+func (a *app) parse(fs *flag.FlagSet, args []string, nargs int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != nargs {
+		return nil, fmt.Errorf("%w: %s expects %d argument(s), got %d", errUsage, fs.Name(), nargs, len(positional))
+	}
+	if a.output != "table" && a.output != "json" {
+		return nil, fmt.Errorf("%w: -o must be table or json, got %q", errUsage, a.output)
+	}
+	return positional, nil
+}
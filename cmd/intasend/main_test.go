@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/techliana/intasend-sdk-golang"
+	"github.com/techliana/intasend-sdk-golang/intasendtest"
+)
+
+// runCLI runs the CLI against srv using environment credentials
+func runCLI(t *testing.T, srv *intasendtest.Server, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	t.Setenv("INTASEND_PUBLISHABLE_KEY", intasendtest.DefaultPublishableKey)
+	t.Setenv("INTASEND_TOKEN", intasendtest.DefaultToken)
+	t.Setenv("INTASEND_TEST", "true")
+	t.Setenv("INTASEND_BASE_URL", srv.URL)
+	t.Setenv("INTASEND_API_BASE_URL", srv.URL)
+
+	var out, errOut bytes.Buffer
+	a := &app{stdout: &out, stderr: &errOut}
+	code = a.main(args)
+	return out.String(), errOut.String(), code
+}
+
+func TestCheckoutAndStatus(t *testing.T) {
+	srv := intasendtest.NewServer()
+	defer srv.Close()
+
+	out, stderr, code := runCLI(t, srv, "-o", "json", "checkout", "create", "-amount", "250", "-email", "ops@example.com", "-api-ref", "order-9")
+	if code != 0 {
+		t.Fatalf("checkout create exited %d: %s", code, stderr)
+	}
+	var checkout intasend.PaymentResponse
+	if err := json.Unmarshal([]byte(out), &checkout); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if err := srv.Complete(checkout.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// -o is also accepted after the command and its argument
+	out, stderr, code = runCLI(t, srv, "status", checkout.ID, "-o", "table")
+	if code != 0 {
+		t.Fatalf("status exited %d: %s", code, stderr)
+	}
+	for _, want := range []string{checkout.ID, "COMPLETE", "250.00 KES", "order-9"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected status table to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out, _, code = runCLI(t, srv, "invoices", "list", "-state", intasend.StatusComplete)
+	if code != 0 || !strings.Contains(out, checkout.ID) || !strings.Contains(out, "1 of 1") {
+		t.Errorf("Unexpected invoices list (exit %d):\n%s", code, out)
+	}
+}
+
+func TestWalletsAndTransactions(t *testing.T) {
+	srv := intasendtest.NewServer()
+	defer srv.Close()
+
+	out, stderr, code := runCLI(t, srv, "wallets", "list", "-currency", "UGX")
+	if code != 0 {
+		t.Fatalf("wallets list exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(out, "WUGX001") || strings.Contains(out, "WKES001") {
+		t.Errorf("Expected only the UGX wallet, got:\n%s", out)
+	}
+
+	out, stderr, code = runCLI(t, srv, "wallet", "tx", "WKES001")
+	if code != 0 || !strings.Contains(out, "TRANSACTION") {
+		t.Errorf("wallet tx exited %d: %s\n%s", code, stderr, out)
+	}
+
+	out, stderr, code = runCLI(t, srv, "wallet", "verify", "WKES001")
+	if code != 0 || !strings.Contains(out, "0 transactions checked") {
+		t.Errorf("wallet verify exited %d: %s\n%s", code, stderr, out)
+	}
+
+	dir := t.TempDir()
+	out, stderr, code = runCLI(t, srv, "transactions", "export", "-format", "csv", "-dir", dir, "-wallet", "WKES001")
+	if code != 0 || !strings.Contains(out, "transactions-0001.csv") || !strings.Contains(out, "0 transactions in 1 file(s)") {
+		t.Errorf("transactions export exited %d: %s\n%s", code, stderr, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "transactions-0001.csv")); err != nil {
+		t.Errorf("Expected the export file to exist: %v", err)
+	}
+	if _, stderr, code := runCLI(t, srv, "transactions", "export", "-format", "xml"); code != 2 || !strings.Contains(stderr, "-format must be") {
+		t.Errorf("Expected a usage error for an unknown format, got exit %d: %s", code, stderr)
+	}
+
+	_, stderr, code = runCLI(t, srv, "transactions", "list", "-from", "01/02/2024")
+	if code != 2 || !strings.Contains(stderr, "-from must be a date") {
+		t.Errorf("Expected a usage error for a bad date, got exit %d: %s", code, stderr)
+	}
+}
+
+func TestPayoutSend(t *testing.T) {
+	srv := intasendtest.NewServer(intasendtest.WithAutoAdvance())
+	defer srv.Close()
+
+	dir := t.TempDir()
+	batch := filepath.Join(dir, "batch.csv")
+	csv := "Name,Phone,Amount,Narrative\nJane,254712345678,100,Salary\nJohn,254798765432,250.50,Salary\nAmos,254700000001,50,Salary\n"
+	if err := os.WriteFile(batch, []byte(csv), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mapping := "account=Phone,name=Name"
+	out, stderr, code := runCLI(t, srv, "payout", "send", "-file", batch, "-columns", mapping, "-reference", "payroll-1", "-batch-size", "2", "-dry-run")
+	if code != 0 || !strings.Contains(out, "3 transactions in 2 batch(es), 400.50 KES") || !strings.Contains(out, "payroll-1-2") {
+		t.Fatalf("Unexpected dry run (exit %d): %s\n%s", code, stderr, out)
+	}
+
+	send := []string{"-o", "json", "payout", "send", "-file", batch, "-columns", mapping, "-reference", "payroll-1", "-batch-size", "2", "-checkpoints", dir, "-poll", "10ms"}
+	out, stderr, code = runCLI(t, srv, send...)
+	if code != 0 {
+		t.Fatalf("payout send exited %d: %s", code, stderr)
+	}
+	var report intasend.PayoutReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Chunks) != 2 || report.Chunks[0].TrackingID == "" || len(report.Chunks[0].Transactions) != 2 || len(report.Chunks[1].Transactions) != 1 {
+		t.Errorf("Unexpected payout chunks: %+v", report.Chunks)
+	}
+	if !report.Done() || len(report.Succeeded) != 3 || report.SucceededAmount != 400.50 {
+		t.Errorf("Expected every recipient to be paid, got %+v", report)
+	}
+
+	// Sending the same file again resumes the finished run instead of paying twice
+	out, stderr, code = runCLI(t, srv, send...)
+	if code != 0 {
+		t.Fatalf("payout send exited %d on the second run: %s", code, stderr)
+	}
+	var again intasend.PayoutReport
+	if err := json.Unmarshal([]byte(out), &again); err != nil {
+		t.Fatal(err)
+	}
+	if again.RunID != report.RunID || again.Chunks[0].TrackingID != report.Chunks[0].TrackingID || again.Chunks[1].TrackingID != report.Chunks[1].TrackingID {
+		t.Errorf("Expected the second run to resume %s, got %+v", report.RunID, again)
+	}
+
+	tariffs := filepath.Join(dir, "tariffs.yaml")
+	if err := os.WriteFile(tariffs, []byte("- provider: MPESA-B2C\n  currency: KES\n  bands:\n    - {min: 1, fixed: 10}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A fresh KES wallet has nothing paid in yet, so the preflight reports the shortfall
+	empty := intasendtest.NewServer()
+	defer empty.Close()
+	out, stderr, code = runCLI(t, empty, "payout", "send", "-file", batch, "-columns", mapping, "-tariffs", tariffs, "-dry-run")
+	if code != 1 || !strings.Contains(out, "+ 30.00 charges = 430.50 from WKES001 (available 0.00)") || !strings.Contains(stderr, "short by 430.50") {
+		t.Errorf("Expected a dry run with a shortfall, got exit %d: %s\n%s", code, stderr, out)
+	}
+	if _, stderr, code := runCLI(t, empty, "payout", "send", "-file", batch, "-columns", mapping, "-tariffs", tariffs, "-checkpoints", dir); code != 1 || !strings.Contains(stderr, "insufficient funds in wallet WKES001") {
+		t.Errorf("Expected the payout to be refused, got exit %d: %s", code, stderr)
+	}
+
+	bad := filepath.Join(dir, "bad.csv")
+	if err := os.WriteFile(bad, []byte("account,amount\n254712345678,-5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, code := runCLI(t, srv, "payout", "send", "-file", bad); code != 1 || !strings.Contains(stderr, "row 2: amount") {
+		t.Errorf("Expected the bad row to be reported, got exit %d: %s", code, stderr)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	srv := intasendtest.NewServer()
+	defer srv.Close()
+	for _, name := range []string{"INTASEND_PUBLISHABLE_KEY", "INTASEND_TOKEN", "INTASEND_PROFILE", "INTASEND_CONFIG"} {
+		t.Setenv(name, "")
+	}
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	hosts := "    base_url: " + srv.URL + "\n    api_base_url: " + srv.URL + "\n"
+	body := "default: sandbox\nprofiles:\n" +
+		"  sandbox:\n    token: " + intasendtest.DefaultToken + "\n    test: true\n" + hosts +
+		"  live:\n    token: ISSecretKey_live_example\n" + hosts
+	if err := os.WriteFile(config, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, int) {
+		var out, errOut bytes.Buffer
+		a := &app{stdout: &out, stderr: &errOut}
+		code := a.main(append([]string{"-config", config}, args...))
+		return out.String() + errOut.String(), code
+	}
+
+	if out, code := run("wallets", "list"); code != 0 || !strings.Contains(out, "WKES001") {
+		t.Errorf("Expected the default profile to work, got exit %d:\n%s", code, out)
+	}
+	// The fake rejects the live token
+	if out, code := run("-profile", "live", "wallets", "list"); code != 1 || !strings.Contains(out, "401") {
+		t.Errorf("Expected the live profile to be used and rejected, got exit %d:\n%s", code, out)
+	}
+	if out, code := run("-profile", "staging", "wallets", "list"); code != 1 || !strings.Contains(out, `profile "staging" not found`) {
+		t.Errorf("Expected an unknown profile error, got exit %d:\n%s", code, out)
+	}
+
+	// Profile-specific environment variables take precedence over the file
+	t.Setenv("INTASEND_LIVE_TOKEN", intasendtest.DefaultToken)
+	t.Setenv("INTASEND_LIVE_TEST", "true")
+	t.Setenv("INTASEND_LIVE_BASE_URL", srv.URL)
+	if out, code := run("-profile", "live", "wallets", "list"); code != 0 {
+		t.Errorf("Expected INTASEND_LIVE_* to override the file, got exit %d:\n%s", code, out)
+	}
+}
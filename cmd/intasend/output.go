@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// table is the tabular rendering of a command's result
+type table struct {
+	header []string
+	rows   [][]string
+	footer string // optional line printed below the rows, e.g. a page summary
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// print writes v as indented JSON or t as an aligned table, depending on -o
+func (a *app) print(v any, t *table) error {
+	if a.output == "json" {
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	if len(t.header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	}
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if t.footer != "" {
+		fmt.Fprintln(a.stdout, t.footer)
+	}
+	return nil
+}
+
+// fields renders label/value pairs as a two-column table
+func fields(pairs ...string) *table {
+	t := &table{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		t.add(pairs[i]+":", pairs[i+1])
+	}
+	return t
+}
+
+// pageFooter summarises a page of a paginated list. next is the response's
+// Next link, a *string or, for transactions, an untyped value.
+func pageFooter(shown, count int, next interface{}) string {
+	footer := fmt.Sprintf("%d of %d", shown, count)
+	if intasend.HasNextPage(next) {
+		footer += " (more pages, use -page)"
+	}
+	return footer
+}
+
+func money(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// deref returns the value of an optional string, or ""
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
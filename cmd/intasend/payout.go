@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+func runPayoutSend(a *app, args []string) error {
+	fs := a.flags("payout send")
+	file := fs.String("file", "", "CSV or XLSX file with a header row: account, amount and optionally name, narrative, id_number, bank_code, account_type, account_reference, category_name")
+	columns := fs.String("columns", "", "read only these columns, as field=header pairs, e.g. account=Phone,amount=Amount KES,name=Name")
+	sheet := fs.String("sheet", "", "XLSX sheet to read (default the first)")
+	currency := fs.String("currency", string(intasend.CurrencyKES), "currency")
+	provider := fs.String("provider", string(intasend.ProviderMPESAB2C), "provider (MPESA-B2C, MPESA-B2B, BANK, INTASEND-XB)")
+	reference := fs.String("reference", "", "batch reference, suffixed -1, -2, ... when the file is split")
+	batchSize := fs.Int("batch-size", intasend.DefaultPayoutBatchSize, "maximum transactions per batch")
+	wallet := fs.String("wallet", "", "wallet ID to debit (default the currency's settlement wallet)")
+	tariffs := fs.String("tariffs", "", "YAML or JSON tariff table; when set, charges and the wallet balance are checked before sending")
+	approval := fs.Bool("approval", false, "hold the batches for approval in the dashboard")
+	runID := fs.String("run-id", "", "name of the run; running again with the same name resumes it instead of paying twice (default derived from the file)")
+	checkpoints := fs.String("checkpoints", "", "directory for run checkpoints (default intasend/payouts in the user cache directory)")
+	poll := fs.Duration("poll", intasend.DefaultPayoutPollInterval, "time between status checks while waiting for the batches")
+	dryRun := fs.Bool("dry-run", false, "validate and print the batches without sending them")
+	yes := fs.Bool("yes", false, "confirm sending batches with live credentials")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("%w: -file is required", errUsage)
+	}
+
+	opts := &intasend.PayoutImportOptions{
+		Provider: intasend.SendMoneyProvider(strings.ToUpper(*provider)),
+		Sheet:    *sheet,
+	}
+	if *columns != "" {
+		cols, err := parseColumns(*columns)
+		if err != nil {
+			return fmt.Errorf("%w: -columns: %v", errUsage, err)
+		}
+		opts.Columns = cols
+	}
+	imp, err := intasend.LoadPayoutFile(*file, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *file, err)
+	}
+
+	base := &intasend.SendMoneyRequest{
+		Currency:         intasend.CurrencyType(strings.ToUpper(*currency)),
+		Provider:         opts.Provider,
+		BatchReference:   *reference,
+		WalletID:         *wallet,
+		RequiresApproval: intasend.ApprovalNo,
+	}
+	if *approval {
+		base.RequiresApproval = intasend.ApprovalYes
+	}
+	batches, err := imp.Batches(base, *batchSize)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	var client *intasend.Client
+	if !*dryRun || *tariffs != "" {
+		if client, err = a.connect(); err != nil {
+			return err
+		}
+	}
+
+	// The preflight covers the whole file, so a shortfall stops every batch
+	var preflight *intasend.PayoutPreflight
+	if *tariffs != "" {
+		rates, err := intasend.LoadPayoutTariffs(*tariffs)
+		if err != nil {
+			return err
+		}
+		preflight = &intasend.PayoutPreflight{Wallets: client, Tariffs: rates}
+	}
+
+	if *dryRun {
+		var est *intasend.PayoutEstimate
+		var preflightErr error
+		if preflight != nil {
+			all := *base
+			all.Transactions = imp.Transactions
+			est, preflightErr = preflight.Check(context.Background(), &all)
+			if preflightErr != nil && !errors.Is(preflightErr, intasend.ErrInsufficientFunds) {
+				return preflightErr
+			}
+		}
+		t := &table{header: []string{"ROW", "BATCH", "NAME", "ACCOUNT", "AMOUNT", "NARRATIVE"}}
+		if est != nil {
+			t.header = append(t.header, "CHARGE")
+		}
+		row := 0
+		for _, batch := range batches {
+			for _, tx := range batch.Transactions {
+				cells := []string{fmt.Sprint(imp.Rows[row]), orDash(batch.BatchReference), orDash(tx.Name), tx.Account, tx.Amount, orDash(tx.Narrative)}
+				if est != nil {
+					cells = append(cells, money(est.Lines[row].Charge))
+				}
+				t.add(cells...)
+				row++
+			}
+		}
+		t.footer = fmt.Sprintf("%d transactions in %d batch(es), %s %s", len(imp.Transactions), len(batches), money(imp.Total), base.Currency)
+		if est != nil {
+			t.footer += fmt.Sprintf(" + %s charges = %s from %s (available %s)", money(est.Charges), money(est.Total), est.WalletID, money(est.AvailableBalance))
+		}
+		t.footer += " (dry run, nothing sent)"
+		if err := a.print(batches, t); err != nil {
+			return err
+		}
+		return preflightErr
+	}
+
+	if client.Environment() == intasend.EnvironmentLive && !*yes {
+		return fmt.Errorf("refusing to send a live payout without -yes")
+	}
+
+	// Each batch carries an idempotency key derived from the run ID and the
+	// checkpoint records what was accepted, so running the same file again
+	// resumes the run instead of paying anyone twice
+	if *runID == "" {
+		if *runID, err = payoutRunID(base, imp.Transactions); err != nil {
+			return err
+		}
+	}
+	store := &intasend.FileCheckpointStore{Dir: *checkpoints}
+	if store.Dir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return fmt.Errorf("%w: -checkpoints is required: %v", errUsage, err)
+		}
+		store.Dir = filepath.Join(dir, "intasend", "payouts")
+	}
+	orch, err := intasend.NewPayoutOrchestrator(intasend.PayoutOrchestratorConfig{
+		Client:       client,
+		ChunkSize:    *batchSize,
+		PollInterval: *poll,
+		Checkpoints:  store,
+		Preflight:    preflight,
+		OnChunk: func(c intasend.PayoutChunk) {
+			if c.TrackingID != "" {
+				fmt.Fprintf(a.stderr, "intasend: batch %s %s %s\n", orDash(c.Request.BatchReference), c.TrackingID, c.Status)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	report, runErr := orch.Run(ctx, *runID, base, imp.Transactions)
+	if report == nil {
+		return runErr
+	}
+
+	t := &table{header: []string{"BATCH", "TRACKING ID", "STATUS", "TRANSACTIONS", "ERROR"}}
+	for _, c := range report.Chunks {
+		status := c.Status
+		if c.Rejected {
+			status = "REJECTED"
+		}
+		t.add(orDash(c.Request.BatchReference), orDash(c.TrackingID), orDash(status), fmt.Sprint(len(c.Request.Transactions)), orDash(c.Error))
+	}
+	t.footer = fmt.Sprintf("run %s: %d succeeded (%s %s), %d failed (%s %s), %d pending",
+		report.RunID, len(report.Succeeded), money(report.SucceededAmount), base.Currency,
+		len(report.Failed), money(report.FailedAmount), base.Currency, len(report.Pending))
+	if err := a.print(report, t); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return fmt.Errorf("%w (run again with -run-id %s to resume)", runErr, report.RunID)
+	}
+	return nil
+}
+
+// payoutRunID names a run after its input, so sending the same file with the
+// same options again resumes the earlier run
+func payoutRunID(base *intasend.SendMoneyRequest, txs []intasend.SendMoneyTransaction) (string, error) {
+	req := *base
+	req.Transactions = txs
+	data, err := json.Marshal(&req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "payout-" + hex.EncodeToString(sum[:8]), nil
+}
+
+// parseColumns parses field=header pairs into a column mapping. Account and
+// amount keep their default headers unless mapped; other fields not mentioned
+// are not read.
+func parseColumns(s string) (*intasend.PayoutColumns, error) {
+	cols := intasend.PayoutColumns{
+		Account: intasend.DefaultPayoutColumns.Account,
+		Amount:  intasend.DefaultPayoutColumns.Amount,
+	}
+	fields := map[string]*string{
+		"name":              &cols.Name,
+		"account":           &cols.Account,
+		"amount":            &cols.Amount,
+		"narrative":         &cols.Narrative,
+		"id_number":         &cols.IDNumber,
+		"bank_code":         &cols.BankCode,
+		"account_type":      &cols.AccountType,
+		"account_reference": &cols.AccountReference,
+		"category_name":     &cols.CategoryName,
+	}
+	for _, pair := range strings.Split(s, ",") {
+		field, header, ok := strings.Cut(pair, "=")
+		dst, known := fields[strings.TrimSpace(field)]
+		if !ok || !known {
+			return nil, fmt.Errorf("expected field=header with a known field, got %q", pair)
+		}
+		*dst = strings.TrimSpace(header)
+	}
+	return &cols, nil
+}
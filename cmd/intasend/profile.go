@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/techliana/intasend-sdk-golang"
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is the layout of the CLI config file
+type configFile struct {
+	Default  string                     `json:"default" yaml:"default"`
+	Profiles map[string]intasend.Config `json:"profiles" yaml:"profiles"`
+}
+
+// connect returns the client for the selected profile, creating it on first use
+func (a *app) connect() (*intasend.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range cfg.Warnings() {
+		fmt.Fprintln(a.stderr, "intasend: warning:", w)
+	}
+	a.client = cfg.NewClient()
+	return a.client, nil
+}
+
+// loadConfig resolves credentials: the profile's environment variables,
+// then the config file
+func (a *app) loadConfig() (*intasend.Config, error) {
+	profile := a.profile
+	if profile == "" {
+		profile = os.Getenv("INTASEND_PROFILE")
+	}
+
+	prefix := intasend.DefaultEnvPrefix
+	if profile != "" {
+		prefix += strings.ToUpper(strings.ReplaceAll(profile, "-", "_")) + "_"
+	}
+	if os.Getenv(prefix+"PUBLISHABLE_KEY") != "" || os.Getenv(prefix+"TOKEN") != "" {
+		return intasend.LoadConfigFromEnv(prefix)
+	}
+
+	path, explicit := a.configPath, a.configPath != ""
+	if path == "" {
+		path, explicit = os.Getenv("INTASEND_CONFIG"), os.Getenv("INTASEND_CONFIG") != ""
+	}
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("no credentials: set %sTOKEN or use -config", prefix)
+		}
+		path = filepath.Join(dir, "intasend", "config.yaml")
+	}
+
+	file, err := readConfigFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil, fmt.Errorf("no credentials: set %sPUBLISHABLE_KEY and/or %sTOKEN, or create %s", prefix, prefix, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == "" {
+		profile = file.Default
+	}
+	if profile == "" && len(file.Profiles) == 1 {
+		for name := range file.Profiles {
+			profile = name
+		}
+	}
+	cfg, ok := file.Profiles[profile]
+	if !ok {
+		names := make([]string, 0, len(file.Profiles))
+		for name := range file.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if profile == "" {
+			return nil, fmt.Errorf("%s has no default profile; use -profile with one of: %s", path, strings.Join(names, ", "))
+		}
+		return nil, fmt.Errorf("profile %q not found in %s (have: %s)", profile, path, strings.Join(names, ", "))
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("profile %q: %w", profile, err)
+	}
+	return &cfg, nil
+}
+
+func readConfigFile(path string) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	file := &configFile{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, file)
+	case ".json":
+		err = json.Unmarshal(data, file)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q (use .yaml, .yml or .json)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return file, nil
+}
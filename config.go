@@ -0,0 +1,123 @@
+package intasend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvPrefix is the conventional prefix for IntaSend environment
+// variables, e.g. INTASEND_PUBLISHABLE_KEY and INTASEND_TOKEN
+const DefaultEnvPrefix = "INTASEND_"
+
+// Config holds client settings loaded from the environment or a config file
+type Config struct {
+	PublishableKey string `json:"publishable_key" yaml:"publishable_key"`
+	Token          Secret `json:"token" yaml:"token"`
+	Test           bool   `json:"test" yaml:"test"`
+	ShowLogs       bool   `json:"show_logs" yaml:"show_logs"`
+	BaseURL        string `json:"base_url,omitempty" yaml:"base_url,omitempty"`         // optional, overrides the payment host
+	APIBaseURL     string `json:"api_base_url,omitempty" yaml:"api_base_url,omitempty"` // optional, overrides the API host
+}
+
+// LoadConfigFromEnv reads the configuration from environment variables named
+// <prefix>PUBLISHABLE_KEY, <prefix>TOKEN, <prefix>TEST, <prefix>SHOW_LOGS,
+// <prefix>BASE_URL and <prefix>API_BASE_URL. Pass DefaultEnvPrefix for the
+// conventional INTASEND_ names.
+func LoadConfigFromEnv(prefix string) (*Config, error) {
+	cfg := &Config{
+		PublishableKey: os.Getenv(prefix + "PUBLISHABLE_KEY"),
+		Token:          Secret(os.Getenv(prefix + "TOKEN")),
+		BaseURL:        os.Getenv(prefix + "BASE_URL"),
+		APIBaseURL:     os.Getenv(prefix + "API_BASE_URL"),
+	}
+	for name, dst := range map[string]*bool{"TEST": &cfg.Test, "SHOW_LOGS": &cfg.ShowLogs} {
+		v := os.Getenv(prefix + name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s%s: %w", prefix, name, err)
+		}
+		*dst = b
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%w (set %sPUBLISHABLE_KEY and/or %sTOKEN)", err, prefix, prefix)
+	}
+	return cfg, nil
+}
+
+// LoadConfigFromFile reads the configuration from a YAML (.yaml, .yml) or
+// JSON (.json) file. Keep such files out of version control.
+func LoadConfigFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg := &Config{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".json":
+		err = json.Unmarshal(data, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q (use .yaml, .yml or .json)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that the configuration carries at least one API key
+func (c *Config) Validate() error {
+	if c.PublishableKey == "" && c.Token == "" {
+		return fmt.Errorf("publishable key or token is required")
+	}
+	return nil
+}
+
+// Warnings reports likely misconfigurations, such as live keys used while
+// Test is true (requests would go to the sandbox and fail) or test keys used
+// while Test is false
+func (c *Config) Warnings() []string {
+	env := EnvironmentLive
+	if c.Test {
+		env = EnvironmentSandbox
+	}
+
+	var warnings []string
+	check := func(name, key string) {
+		if keyEnv := KeyEnvironment(key); keyEnv != EnvironmentUnknown && keyEnv != env {
+			warnings = append(warnings, fmt.Sprintf("%s is a %s key but Test is %t", name, keyEnv, c.Test))
+		}
+	}
+	check("publishable key", c.PublishableKey)
+	check("token", c.Token.Reveal())
+	return warnings
+}
+
+// NewClient creates a client from the configuration. It does not check the
+// keys against Test; call Warnings to report a mismatch.
+func (c *Config) NewClient() *Client {
+	client := NewClient(c.PublishableKey, c.Token.Reveal(), c.Test, c.ShowLogs)
+	if c.BaseURL != "" {
+		client.BaseURL = c.BaseURL
+	}
+	if c.APIBaseURL != "" {
+		client.APIBaseURL = c.APIBaseURL
+	}
+	return client
+}
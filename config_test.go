@@ -0,0 +1,87 @@
+package intasend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	client := NewClient("ISPubKey_test_abc", "ISSecretKey_test_supersecret", true, false)
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if out := fmt.Sprintf(format, client); strings.Contains(out, "supersecret") {
+			t.Errorf("%s leaked the token: %s", format, out)
+		}
+		if out := fmt.Sprintf(format, *client); strings.Contains(out, "supersecret") {
+			t.Errorf("%s leaked the token from a copy of the client: %s", format, out)
+		}
+	}
+	data, err := json.Marshal(Config{Token: Secret(client.Token)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "supersecret") || !strings.Contains(string(data), redacted) {
+		t.Errorf("JSON leaked the token: %s", data)
+	}
+	if client.Token != "ISSecretKey_test_supersecret" {
+		t.Errorf("Token is %q", client.Token)
+	}
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("INTASEND_PUBLISHABLE_KEY", "ISPubKey_live_abc")
+	t.Setenv("INTASEND_TOKEN", "ISSecretKey_live_xyz")
+	t.Setenv("INTASEND_TEST", "true")
+
+	cfg, err := LoadConfigFromEnv(DefaultEnvPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PublishableKey != "ISPubKey_live_abc" || cfg.Token.Reveal() != "ISSecretKey_live_xyz" || !cfg.Test {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if warnings := cfg.Warnings(); len(warnings) != 2 {
+		t.Errorf("Expected live-key warnings in test mode, got %v", warnings)
+	}
+
+	if _, err := LoadConfigFromEnv("MISSING_"); err == nil {
+		t.Error("Expected an error when no keys are set")
+	}
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"intasend.yaml": "publishable_key: ISPubKey_test_abc\ntoken: ISSecretKey_test_xyz\nbase_url: http://localhost:8080\n",
+		"intasend.json": `{"publishable_key":"ISPubKey_test_abc","token":"ISSecretKey_test_xyz","base_url":"http://localhost:8080"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfigFromFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if cfg.Token.Reveal() != "ISSecretKey_test_xyz" || cfg.Test {
+			t.Errorf("%s: unexpected config %+v", name, cfg)
+		}
+		if warnings := cfg.Warnings(); len(warnings) != 2 || !strings.Contains(warnings[0], "sandbox key") {
+			t.Errorf("%s: expected test-key warnings in live mode, got %v", name, warnings)
+		}
+		if client := cfg.NewClient(); client.BaseURL != "http://localhost:8080" || client.Token != "ISSecretKey_test_xyz" {
+			t.Errorf("%s: unexpected client %+v", name, client)
+		}
+	}
+
+	toml := filepath.Join(dir, "intasend.toml")
+	os.WriteFile(toml, []byte(`token = "x"`), 0o600)
+	if _, err := LoadConfigFromFile(toml); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Error("Expected an error for unsupported extensions")
+	}
+}
@@ -0,0 +1,172 @@
+package intasend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownTenant is returned by credential providers that hold no credentials for a tenant
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// Credentials are the API keys a client uses on behalf of one tenant
+type Credentials struct {
+	PublishableKey string `json:"publishable_key"`
+	Token          Secret `json:"token"`
+	Test           bool   `json:"test"`
+}
+
+// CredentialProvider loads credentials for a tenant. Implementations must be
+// safe for concurrent use; ClientPool calls them again whenever it refreshes
+// a tenant, so rotated keys are picked up without restarting.
+type CredentialProvider interface {
+	Credentials(ctx context.Context, tenantID string) (*Credentials, error)
+}
+
+// CredentialProviderFunc adapts a function to CredentialProvider
+type CredentialProviderFunc func(ctx context.Context, tenantID string) (*Credentials, error)
+
+// Credentials implements CredentialProvider
+func (f CredentialProviderFunc) Credentials(ctx context.Context, tenantID string) (*Credentials, error) {
+	return f(ctx, tenantID)
+}
+
+// EnvCredentialProvider reads credentials from environment variables named
+// <Prefix><TENANT>_PUBLISHABLE_KEY, <Prefix><TENANT>_TOKEN and
+// <Prefix><TENANT>_TEST, where TENANT is the upper-cased tenant ID with
+// anything other than letters and digits replaced by underscores.
+type EnvCredentialProvider struct {
+	Prefix string // defaults to "INTASEND_"
+}
+
+// Credentials implements CredentialProvider
+func (p EnvCredentialProvider) Credentials(ctx context.Context, tenantID string) (*Credentials, error) {
+	prefix := p.Prefix
+	if prefix == "" {
+		prefix = "INTASEND_"
+	}
+	name := prefix + envName(tenantID) + "_"
+
+	creds := &Credentials{
+		PublishableKey: os.Getenv(name + "PUBLISHABLE_KEY"),
+		Token:          Secret(os.Getenv(name + "TOKEN")),
+	}
+	if creds.PublishableKey == "" && creds.Token == "" {
+		return nil, fmt.Errorf("%w %q: %sPUBLISHABLE_KEY and %sTOKEN are not set", ErrUnknownTenant, tenantID, name, name)
+	}
+	if v := os.Getenv(name + "TEST"); v != "" {
+		test, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %sTEST: %w", name, err)
+		}
+		creds.Test = test
+	}
+	return creds, nil
+}
+
+func envName(tenantID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, tenantID)
+}
+
+// FileCredentialProvider reads credentials from a JSON file mapping tenant IDs
+// to Credentials. The file is re-read whenever its modification time changes,
+// so keys rotated by a secrets sidecar are picked up.
+type FileCredentialProvider struct {
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	tenants map[string]*Credentials
+}
+
+// NewFileCredentialProvider creates a provider for the JSON file at path
+func NewFileCredentialProvider(path string) *FileCredentialProvider {
+	return &FileCredentialProvider{Path: path}
+}
+
+// Credentials implements CredentialProvider
+func (p *FileCredentialProvider) Credentials(ctx context.Context, tenantID string) (*Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	if p.tenants == nil || !info.ModTime().Equal(p.modTime) {
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		var tenants map[string]*Credentials
+		if err := json.Unmarshal(data, &tenants); err != nil {
+			return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+		}
+		p.tenants, p.modTime = tenants, info.ModTime()
+	}
+
+	creds, ok := p.tenants[tenantID]
+	if !ok || creds == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTenant, tenantID)
+	}
+	c := *creds
+	return &c, nil
+}
+
+// SecretReader reads a secret from a key/value secret store such as
+// HashiCorp Vault's KV engine. The returned map holds the secret's fields.
+type SecretReader interface {
+	ReadSecret(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// SecretStoreCredentialProvider loads credentials from a SecretReader. Each
+// tenant's secret lives at fmt.Sprintf(PathFormat, tenantID) and holds the
+// fields publishable_key, token and (optionally) test.
+type SecretStoreCredentialProvider struct {
+	Store      SecretReader
+	PathFormat string // defaults to "intasend/%s"
+}
+
+// Credentials implements CredentialProvider
+func (p SecretStoreCredentialProvider) Credentials(ctx context.Context, tenantID string) (*Credentials, error) {
+	format := p.PathFormat
+	if format == "" {
+		format = "intasend/%s"
+	}
+	path := fmt.Sprintf(format, tenantID)
+
+	secret, err := p.Store.ReadSecret(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", path, err)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("%w %q: no secret at %s", ErrUnknownTenant, tenantID, path)
+	}
+
+	creds := &Credentials{}
+	creds.PublishableKey, _ = secret["publishable_key"].(string)
+	token, _ := secret["token"].(string)
+	creds.Token = Secret(token)
+	switch v := secret["test"].(type) {
+	case bool:
+		creds.Test = v
+	case string:
+		creds.Test, _ = strconv.ParseBool(v)
+	}
+	return creds, nil
+}
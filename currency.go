@@ -0,0 +1,273 @@
+package intasend
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Currency describes a currency: its ISO 4217 codes and how amounts in it
+// are written
+type Currency struct {
+	Code       CurrencyType `json:"code"`
+	Numeric    string       `json:"numeric"` // ISO 4217 numeric code, e.g. "404"
+	Name       string       `json:"name"`
+	MinorUnits int          `json:"minor_units"` // digits after the decimal point
+	Symbol     string       `json:"symbol"`
+	Countries  []string     `json:"countries"` // ISO 3166 alpha-2 codes of the countries using it
+	Locale     string       `json:"locale"`    // BCP 47 tag amounts are displayed in, e.g. "en-KE"
+}
+
+var eurozone = []string{"AT", "BE", "CY", "DE", "EE", "ES", "FI", "FR", "GR", "HR", "IE", "IT", "LT", "LU", "LV", "MT", "NL", "PT", "SI", "SK"}
+
+var currencies = struct {
+	sync.RWMutex
+	m map[CurrencyType]Currency
+}{m: map[CurrencyType]Currency{
+	CurrencyKES: {Code: CurrencyKES, Numeric: "404", Name: "Kenyan shilling", MinorUnits: 2, Symbol: "KSh", Countries: []string{"KE"}, Locale: "en-KE"},
+	CurrencyUGX: {Code: CurrencyUGX, Numeric: "800", Name: "Ugandan shilling", MinorUnits: 0, Symbol: "USh", Countries: []string{"UG"}, Locale: "en-UG"},
+	CurrencyTZS: {Code: CurrencyTZS, Numeric: "834", Name: "Tanzanian shilling", MinorUnits: 2, Symbol: "TSh", Countries: []string{"TZ"}, Locale: "en-TZ"},
+	CurrencyNGN: {Code: CurrencyNGN, Numeric: "566", Name: "Nigerian naira", MinorUnits: 2, Symbol: "₦", Countries: []string{"NG"}, Locale: "en-NG"},
+	CurrencyGHS: {Code: CurrencyGHS, Numeric: "936", Name: "Ghanaian cedi", MinorUnits: 2, Symbol: "GH₵", Countries: []string{"GH"}, Locale: "en-GH"},
+	CurrencyXAF: {Code: CurrencyXAF, Numeric: "950", Name: "Central African CFA franc", MinorUnits: 0, Symbol: "FCFA", Countries: []string{"CF", "CG", "CM", "GA", "GQ", "TD"}, Locale: "fr-CM"},
+	CurrencyXOF: {Code: CurrencyXOF, Numeric: "952", Name: "West African CFA franc", MinorUnits: 0, Symbol: "CFA", Countries: []string{"BF", "BJ", "CI", "GW", "ML", "NE", "SN", "TG"}, Locale: "fr-SN"},
+	CurrencyUSD: {Code: CurrencyUSD, Numeric: "840", Name: "US dollar", MinorUnits: 2, Symbol: "$", Countries: []string{"US"}, Locale: "en-US"},
+	CurrencyGBP: {Code: CurrencyGBP, Numeric: "826", Name: "Pound sterling", MinorUnits: 2, Symbol: "£", Countries: []string{"GB"}, Locale: "en-GB"},
+	CurrencyEUR: {Code: CurrencyEUR, Numeric: "978", Name: "Euro", MinorUnits: 2, Symbol: "€", Countries: eurozone, Locale: "en-IE"},
+}}
+
+// LookupCurrency returns the registered metadata for a currency code
+func LookupCurrency(code CurrencyType) (Currency, bool) {
+	currencies.RLock()
+	defer currencies.RUnlock()
+	c, ok := currencies.m[code]
+	c.Countries = slices.Clone(c.Countries)
+	return c, ok
+}
+
+// Currencies returns every registered currency, sorted by code
+func Currencies() []Currency {
+	currencies.RLock()
+	list := make([]Currency, 0, len(currencies.m))
+	for _, c := range currencies.m {
+		c.Countries = slices.Clone(c.Countries)
+		list = append(list, c)
+	}
+	currencies.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// RegisterCurrency adds a currency to the registry, or replaces the entry
+// with the same code. Requests are only accepted in registered currencies,
+// so this is how to use one IntaSend supports before the SDK lists it.
+func RegisterCurrency(c Currency) error {
+	if len(c.Code) != 3 || strings.ToUpper(string(c.Code)) != string(c.Code) {
+		return fmt.Errorf("currency code must be three upper-case letters, got %q", c.Code)
+	}
+	if c.MinorUnits < 0 || c.MinorUnits > 4 {
+		return fmt.Errorf("minor units of %s must be between 0 and 4", c.Code)
+	}
+	c.Countries = slices.Clone(c.Countries)
+	currencies.Lock()
+	currencies.m[c.Code] = c
+	currencies.Unlock()
+	return nil
+}
+
+// Valid reports whether the currency is registered
+func (c CurrencyType) Valid() bool {
+	_, ok := LookupCurrency(c)
+	return ok
+}
+
+// Validate returns an error if the currency is not registered
+func (c CurrencyType) Validate() error {
+	if !c.Valid() {
+		return fmt.Errorf("unsupported currency %q", c)
+	}
+	return nil
+}
+
+// Format writes an amount with the currency code, e.g. "KES 1,234.50". An
+// unregistered currency is formatted with two decimal places.
+func (c CurrencyType) Format(amount float64) string {
+	cur, ok := LookupCurrency(c)
+	if !ok {
+		cur = Currency{Code: c, MinorUnits: 2}
+	}
+	return cur.Format(amount)
+}
+
+// Display writes an amount the way it is shown locally, e.g. "USh 3,013"
+func (c CurrencyType) Display(amount float64) string {
+	cur, ok := LookupCurrency(c)
+	if !ok {
+		return c.Format(amount)
+	}
+	return cur.Display(amount)
+}
+
+// Format writes an amount with the currency code and English separators,
+// e.g. "KES 1,234.50", which suits logs and files
+func (c *Currency) Format(amount float64) string {
+	return string(c.Code) + " " + formatNumber(amount, c.MinorUnits, ",", ".")
+}
+
+// Display writes an amount with the currency symbol in the conventions of
+// its locale: "KSh 1,234.50" in English, "1 234 FCFA" in French
+func (c *Currency) Display(amount float64) string {
+	group, decimal := c.separators()
+	n := formatNumber(amount, c.MinorUnits, group, decimal)
+	if c.symbolAfter() {
+		return n + "\u00a0" + c.Symbol
+	}
+	return c.Symbol + " " + n
+}
+
+// ParseAmount reads an amount written with a registered currency's code or
+// symbol before or after it, such as "KES 1,234.50", "USh 3,013" or
+// "1 234 FCFA". It rejects more decimal places than the currency has.
+func ParseAmount(s string) (float64, CurrencyType, error) {
+	text, sign := strings.TrimSpace(s), ""
+	if rest, ok := strings.CutPrefix(text, "-"); ok {
+		text, sign = rest, "-"
+	}
+	cur, number, ok := matchCurrency(text)
+	if !ok {
+		return 0, "", fmt.Errorf("no currency code or symbol in %q", s)
+	}
+	amount, err := cur.parseNumber(sign + strings.TrimSpace(number))
+	if err != nil {
+		return 0, cur.Code, fmt.Errorf("invalid %s amount %q: %w", cur.Code, s, err)
+	}
+	return amount, cur.Code, nil
+}
+
+// matchCurrency finds the code or symbol at either end of s, trying longer
+// ones first so "FCFA" wins over "CFA", and returns the rest of s
+func matchCurrency(s string) (Currency, string, bool) {
+	type marker struct {
+		text string
+		cur  Currency
+		code bool
+	}
+	var markers []marker
+	for _, c := range Currencies() {
+		markers = append(markers, marker{string(c.Code), c, true})
+		if c.Symbol != "" {
+			markers = append(markers, marker{c.Symbol, c, false})
+		}
+	}
+	sort.SliceStable(markers, func(i, j int) bool {
+		return utf8.RuneCountInString(markers[i].text) > utf8.RuneCountInString(markers[j].text)
+	})
+
+	for _, m := range markers {
+		n := len(m.text)
+		if len(s) < n {
+			continue
+		}
+		head, tail := s[:n], s[len(s)-n:]
+		switch {
+		case head == m.text || (m.code && strings.EqualFold(head, m.text)):
+			return m.cur, s[n:], true
+		case tail == m.text || (m.code && strings.EqualFold(tail, m.text)):
+			return m.cur, s[:len(s)-n], true
+		}
+	}
+	return Currency{}, "", false
+}
+
+// parseNumber reads the numeric part of an amount in the currency's locale
+func (c *Currency) parseNumber(s string) (float64, error) {
+	_, decimal := c.separators()
+	s = strings.Trim(s, " \u00a0\u202f")
+	neg := false
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		neg, s = true, rest
+	} else if rest, ok := strings.CutPrefix(s, "\u2212"); ok {
+		neg, s = true, rest
+	}
+
+	var digits strings.Builder
+	decimals := -1
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+			if decimals >= 0 {
+				decimals++
+			}
+		case string(r) == decimal:
+			if decimals >= 0 {
+				return 0, fmt.Errorf("more than one decimal separator")
+			}
+			digits.WriteByte('.')
+			decimals = 0
+		case r == ',' || r == '.' || r == ' ' || r == '\u00a0' || r == '\u202f' || r == '\'':
+			if decimals >= 0 {
+				return 0, fmt.Errorf("digit grouping after the decimal separator")
+			}
+		default:
+			return 0, fmt.Errorf("unexpected %q", r)
+		}
+	}
+	if digits.Len() == 0 || digits.String() == "." {
+		return 0, fmt.Errorf("no digits")
+	}
+	if decimals > c.MinorUnits {
+		if c.MinorUnits == 0 {
+			return 0, fmt.Errorf("%s has no minor units", c.Code)
+		}
+		return 0, fmt.Errorf("%s allows at most %d decimal places", c.Code, c.MinorUnits)
+	}
+	v, err := strconv.ParseFloat(digits.String(), 64)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// separators returns the digit group and decimal separators of the locale
+func (c *Currency) separators() (group, decimal string) {
+	if c.symbolAfter() {
+		return "\u202f", ","
+	}
+	return ",", "."
+}
+
+// symbolAfter reports whether the locale writes the symbol after the number
+func (c *Currency) symbolAfter() bool {
+	return strings.HasPrefix(c.Locale, "fr")
+}
+
+// formatNumber rounds to the given decimals and groups thousands
+func formatNumber(amount float64, decimals int, group, decimal string) string {
+	s := strconv.FormatFloat(math.Abs(amount), 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if amount < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(group)
+		}
+		b.WriteRune(r)
+	}
+	if frac != "" {
+		b.WriteString(decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package intasend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCurrencyFormat(t *testing.T) {
+	tests := []struct {
+		currency CurrencyType
+		amount   float64
+		format   string
+		display  string
+	}{
+		{CurrencyKES, 1234.5, "KES 1,234.50", "KSh 1,234.50"},
+		{CurrencyUGX, 3013.4, "UGX 3,013", "USh 3,013"},
+		{CurrencyTZS, 0, "TZS 0.00", "TSh 0.00"},
+		{CurrencyNGN, -1500000, "NGN -1,500,000.00", "₦ -1,500,000.00"},
+		{CurrencyXAF, 1234567, "XAF 1,234,567", "1\u202f234\u202f567\u00a0FCFA"},
+		{CurrencyXOF, 999.6, "XOF 1,000", "1\u202f000\u00a0CFA"},
+		{CurrencyKES, -0.001, "KES 0.00", "KSh 0.00"},
+		{"ZZZ", 12.345, "ZZZ 12.35", "ZZZ 12.35"},
+	}
+	for _, tt := range tests {
+		if got := tt.currency.Format(tt.amount); got != tt.format {
+			t.Errorf("%s.Format(%v) = %q, want %q", tt.currency, tt.amount, got, tt.format)
+		}
+		if got := tt.currency.Display(tt.amount); got != tt.display {
+			t.Errorf("%s.Display(%v) = %q, want %q", tt.currency, tt.amount, got, tt.display)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in       string
+		amount   float64
+		currency CurrencyType
+	}{
+		{"KES 1,234.50", 1234.5, CurrencyKES},
+		{"kes1234.5", 1234.5, CurrencyKES},
+		{"USh 3,013", 3013, CurrencyUGX},
+		{"3013 UGX", 3013, CurrencyUGX},
+		{"1\u202f234\u00a0FCFA", 1234, CurrencyXAF},
+		{"1 234 CFA", 1234, CurrencyXOF},
+		{"GH₵ 20.05", 20.05, CurrencyGHS},
+		{"-KES 50", -50, CurrencyKES},
+		{"KSh -50.00", -50, CurrencyKES},
+	}
+	for _, tt := range tests {
+		amount, currency, err := ParseAmount(tt.in)
+		if err != nil || amount != tt.amount || currency != tt.currency {
+			t.Errorf("ParseAmount(%q) = %v, %s, %v; want %v, %s", tt.in, amount, currency, err, tt.amount, tt.currency)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"1,234.50":     "no currency code or symbol",
+		"USh 3,013.50": "UGX has no minor units",
+		"KES 1.234":    "KES allows at most 2 decimal places",
+		"KES 12a":      "unexpected 'a'",
+		"KES":          "no digits",
+		"KES 1.2.3":    "more than one decimal separator",
+	} {
+		if _, _, err := ParseAmount(in); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseAmount(%q) error = %v, want %q", in, err, want)
+		}
+	}
+}
+
+func TestCurrencyRegistry(t *testing.T) {
+	kes, ok := LookupCurrency(CurrencyKES)
+	if !ok || kes.Numeric != "404" || kes.MinorUnits != 2 || kes.Countries[0] != "KE" {
+		t.Fatalf("Unexpected KES metadata: %+v", kes)
+	}
+	kes.Countries[0] = "XX"
+	if again, _ := LookupCurrency(CurrencyKES); again.Countries[0] != "KE" {
+		t.Error("Expected lookups to return a copy")
+	}
+	if list := Currencies(); len(list) < 10 || list[0].Code != CurrencyEUR {
+		t.Errorf("Expected the built-in currencies sorted by code, got %d starting with %s", len(list), list[0].Code)
+	}
+
+	if CurrencyType("RWF").Valid() {
+		t.Fatal("Expected RWF to be unregistered")
+	}
+	client := NewClient("pk", "token", true, false)
+	_, err := client.CreatePaymentLink(&PaymentLinkRequest{Title: "Donations", Currency: "RWF"})
+	if err == nil || err.Error() != `unsupported currency "RWF"` {
+		t.Errorf("Expected an unsupported currency error, got %v", err)
+	}
+
+	if err := RegisterCurrency(Currency{Code: "rwf"}); err == nil {
+		t.Error("Expected a lower-case code to be rejected")
+	}
+	if err := RegisterCurrency(Currency{Code: "RWF", Numeric: "646", Name: "Rwandan franc", Symbol: "FRw", Countries: []string{"RW"}, Locale: "en-RW"}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		currencies.Lock()
+		delete(currencies.m, "RWF")
+		currencies.Unlock()
+	}()
+	if err := CurrencyType("RWF").Validate(); err != nil {
+		t.Errorf("Expected RWF to validate once registered: %v", err)
+	}
+	if amount, currency, err := ParseAmount("FRw 5,000"); err != nil || amount != 5000 || currency != "RWF" {
+		t.Errorf("Expected registered symbols to parse, got %v %s %v", amount, currency, err)
+	}
+}
@@ -0,0 +1,294 @@
+package intasend
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Customer is a customer record as stored by IntaSend. It is returned by the
+// customer endpoints and embedded in payment status and IntaSend-XB push
+// responses.
+type Customer struct {
+	CustomerID  string    `json:"customer_id"`  // Customer ID (e.g., "ZOEW022")
+	PhoneNumber string    `json:"phone_number"` // Customer phone number
+	Email       string    `json:"email"`        // Customer email address
+	FirstName   string    `json:"first_name"`   // Customer first name
+	LastName    string    `json:"last_name"`    // Customer last name
+	Country     string    `json:"country"`      // Country code (e.g., "KE")
+	Address     string    `json:"address"`      // Customer address
+	City        string    `json:"city"`         // Customer city
+	State       string    `json:"state"`        // Customer state/province
+	ZipCode     string    `json:"zipcode"`      // Customer zip/postal code
+	Provider    string    `json:"provider"`     // Payment provider last used
+	CreatedAt   time.Time `json:"created_at"`   // Customer record creation timestamp
+	UpdatedAt   time.Time `json:"updated_at"`   // Customer record update timestamp
+
+	// ID is the customer ID as payment status responses used to report it.
+	// UnmarshalJSON sets it to CustomerID.
+	//
+	// Deprecated: use CustomerID.
+	ID string `json:"-"`
+}
+
+// IntaSendXBCustomer is the customer returned in IntaSend-XB push responses.
+//
+// Deprecated: use Customer.
+type IntaSendXBCustomer = Customer
+
+// UnmarshalJSON accepts both customer shapes IntaSend returns: payment status
+// responses identify the customer by "id" and may send nulls or empty strings
+// for unset fields and timestamps.
+func (c *Customer) UnmarshalJSON(data []byte) error {
+	type customer Customer
+	aux := struct {
+		*customer
+		ID        string `json:"id"`
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}{customer: (*customer)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if c.CustomerID == "" {
+		c.CustomerID = aux.ID
+	}
+	c.ID = c.CustomerID
+
+	var err error
+	if c.CreatedAt, err = parseTimestamp(aux.CreatedAt); err != nil {
+		return fmt.Errorf("invalid customer created_at: %w", err)
+	}
+	if c.UpdatedAt, err = parseTimestamp(aux.UpdatedAt); err != nil {
+		return fmt.Errorf("invalid customer updated_at: %w", err)
+	}
+	return nil
+}
+
+// parseTimestamp parses an RFC 3339 timestamp, treating "" as the zero time
+func parseTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// FullName returns the customer's first and last name separated by a space
+func (c *Customer) FullName() string {
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
+
+// CustomerRequest is the payload for creating a customer record
+type CustomerRequest struct {
+	Email       string `json:"email,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	FirstName   string `json:"first_name,omitempty"`
+	LastName    string `json:"last_name,omitempty"`
+	Country     string `json:"country,omitempty"`
+	Address     string `json:"address,omitempty"`
+	City        string `json:"city,omitempty"`
+	State       string `json:"state,omitempty"`
+	ZipCode     string `json:"zipcode,omitempty"`
+}
+
+// CustomerUpdate holds the fields to change on a customer; nil fields are left as they are
+type CustomerUpdate struct {
+	Email       *string `json:"email,omitempty"`
+	PhoneNumber *string `json:"phone_number,omitempty"`
+	FirstName   *string `json:"first_name,omitempty"`
+	LastName    *string `json:"last_name,omitempty"`
+	Country     *string `json:"country,omitempty"`
+	Address     *string `json:"address,omitempty"`
+	City        *string `json:"city,omitempty"`
+	State       *string `json:"state,omitempty"`
+	ZipCode     *string `json:"zipcode,omitempty"`
+}
+
+// PaginatedCustomers represents the paginated response for listing customers
+type PaginatedCustomers struct {
+	Count    int        `json:"count"`
+	Next     *string    `json:"next"`
+	Previous *string    `json:"previous"`
+	Results  []Customer `json:"results"`
+}
+
+// ListCustomersParams defines optional filters and pagination for listing customers
+type ListCustomersParams struct {
+	Page        *int   // optional page number
+	PageSize    *int   // optional page size
+	Email       string // optional exact-match filter by email
+	PhoneNumber string // optional exact-match filter by phone number
+}
+
+// CreateCustomer creates a customer record that later checkouts and pushes can reference by ID
+func (c *Client) CreateCustomer(req *CustomerRequest, opts ...CallOption) (*Customer, error) {
+	return invoke(c, &Invocation{Operation: OpCustomerCreate, Request: req}, opts,
+		func(inv *Invocation, opts []CallOption) (*Customer, error) {
+			req, err := requestAs[*CustomerRequest](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.createCustomer(req, opts)
+		})
+}
+
+// createCustomer validates the request and posts it to the customers endpoint
+func (c *Client) createCustomer(req *CustomerRequest, opts []CallOption) (*Customer, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to create customers")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	if req.Email == "" && req.PhoneNumber == "" {
+		return nil, fmt.Errorf("email or phone number is required")
+	}
+	if req.Email != "" && !ValidateEmail(req.Email) {
+		return nil, fmt.Errorf("invalid email: %s", req.Email)
+	}
+
+	var customer Customer
+	err := c.call(&RequestOptions{
+		Method:   POST,
+		Endpoint: "api/v1/customers/",
+		Body:     req,
+		UseToken: true,
+	}, &customer, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// ListCustomers retrieves a paginated list of customers with optional filters
+func (c *Client) ListCustomers(params *ListCustomersParams, opts ...CallOption) (*PaginatedCustomers, error) {
+	return invoke(c, &Invocation{Operation: OpCustomersList, Request: params}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaginatedCustomers, error) {
+			params, err := requestAs[*ListCustomersParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listCustomers(params, opts)
+		})
+}
+
+// listCustomers builds the query and fetches one page of customers
+func (c *Client) listCustomers(params *ListCustomersParams, opts []CallOption) (*PaginatedCustomers, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to list customers")
+	}
+
+	queryParams := make(map[string]string)
+	if params != nil {
+		setPageParams(queryParams, params.Page, params.PageSize)
+		if params.Email != "" {
+			queryParams["email"] = params.Email
+		}
+		if params.PhoneNumber != "" {
+			queryParams["phone_number"] = params.PhoneNumber
+		}
+	}
+	return c.fetchCustomers(queryParams, opts)
+}
+
+// SearchCustomers returns the customers whose email or phone number contains query
+func (c *Client) SearchCustomers(query string, opts ...CallOption) (*PaginatedCustomers, error) {
+	return invoke(c, &Invocation{Operation: OpCustomersSearch, Request: query}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaginatedCustomers, error) {
+			query, err := requestAs[string](inv)
+			if err != nil {
+				return nil, err
+			}
+			if c.Token == "" {
+				return nil, fmt.Errorf("token is required to search customers")
+			}
+			if strings.TrimSpace(query) == "" {
+				return nil, fmt.Errorf("query is required")
+			}
+			return c.fetchCustomers(map[string]string{"search": query}, opts)
+		})
+}
+
+func (c *Client) fetchCustomers(queryParams map[string]string, opts []CallOption) (*PaginatedCustomers, error) {
+	var result PaginatedCustomers
+	err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    "api/v1/customers/",
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &result, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetCustomer retrieves a single customer by ID
+func (c *Client) GetCustomer(customerID string, opts ...CallOption) (*Customer, error) {
+	return invoke(c, &Invocation{Operation: OpCustomerGet, ResourceID: customerID}, opts,
+		func(inv *Invocation, opts []CallOption) (*Customer, error) {
+			return c.getCustomer(inv.ResourceID, opts)
+		})
+}
+
+// getCustomer fetches a single customer
+func (c *Client) getCustomer(customerID string, opts []CallOption) (*Customer, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to get customer")
+	}
+	if customerID == "" {
+		return nil, fmt.Errorf("customerID is required")
+	}
+
+	var customer Customer
+	if err := c.call(&RequestOptions{Method: GET, Endpoint: customerEndpoint(customerID), UseToken: true}, &customer, opts); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// UpdateCustomer changes the non-nil fields of a customer
+func (c *Client) UpdateCustomer(customerID string, update *CustomerUpdate, opts ...CallOption) (*Customer, error) {
+	return invoke(c, &Invocation{Operation: OpCustomerUpdate, Request: update, ResourceID: customerID}, opts,
+		func(inv *Invocation, opts []CallOption) (*Customer, error) {
+			update, err := requestAs[*CustomerUpdate](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.updateCustomer(inv.ResourceID, update, opts)
+		})
+}
+
+// updateCustomer sends a partial update for a customer
+func (c *Client) updateCustomer(customerID string, update *CustomerUpdate, opts []CallOption) (*Customer, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to update customer")
+	}
+	if customerID == "" {
+		return nil, fmt.Errorf("customerID is required")
+	}
+	if update == nil {
+		return nil, fmt.Errorf("update is required")
+	}
+	if update.Email != nil && *update.Email != "" && !ValidateEmail(*update.Email) {
+		return nil, fmt.Errorf("invalid email: %s", *update.Email)
+	}
+
+	var customer Customer
+	err := c.call(&RequestOptions{
+		Method:   PATCH,
+		Endpoint: customerEndpoint(customerID),
+		Body:     update,
+		UseToken: true,
+	}, &customer, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func customerEndpoint(customerID string) string {
+	return fmt.Sprintf("api/v1/customers/%s/", url.PathEscape(customerID))
+}
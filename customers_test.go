@@ -0,0 +1,117 @@
+package intasend
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCustomerUnmarshalShapes(t *testing.T) {
+	// Payment status responses identify the customer by "id" and use nulls
+	statusShape := `{
+		"id": "CUST456",
+		"phone_number": "256759739706",
+		"email": null,
+		"first_name": "John",
+		"last_name": "",
+		"created_at": "2024-01-01T00:00:00+03:00",
+		"updated_at": ""
+	}`
+	// IntaSend-XB push and the customer endpoints use "customer_id"
+	recordShape := `{
+		"customer_id": "CUST456",
+		"phone_number": "256759739706",
+		"email": "customer@example.com",
+		"first_name": "John",
+		"last_name": "Doe",
+		"created_at": "2024-01-01T00:00:00.123456+03:00",
+		"updated_at": "2024-01-02T00:00:00+03:00"
+	}`
+
+	var fromStatus, fromRecord Customer
+	if err := json.Unmarshal([]byte(statusShape), &fromStatus); err != nil {
+		t.Fatalf("Failed to unmarshal payment status customer: %v", err)
+	}
+	if err := json.Unmarshal([]byte(recordShape), &fromRecord); err != nil {
+		t.Fatalf("Failed to unmarshal customer record: %v", err)
+	}
+
+	if fromStatus.CustomerID != "CUST456" || fromRecord.CustomerID != "CUST456" {
+		t.Errorf("Expected both shapes to carry the customer ID, got %q and %q", fromStatus.CustomerID, fromRecord.CustomerID)
+	}
+	if fromStatus.ID != "CUST456" || fromRecord.ID != "CUST456" {
+		t.Errorf("Expected the deprecated ID to mirror CustomerID, got %q and %q", fromStatus.ID, fromRecord.ID)
+	}
+	if data, _ := json.Marshal(fromStatus); strings.Contains(string(data), `"id"`) {
+		t.Errorf("Expected only customer_id to be marshalled, got %s", data)
+	}
+	if fromStatus.Email != "" || fromStatus.CreatedAt.IsZero() || !fromStatus.UpdatedAt.IsZero() {
+		t.Errorf("Unexpected payment status customer: %+v", fromStatus)
+	}
+	if fromRecord.FullName() != "John Doe" || fromRecord.UpdatedAt.Day() != 2 {
+		t.Errorf("Unexpected customer record: %+v", fromRecord)
+	}
+
+	if err := json.Unmarshal([]byte(`{"created_at": "yesterday"}`), &fromRecord); err == nil {
+		t.Error("Expected an invalid timestamp to fail")
+	}
+}
+
+func TestCustomerRequests(t *testing.T) {
+	page, size := 2, 25
+	city := "Nairobi"
+	runRequestCases(t, `{"customer_id":"CUS1","created_at":"2024-01-01T00:00:00+03:00"}`, []requestCase{
+		{
+			name: "create",
+			call: func(c *Client) error {
+				_, err := c.CreateCustomer(&CustomerRequest{Email: "jane@example.com", FirstName: "Jane", Country: "KE"})
+				return err
+			},
+			method: "POST",
+			path:   "/api/v1/customers/",
+			body:   `{"email":"jane@example.com","first_name":"Jane","country":"KE"}`,
+		},
+		{
+			name: "list",
+			call: func(c *Client) error {
+				_, err := c.ListCustomers(&ListCustomersParams{Page: &page, PageSize: &size, PhoneNumber: "254712345678"})
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/customers/",
+			query:  "page=2&page_size=25&phone_number=254712345678",
+		},
+		{
+			name: "search",
+			call: func(c *Client) error {
+				_, err := c.SearchCustomers("jane@")
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/customers/",
+			query:  "search=jane%40",
+		},
+		{
+			name: "get",
+			call: func(c *Client) error {
+				customer, err := c.GetCustomer("CUS1")
+				if err == nil && customer.CustomerID != "CUS1" {
+					t.Errorf("Expected the response to be decoded, got %+v", customer)
+				}
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/customers/CUS1/",
+		},
+		{
+			name: "update",
+			call: func(c *Client) error {
+				_, err := c.UpdateCustomer("CUS1", &CustomerUpdate{City: &city})
+				return err
+			},
+			method: "PATCH",
+			path:   "/api/v1/customers/CUS1/",
+			body:   `{"city":"Nairobi"}`,
+		},
+	})
+}
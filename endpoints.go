@@ -0,0 +1,58 @@
+package intasend
+
+import (
+	"net/url"
+	"strings"
+)
+
+// EndpointFamily groups API endpoints that share rate limits, health and metrics
+type EndpointFamily string
+
+const (
+	FamilyCheckout      EndpointFamily = "checkout"
+	FamilyPaymentStatus EndpointFamily = "payment_status"
+	FamilyCollection    EndpointFamily = "collection" // STK/XB push collections
+	FamilySendMoney     EndpointFamily = "send_money"
+	FamilyWallets       EndpointFamily = "wallets"
+	FamilyTransactions  EndpointFamily = "transactions"
+	FamilyInvoices      EndpointFamily = "invoices"
+	FamilyPaymentLinks  EndpointFamily = "payment_links"
+	FamilySubscriptions EndpointFamily = "subscriptions"
+	FamilyCustomers     EndpointFamily = "customers"
+	FamilyOther         EndpointFamily = "other"
+)
+
+// endpointFamilyPrefixes maps API path prefixes (after /api/v1/) to families.
+// More specific prefixes must come first.
+var endpointFamilyPrefixes = []struct {
+	prefix string
+	family EndpointFamily
+}{
+	{"checkout/", FamilyCheckout},
+	{"payment/status/", FamilyPaymentStatus},
+	{"payment/", FamilyCollection},
+	{"send-money/", FamilySendMoney},
+	{"wallets/", FamilyWallets},
+	{"transactions/", FamilyTransactions},
+	{"invoices/", FamilyInvoices},
+	{"paymentlinks/", FamilyPaymentLinks},
+	{"subscriptions/", FamilySubscriptions},
+	{"customers/", FamilyCustomers},
+}
+
+// EndpointFamilyFor classifies an endpoint path or full URL into its family
+func EndpointFamilyFor(endpoint string) EndpointFamily {
+	path := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Path != "" {
+		path = u.Path
+	}
+	path = strings.TrimPrefix(path, "/")
+	path = strings.TrimPrefix(path, "api/v1/")
+
+	for _, p := range endpointFamilyPrefixes {
+		if strings.HasPrefix(path, p.prefix) {
+			return p.family
+		}
+	}
+	return FamilyOther
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/joho/godotenv"
 	"github.com/techliana/intasend-sdk-golang"
@@ -17,9 +16,17 @@ func main() {
 		log.Fatal(envErr)
 	}
 
-	publishable := os.Getenv("PUBLISHABLE_KEY")
-	token := os.Getenv("TOKEN")
-	client := intasend.NewClient(publishable, token, false, true)
+	// Reads PUBLISHABLE_KEY, TOKEN, TEST and SHOW_LOGS; use
+	// intasend.DefaultEnvPrefix for INTASEND_PUBLISHABLE_KEY etc.
+	cfg, err := intasend.LoadConfigFromEnv("")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg.ShowLogs = true
+	for _, w := range cfg.Warnings() {
+		log.Printf("warning: %s", w)
+	}
+	client := cfg.NewClient()
 
 	// resp, err := client.SendIntaSendXBPush(&intasend.IntaSendXBPushRequest{
 	// 	Amount:       "500",
@@ -80,7 +87,6 @@ func main() {
 			fmt.Printf("  Tx: %s -> %s, Amount: %v, Status: %s\n", tx.Name, tx.Account, tx.Amount, tx.Status)
 		}
 	}
-	return
 
 	// Example 1: List all invoices
 	invoices, err := client.ListInvoices(nil)
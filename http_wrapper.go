@@ -2,12 +2,15 @@ package intasend
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // HTTPMethod represents HTTP methods
@@ -28,8 +31,9 @@ type RequestOptions struct {
 	Body        interface{}
 	QueryParams map[string]string
 	Headers     map[string]string
-	UseToken    bool // Whether to use Bearer token authentication
-	UseAPIKey   bool // Whether to use API key authentication
+	UseToken    bool            // Whether to use Bearer token authentication
+	UseAPIKey   bool            // Whether to use API key authentication
+	Context     context.Context // Optional context for cancellation and deadlines
 }
 
 // HTTPResponse represents the response from an HTTP request
@@ -37,6 +41,45 @@ type HTTPResponse struct {
 	StatusCode int
 	Body       []byte
 	Headers    http.Header
+	Latency    time.Duration // Time from sending the request to reading the full body
+}
+
+// requestIDHeaders lists the headers checked, in order, for a request identifier
+var requestIDHeaders = []string{
+	"X-Request-ID",
+	"X-Request-Id",
+	"Request-Id",
+	"X-Correlation-ID",
+	"X-Amzn-RequestId",
+	"Cf-Ray",
+}
+
+// RequestID returns the request identifier assigned by IntaSend, if any.
+// Quote this value when contacting IntaSend support about a call.
+func (r *HTTPResponse) RequestID() string {
+	if r == nil {
+		return ""
+	}
+	for _, h := range requestIDHeaders {
+		if v := r.Headers.Get(h); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// APIError is returned when IntaSend responds with a 4xx or 5xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+	Errors     map[string]interface{}
+	RequestID  string
+	Body       []byte
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (%d): %s", e.StatusCode, e.Message)
 }
 
 // DoRequest performs an HTTP request with the given options
@@ -48,9 +91,10 @@ func (c *Client) DoRequest(opts *RequestOptions) (*HTTPResponse, error) {
 	}
 
 	// Prepare request body
+	var bodyBytes []byte
 	var bodyReader io.Reader
 	if opts.Body != nil {
-		bodyBytes, err := c.prepareBody(opts.Body)
+		bodyBytes, err = c.prepareBody(opts.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to prepare request body: %w", err)
 		}
@@ -62,8 +106,13 @@ func (c *Client) DoRequest(opts *RequestOptions) (*HTTPResponse, error) {
 		}
 	}
 
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// Create HTTP request
-	req, err := http.NewRequest(string(opts.Method), fullURL, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, string(opts.Method), fullURL, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -76,53 +125,119 @@ func (c *Client) DoRequest(opts *RequestOptions) (*HTTPResponse, error) {
 		c.logRequest(req)
 	}
 
+	// Let instrumentation observe the request, including rejections by the
+	// checks below
+	family := EndpointFamilyFor(fullURL)
+	finish := func(*HTTPResponse, error) {}
+	if c.Instrumentation != nil {
+		req, finish = c.Instrumentation.StartRequest(req, RequestInfo{
+			Family:  family,
+			Method:  req.Method,
+			URL:     fullURL,
+			Attempt: 1,
+			Body:    bodyBytes,
+		})
+	}
+
+	// Catch keys from the wrong environment before IntaSend answers 401
+	if c.StrictKeys {
+		if err := c.checkRequestKeys(opts); err != nil {
+			finish(nil, err)
+			return nil, err
+		}
+	}
+
+	// Fail fast if the family's circuit is open
+	recordOutcome := func(*HTTPResponse, error) {}
+	if c.CircuitBreaker != nil {
+		recordOutcome, err = c.CircuitBreaker.Allow(family)
+		if err != nil {
+			finish(nil, err)
+			return nil, err
+		}
+	}
+
+	// Wait for the rate limiter if one is configured
+	if c.RateLimiter != nil {
+		release, err := c.RateLimiter.Wait(req.Context(), family)
+		if err != nil {
+			err = fmt.Errorf("%w: %w", ErrRateLimitWait, err)
+			finish(nil, err)
+			recordOutcome(nil, errNotSent)
+			return nil, err
+		}
+		defer release()
+	}
+
 	// Make the request
+	httpResp, err := c.send(req, family)
+	finish(httpResp, err)
+	recordOutcome(httpResp, err)
+	if err != nil {
+		return nil, err
+	}
+
+	// Log response if debugging is enabled
+	if c.shouldLog() {
+		c.logResponse(httpResp)
+	}
+
+	return httpResp, nil
+}
+
+// send executes the request and reads the full response body
+func (c *Client) send(req *http.Request, family EndpointFamily) (*HTTPResponse, error) {
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if c.RateLimiter != nil {
+		c.RateLimiter.Observe(family, resp.StatusCode, resp.Header)
+	}
+
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	httpResp := &HTTPResponse{
+	return &HTTPResponse{
 		StatusCode: resp.StatusCode,
 		Body:       body,
 		Headers:    resp.Header,
-	}
-
-	// Log response if debugging is enabled
-	if c.shouldLog() {
-		c.logResponse(httpResp)
-	}
-
-	return httpResp, nil
+		Latency:    time.Since(start),
+	}, nil
 }
 
 // DoRequestWithJSON performs an HTTP request and unmarshals the response into the provided interface
 func (c *Client) DoRequestWithJSON(opts *RequestOptions, result interface{}) error {
+	_, err := c.doRequestWithJSON(opts, result)
+	return err
+}
+
+// doRequestWithJSON is DoRequestWithJSON that also returns the raw response when one was received
+func (c *Client) doRequestWithJSON(opts *RequestOptions, result interface{}) (*HTTPResponse, error) {
 	resp, err := c.DoRequest(opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
-		return c.handleErrorResponse(resp)
+		return resp, c.handleErrorResponse(resp)
 	}
 
 	// Unmarshal successful response
 	if result != nil {
 		if err := json.Unmarshal(resp.Body, result); err != nil {
-			return fmt.Errorf("failed to parse response: %w", err)
+			return resp, fmt.Errorf("failed to parse response: %w", err)
 		}
 	}
 
-	return nil
+	return resp, nil
 }
 
 // buildURL constructs the full URL with query parameters
@@ -155,6 +270,16 @@ func (c *Client) buildURL(endpoint string, queryParams map[string]string) (strin
 	return u.String(), nil
 }
 
+// setPageParams adds the standard page and page_size query parameters
+func setPageParams(queryParams map[string]string, page, pageSize *int) {
+	if page != nil {
+		queryParams["page"] = strconv.Itoa(*page)
+	}
+	if pageSize != nil {
+		queryParams["page_size"] = strconv.Itoa(*pageSize)
+	}
+}
+
 // prepareBody converts the body interface to bytes
 func (c *Client) prepareBody(body interface{}) ([]byte, error) {
 	switch v := body.(type) {
@@ -182,7 +307,7 @@ func (c *Client) setHeaders(req *http.Request, opts *RequestOptions) {
 
 	// Set authentication headers
 	if opts.UseToken && c.Token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+		req.Header.Set("Authorization", "Bearer "+c.Token)
 	}
 
 	if opts.UseAPIKey && c.PublishableKey != "" {
@@ -199,11 +324,18 @@ func (c *Client) setHeaders(req *http.Request, opts *RequestOptions) {
 
 // handleErrorResponse processes error responses and returns appropriate errors
 func (c *Client) handleErrorResponse(resp *HTTPResponse) error {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    string(resp.Body),
+		RequestID:  resp.RequestID(),
+		Body:       resp.Body,
+	}
 	var errorResp ErrorResponse
 	if err := json.Unmarshal(resp.Body, &errorResp); err == nil && errorResp.Message != "" {
-		return fmt.Errorf("API error (%d): %s", resp.StatusCode, errorResp.Message)
+		apiErr.Message = errorResp.Message
+		apiErr.Errors = errorResp.Errors
 	}
-	return fmt.Errorf("API error (%d): %s", resp.StatusCode, string(resp.Body))
+	return apiErr
 }
 
 // shouldLog determines if logging should be enabled
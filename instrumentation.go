@@ -0,0 +1,26 @@
+package intasend
+
+import (
+	"net/http"
+)
+
+// RequestInfo describes an outgoing API request for instrumentation
+type RequestInfo struct {
+	Family  EndpointFamily // endpoint family derived from the URL path
+	Method  string         // HTTP method
+	URL     string         // full request URL including query parameters
+	Attempt int            // 1-based attempt number; the client sends each request once
+	Body    []byte         // request body as sent, nil for requests without a body
+}
+
+// Instrumentation observes every request sent by DoRequest. It is the hook
+// used by tracing and metrics integrations such as the intasendotel package.
+type Instrumentation interface {
+	// StartRequest is called once a request is built, before the client's
+	// key check, circuit breaker and rate limiter. It may return a derived
+	// request (for example carrying trace headers or a new context) and must
+	// return a function that is called exactly once with the outcome. resp is
+	// nil when err is a transport error or the client refused to send the
+	// request, e.g. with ErrCircuitOpen or a *KeyMismatchError.
+	StartRequest(req *http.Request, info RequestInfo) (*http.Request, func(resp *HTTPResponse, err error))
+}
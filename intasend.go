@@ -10,13 +10,32 @@ import (
 // Client represents the IntaSend API client
 type Client struct {
 	PublishableKey string
-	Token          string
+	Token          string // bearer token; redacted when the client is printed
 	BaseURL        string
 	APIBaseURL     string
 
-	HTTPClient *http.Client
-	Test       bool
-	ShowLogs   bool
+	HTTPClient     *http.Client
+	Test           bool
+	ShowLogs       bool
+	RateLimiter    *RateLimiter    // optional, throttles requests per endpoint family
+	CircuitBreaker *CircuitBreaker // optional, fails fast per endpoint family during outages
+	StrictKeys     bool            // optional, fails requests whose keys belong to the other environment; see CheckKeys
+
+	Instrumentation Instrumentation // optional, observes every request (tracing, metrics)
+	Metrics         Metrics         // optional, receives business events (checkouts, payouts, webhooks)
+	Interceptors    []Interceptor   // optional, wraps every operation; see Use
+}
+
+// String describes the client without revealing its token, so %v and %+v
+// are safe to log whether the client is printed by pointer or by value
+func (c Client) String() string {
+	return fmt.Sprintf("intasend.Client{PublishableKey: %q, Token: %s, BaseURL: %q, APIBaseURL: %q, Test: %t}",
+		c.PublishableKey, Secret(c.Token), c.BaseURL, c.APIBaseURL, c.Test)
+}
+
+// GoString implements fmt.GoStringer with the same redaction as String
+func (c Client) GoString() string {
+	return c.String()
 }
 
 // PaymentRequest represents the payment checkout request payload
@@ -44,7 +63,19 @@ func NewClient(publishableKey, token string, test bool, showlogs bool) *Client {
 }
 
 // CreateCheckoutLink generates a secure checkout link for payment
-func (c *Client) CreateCheckoutLink(req *PaymentRequest) (*PaymentResponse, error) {
+func (c *Client) CreateCheckoutLink(req *PaymentRequest, opts ...CallOption) (*PaymentResponse, error) {
+	return invoke(c, &Invocation{Operation: OpCheckoutCreate, Request: req}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaymentResponse, error) {
+			req, err := requestAs[*PaymentRequest](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.createCheckoutLink(req, opts)
+		})
+}
+
+// createCheckoutLink applies defaults and posts the checkout request
+func (c *Client) createCheckoutLink(req *PaymentRequest, opts []CallOption) (*PaymentResponse, error) {
 	// Validate required fields
 	if c.PublishableKey == "" {
 		return nil, fmt.Errorf("publishable key is required")
@@ -54,6 +85,9 @@ func (c *Client) CreateCheckoutLink(req *PaymentRequest) (*PaymentResponse, erro
 	if req.Currency == "" {
 		req.Currency = CurrencyKES
 	}
+	if err := req.Currency.Validate(); err != nil {
+		return nil, err
+	}
 	if req.CardTarrif == "" {
 		req.CardTarrif = CUSTOMER_PAYS
 	}
@@ -63,16 +97,24 @@ func (c *Client) CreateCheckoutLink(req *PaymentRequest) (*PaymentResponse, erro
 
 	// Use the HTTP wrapper to make the request
 	var paymentResp PaymentResponse
-	err := c.PostJSON("api/v1/checkout/", req, false, true, &paymentResp)
+	err := c.call(&RequestOptions{
+		Method:    POST,
+		Endpoint:  "api/v1/checkout/",
+		Body:      req,
+		UseAPIKey: true,
+	}, &paymentResp, opts)
 	if err != nil {
 		return nil, err
 	}
+	if c.Metrics != nil {
+		c.Metrics.CheckoutCreated(req.Currency)
+	}
 
 	return &paymentResp, nil
 }
 
 // QuickCheckout creates a simple checkout link with minimal required fields
-func (c *Client) QuickCheckout(phoneNumber, email string, amount float64, currency CurrencyType, comment, redirectURL string) (*PaymentResponse, error) {
+func (c *Client) QuickCheckout(phoneNumber, email string, amount float64, currency CurrencyType, comment, redirectURL string, opts ...CallOption) (*PaymentResponse, error) {
 	req := &PaymentRequest{
 		PhoneNumber: phoneNumber,
 		Email:       email,
@@ -82,7 +124,7 @@ func (c *Client) QuickCheckout(phoneNumber, email string, amount float64, curren
 		RedirectURL: redirectURL,
 	}
 
-	return c.CreateCheckoutLink(req)
+	return c.CreateCheckoutLink(req, opts...)
 }
 
 // PaymentRequestBuilder provides a fluent interface for building payment requests
@@ -101,6 +143,12 @@ func NewPaymentRequest() *PaymentRequestBuilder {
 	}
 }
 
+// WithCustomerID references an existing customer record instead of resending their details
+func (b *PaymentRequestBuilder) WithCustomerID(customerID string) *PaymentRequestBuilder {
+	b.request.CustomerID = customerID
+	return b
+}
+
 // WithPhoneNumber sets the customer's phone number
 func (b *PaymentRequestBuilder) WithPhoneNumber(phoneNumber string) *PaymentRequestBuilder {
 	b.request.PhoneNumber = phoneNumber
@@ -196,7 +244,19 @@ func ValidateEmail(email string) bool {
 }
 
 // SendIntaSendXBPush initiates an IntaSend-XB STK push collection request
-func (c *Client) SendIntaSendXBPush(req *IntaSendXBPushRequest) (*IntaSendXBPushResponse, error) {
+func (c *Client) SendIntaSendXBPush(req *IntaSendXBPushRequest, opts ...CallOption) (*IntaSendXBPushResponse, error) {
+	return invoke(c, &Invocation{Operation: OpCollectionXBPush, Request: req}, opts,
+		func(inv *Invocation, opts []CallOption) (*IntaSendXBPushResponse, error) {
+			req, err := requestAs[*IntaSendXBPushRequest](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.sendIntaSendXBPush(req, opts)
+		})
+}
+
+// sendIntaSendXBPush validates and posts the XB push request
+func (c *Client) sendIntaSendXBPush(req *IntaSendXBPushRequest, opts []CallOption) (*IntaSendXBPushResponse, error) {
 	if c.Token == "" {
 		return nil, fmt.Errorf("token is required to initiate IntaSend-XB push")
 	}
@@ -206,8 +266,8 @@ func (c *Client) SendIntaSendXBPush(req *IntaSendXBPushRequest) (*IntaSendXBPush
 	if req.Amount == "" {
 		return nil, fmt.Errorf("amount is required")
 	}
-	if req.PhoneNumber == "" {
-		return nil, fmt.Errorf("phone number is required")
+	if req.PhoneNumber == "" && req.CustomerID == "" {
+		return nil, fmt.Errorf("phone number or customer ID is required")
 	}
 	if req.Currency == "" {
 		return nil, fmt.Errorf("currency is required")
@@ -221,14 +281,30 @@ func (c *Client) SendIntaSendXBPush(req *IntaSendXBPushRequest) (*IntaSendXBPush
 
 	endpoint := fmt.Sprintf("%s/api/v1/payment/intasend-xb-push/", c.APIBaseURL)
 	var resp IntaSendXBPushResponse
-	if err := c.PostJSON(endpoint, req, true, false, &resp); err != nil {
+	if err := c.call(&RequestOptions{
+		Method:   POST,
+		Endpoint: endpoint,
+		Body:     req,
+		UseToken: true,
+	}, &resp, opts); err != nil {
 		return nil, err
 	}
+	if c.Metrics != nil {
+		c.Metrics.STKPushSent(req.Currency)
+	}
 	return &resp, nil
 }
 
 // GetPaymentStatus retrieves the payment status using invoice ID
-func (c *Client) GetPaymentStatus(invoiceID string) (*PaymentStatus, error) {
+func (c *Client) GetPaymentStatus(invoiceID string, opts ...CallOption) (*PaymentStatus, error) {
+	return invoke(c, &Invocation{Operation: OpPaymentStatus, ResourceID: invoiceID}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaymentStatus, error) {
+			return c.getPaymentStatus(inv.ResourceID, opts)
+		})
+}
+
+// getPaymentStatus posts the status lookup for an invoice
+func (c *Client) getPaymentStatus(invoiceID string, opts []CallOption) (*PaymentStatus, error) {
 	// Validate required fields
 	if c.PublishableKey == "" {
 		return nil, fmt.Errorf("publishable key is required")
@@ -245,7 +321,13 @@ func (c *Client) GetPaymentStatus(invoiceID string) (*PaymentStatus, error) {
 
 	// Use the HTTP wrapper to make the request
 	var statusResp PaymentStatus
-	err := c.PostJSON("api/v1/payment/status/", payload, true, true, &statusResp)
+	err := c.call(&RequestOptions{
+		Method:    POST,
+		Endpoint:  "api/v1/payment/status/",
+		Body:      payload,
+		UseToken:  true,
+		UseAPIKey: true,
+	}, &statusResp, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -254,6 +336,6 @@ func (c *Client) GetPaymentStatus(invoiceID string) (*PaymentStatus, error) {
 }
 
 // CheckPaymentStatus is an alias for GetPaymentStatus for consistency with Python SDK
-func (c *Client) CheckPaymentStatus(invoiceID string) (*PaymentStatus, error) {
-	return c.GetPaymentStatus(invoiceID)
+func (c *Client) CheckPaymentStatus(invoiceID string, opts ...CallOption) (*PaymentStatus, error) {
+	return c.GetPaymentStatus(invoiceID, opts...)
 }
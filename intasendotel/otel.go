@@ -0,0 +1,264 @@
+// Package intasendotel instruments the IntaSend client with OpenTelemetry.
+//
+// Install it on a client to get a span per API call plus request count,
+// latency and error metrics, all labelled by endpoint family:
+//
+//	inst, err := intasendotel.New()
+//	if err != nil { ... }
+//	client.Instrumentation = inst
+//
+// Spans are children of the span in the context passed with
+// intasend.WithContext, and the trace context is propagated to IntaSend
+// through the configured propagator.
+package intasendotel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/techliana/intasend-sdk-golang"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope used for the tracer and meter
+const ScopeName = "github.com/techliana/intasend-sdk-golang/intasendotel"
+
+// Attribute keys set on spans and metrics
+const (
+	AttrEndpointFamily = attribute.Key("intasend.endpoint_family")
+	AttrAttempt        = attribute.Key("intasend.attempt")
+	AttrInvoiceID      = attribute.Key("intasend.invoice_id")
+	AttrTrackingID     = attribute.Key("intasend.tracking_id")
+	AttrAmountBucket   = attribute.Key("intasend.amount_bucket")
+	AttrMethod         = attribute.Key("http.request.method")
+	AttrStatusCode     = attribute.Key("http.response.status_code")
+	AttrErrorType      = attribute.Key("error.type")
+)
+
+// Option configures the instrumentation
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the tracer provider (defaults to the global provider)
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider (defaults to the global provider)
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagator used to inject trace context into
+// outgoing requests (defaults to the global propagator)
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// Instrumentation implements intasend.Instrumentation with OpenTelemetry
+type Instrumentation struct {
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// Ensure Instrumentation satisfies the client hook
+var _ intasend.Instrumentation = (*Instrumentation)(nil)
+
+// New creates OpenTelemetry instrumentation for an intasend.Client
+func New(opts ...Option) (*Instrumentation, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+	requests, err := meter.Int64Counter("intasend.client.requests",
+		metric.WithDescription("Number of IntaSend API requests"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request counter: %w", err)
+	}
+	errorCount, err := meter.Int64Counter("intasend.client.errors",
+		metric.WithDescription("Number of failed IntaSend API requests"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create error counter: %w", err)
+	}
+	duration, err := meter.Float64Histogram("intasend.client.request.duration",
+		metric.WithDescription("Latency of IntaSend API requests"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create latency histogram: %w", err)
+	}
+
+	return &Instrumentation{
+		tracer:      cfg.tracerProvider.Tracer(ScopeName),
+		propagators: cfg.propagators,
+		requests:    requests,
+		errors:      errorCount,
+		duration:    duration,
+	}, nil
+}
+
+// StartRequest implements intasend.Instrumentation
+func (i *Instrumentation) StartRequest(req *http.Request, info intasend.RequestInfo) (*http.Request, func(*intasend.HTTPResponse, error)) {
+	family := AttrEndpointFamily.String(string(info.Family))
+	attrs := []attribute.KeyValue{
+		family,
+		AttrMethod.String(info.Method),
+		AttrAttempt.Int(info.Attempt),
+	}
+	attrs = append(attrs, payloadAttributes(info.Body)...)
+
+	ctx, span := i.tracer.Start(req.Context(), "intasend."+string(info.Family),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	req = req.WithContext(ctx)
+	i.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return req, func(resp *intasend.HTTPResponse, err error) {
+		defer span.End()
+
+		metricAttrs := []attribute.KeyValue{family, AttrMethod.String(info.Method)}
+		errorType := ""
+		switch {
+		case err != nil:
+			errorType = errorTypeOf(err)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case resp.StatusCode >= 400:
+			errorType = strconv.Itoa(resp.StatusCode)
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
+
+		if resp != nil {
+			status := AttrStatusCode.Int(resp.StatusCode)
+			span.SetAttributes(status)
+			span.SetAttributes(payloadAttributes(resp.Body)...)
+			metricAttrs = append(metricAttrs, status)
+			i.duration.Record(ctx, resp.Latency.Seconds(), metric.WithAttributes(metricAttrs...))
+		}
+
+		i.requests.Add(ctx, 1, metric.WithAttributes(metricAttrs...))
+		if errorType != "" {
+			span.SetAttributes(AttrErrorType.String(errorType))
+			i.errors.Add(ctx, 1, metric.WithAttributes(append(metricAttrs, AttrErrorType.String(errorType))...))
+		}
+	}
+}
+
+// errorTypeOf labels an error returned without a response: one the client
+// raised before sending, or a transport failure
+func errorTypeOf(err error) string {
+	var mismatch *intasend.KeyMismatchError
+	switch {
+	case errors.As(err, &mismatch):
+		return "key_mismatch"
+	case errors.Is(err, intasend.ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, intasend.ErrRateLimitWait):
+		return "rate_limited"
+	}
+	return "transport"
+}
+
+// MaxPayloadSize is the largest request or response body inspected for
+// span attributes. Single resources are well under it; list responses are
+// skipped rather than decoded.
+const MaxPayloadSize = 16 << 10
+
+// payloadAttributes extracts invoice/tracking IDs and an amount bucket from a JSON body
+func payloadAttributes(body []byte) []attribute.KeyValue {
+	if len(body) == 0 || len(body) > MaxPayloadSize {
+		return nil
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil
+	}
+
+	var attrs []attribute.KeyValue
+	invoiceID := stringField(payload, "invoice_id")
+	if invoiceID == "" {
+		if inv, ok := payload["invoice"].(map[string]interface{}); ok {
+			invoiceID = stringField(inv, "invoice_id")
+		}
+	}
+	if invoiceID != "" {
+		attrs = append(attrs, AttrInvoiceID.String(invoiceID))
+	}
+	if trackingID := stringField(payload, "tracking_id"); trackingID != "" {
+		attrs = append(attrs, AttrTrackingID.String(trackingID))
+	}
+
+	for _, key := range []string{"amount", "total_amount", "value"} {
+		if amount, ok := numberField(payload, key); ok {
+			attrs = append(attrs, AttrAmountBucket.String(AmountBucket(amount)))
+			break
+		}
+	}
+	return attrs
+}
+
+// AmountBucket maps an amount to a low-cardinality label
+func AmountBucket(amount float64) string {
+	switch {
+	case amount < 100:
+		return "<100"
+	case amount < 1000:
+		return "100-1k"
+	case amount < 10000:
+		return "1k-10k"
+	case amount < 100000:
+		return "10k-100k"
+	default:
+		return ">=100k"
+	}
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	if s, ok := m[key].(string); ok {
+		return s
+	}
+	return ""
+}
+
+func numberField(m map[string]interface{}, key string) (float64, bool) {
+	switch v := m[key].(type) {
+	case float64:
+		return v, v > 0
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil && f > 0
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,158 @@
+package intasendotel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/techliana/intasend-sdk-golang"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrumentation(t *testing.T) {
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		if r.URL.Path == "/api/v1/payment/status/" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"CHK1","url":"https://example.com","amount":2500}`))
+	}))
+	defer srv.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	inst, err := New(WithTracerProvider(tp), WithMeterProvider(mp), WithPropagators(propagation.TraceContext{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := intasend.NewClient("pk", "token", true, false)
+	client.BaseURL = srv.URL
+	client.Instrumentation = inst
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "checkout-flow")
+	if _, err := client.CreateCheckoutLink(&intasend.PaymentRequest{Amount: 2500}, intasend.WithContext(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetPaymentStatus("INV404", intasend.WithContext(ctx)); err == nil {
+		t.Fatal("Expected status lookup to fail")
+	}
+	parent.End()
+
+	if traceparent == "" {
+		t.Error("Expected trace context to be propagated")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(ended))
+	}
+	checkout, status := ended[0], ended[1]
+	if checkout.Name() != "intasend.checkout" || checkout.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Unexpected checkout span %s with parent %s", checkout.Name(), checkout.Parent().SpanID())
+	}
+	if !hasAttr(checkout.Attributes(), AttrAmountBucket.String("1k-10k")) {
+		t.Errorf("Expected amount bucket attribute, got %v", checkout.Attributes())
+	}
+	if status.Status().Code != codes.Error || !hasAttr(status.Attributes(), AttrInvoiceID.String("INV404")) {
+		t.Errorf("Expected errored status span with invoice ID, got %v %v", status.Status(), status.Attributes())
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	totals := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range sum.DataPoints {
+					totals[m.Name] += dp.Value
+				}
+			}
+		}
+	}
+	if totals["intasend.client.requests"] != 2 || totals["intasend.client.errors"] != 1 {
+		t.Errorf("Unexpected metric totals: %v", totals)
+	}
+}
+
+func TestInstrumentationRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[]}`))
+	}))
+	defer srv.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	inst, err := New(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A live token on a sandbox client never reaches the server
+	mismatched := intasend.NewClient("pk", intasend.SecretKeyLivePrefix+"abc", true, false)
+	mismatched.BaseURL = srv.URL
+	mismatched.Instrumentation = inst
+	mismatched.StrictKeys = true
+	if _, err := mismatched.ListWallets(nil); err == nil {
+		t.Fatal("Expected a key mismatch")
+	}
+
+	// The second request gives up waiting for a token
+	limited := intasend.NewClient("pk", "token", true, false)
+	limited.BaseURL = srv.URL
+	limited.Instrumentation = inst
+	limited.RateLimiter = intasend.NewRateLimiter(intasend.RateLimiterConfig{Default: intasend.RateLimit{Rate: 0.001, Burst: 1}})
+	if _, err := limited.ListWallets(nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limited.ListWallets(nil, intasend.WithContext(ctx)); !errors.Is(err, intasend.ErrRateLimitWait) {
+		t.Fatalf("Expected a rate limiter error, got %v", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("Expected a span per request, got %d", len(ended))
+	}
+	for i, want := range map[int]string{0: "key_mismatch", 2: "rate_limited"} {
+		if ended[i].Status().Code != codes.Error || !hasAttr(ended[i].Attributes(), AttrErrorType.String(want)) {
+			t.Errorf("Expected span %d to fail with %s, got %v", i, want, ended[i].Attributes())
+		}
+	}
+}
+
+func TestPayloadAttributesSize(t *testing.T) {
+	body := []byte(`{"invoice_id":"INV1","value":250,"narrative":"` + strings.Repeat("x", MaxPayloadSize) + `"}`)
+	if attrs := payloadAttributes(body); attrs != nil {
+		t.Errorf("Expected bodies over MaxPayloadSize to be skipped, got %v", attrs)
+	}
+	if attrs := payloadAttributes([]byte(`{"invoice_id":"INV1","value":250}`)); !hasAttr(attrs, AttrInvoiceID.String("INV1")) {
+		t.Errorf("Expected small bodies to be decoded, got %v", attrs)
+	}
+}
+
+func hasAttr(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, a := range attrs {
+		if a == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Package intasendprom exposes IntaSend business metrics to Prometheus.
+//
+//	collector := intasendprom.NewCollector("myapp")
+//	prometheus.MustRegister(collector)
+//	client.Metrics = collector
+//
+// Platforms serving many merchants can label every series by tenant:
+//
+//	collector := intasendprom.NewTenantCollector("myapp")
+//	pool, err := intasend.NewClientPool(intasend.ClientPoolConfig{
+//		Credentials: provider,
+//		Metrics:     func(tenant string) intasend.Metrics { return collector.ForTenant(tenant) },
+//	})
+package intasendprom
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// Collector implements both intasend.Metrics and prometheus.Collector
+type Collector struct {
+	checkouts *prometheus.CounterVec
+	pushes    *prometheus.CounterVec
+	outcomes  *prometheus.CounterVec
+	payouts   *prometheus.CounterVec
+	webhooks  *prometheus.CounterVec
+
+	tenantLabel bool   // series carry a leading "tenant" label
+	tenant      string // value of the tenant label for this view
+}
+
+// Ensure Collector satisfies both interfaces
+var (
+	_ intasend.Metrics     = (*Collector)(nil)
+	_ prometheus.Collector = (*Collector)(nil)
+)
+
+// NewCollector creates a collector whose metric names are prefixed with namespace
+func NewCollector(namespace string) *Collector {
+	return newCollector(namespace, false)
+}
+
+// NewTenantCollector creates a collector whose series carry a "tenant" label.
+// Register the returned collector once and hand ForTenant views to each
+// tenant's client.
+func NewTenantCollector(namespace string) *Collector {
+	return newCollector(namespace, true)
+}
+
+func newCollector(namespace string, tenantLabel bool) *Collector {
+	counter := func(name, help string, labels ...string) *prometheus.CounterVec {
+		if tenantLabel {
+			labels = append([]string{"tenant"}, labels...)
+		}
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "intasend",
+			Name:      name,
+			Help:      help,
+		}, labels)
+	}
+	return &Collector{
+		tenantLabel: tenantLabel,
+		tenant:      "UNKNOWN",
+		checkouts:   counter("checkouts_created_total", "Checkout links created.", "currency"),
+		pushes:      counter("stk_pushes_sent_total", "STK push collection requests sent.", "currency"),
+		outcomes:    counter("payment_outcomes_total", "Payment outcomes reported by webhooks.", "state", "provider"),
+		payouts:     counter("payout_batches_total", "Send-money batches submitted, by returned status.", "status"),
+		webhooks:    counter("webhook_deliveries_total", "Collection webhooks received.", "result", "reason"),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, v := range c.vectors() {
+		v.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, v := range c.vectors() {
+		v.Collect(ch)
+	}
+}
+
+// ForTenant returns a view of the collector that records under the given
+// tenant label. It shares the underlying series, so only the collector
+// returned by NewTenantCollector needs registering. On a collector created
+// with NewCollector it returns c unchanged.
+func (c *Collector) ForTenant(tenant string) *Collector {
+	if !c.tenantLabel {
+		return c
+	}
+	view := *c
+	view.tenant = tenant
+	if view.tenant == "" {
+		view.tenant = "UNKNOWN"
+	}
+	return &view
+}
+
+// values prepends the tenant label value when the collector has one
+func (c *Collector) values(v ...string) []string {
+	if c.tenantLabel {
+		return append([]string{c.tenant}, v...)
+	}
+	return v
+}
+
+func (c *Collector) vectors() []*prometheus.CounterVec {
+	return []*prometheus.CounterVec{c.checkouts, c.pushes, c.outcomes, c.payouts, c.webhooks}
+}
+
+// CheckoutCreated implements intasend.Metrics
+func (c *Collector) CheckoutCreated(currency intasend.CurrencyType) {
+	c.checkouts.WithLabelValues(c.values(label(string(currency)))...).Inc()
+}
+
+// STKPushSent implements intasend.Metrics
+func (c *Collector) STKPushSent(currency intasend.CurrencyType) {
+	c.pushes.WithLabelValues(c.values(label(string(currency)))...).Inc()
+}
+
+// PaymentOutcome implements intasend.Metrics
+func (c *Collector) PaymentOutcome(state, provider string) {
+	c.outcomes.WithLabelValues(c.values(label(state), label(provider))...).Inc()
+}
+
+// PayoutBatch implements intasend.Metrics
+func (c *Collector) PayoutBatch(status string) {
+	c.payouts.WithLabelValues(c.values(label(status))...).Inc()
+}
+
+// WebhookDelivery implements intasend.Metrics
+func (c *Collector) WebhookDelivery(accepted bool, reason string) {
+	result := "rejected"
+	if accepted {
+		result = "accepted"
+	}
+	c.webhooks.WithLabelValues(c.values(result, reason)...).Inc()
+}
+
+// label normalises free-form API values so they make stable label values
+func label(v string) string {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if v == "" {
+		return "UNKNOWN"
+	}
+	return v
+}
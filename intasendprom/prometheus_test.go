@@ -0,0 +1,87 @@
+package intasendprom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/techliana/intasend-sdk-golang"
+	"github.com/techliana/intasend-sdk-golang/intasendtest"
+)
+
+func TestCollector(t *testing.T) {
+	srv := intasendtest.NewServer()
+	defer srv.Close()
+
+	collector := NewCollector("test")
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	client := srv.Client()
+	client.Metrics = collector
+
+	if _, err := client.QuickCheckout("", "a@example.com", 100, intasend.CurrencyKES, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SendIntaSendXBPush(&intasend.IntaSendXBPushRequest{
+		Amount: "500", PhoneNumber: "255700000000", Currency: intasend.CurrencyTZS,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.InitiateSendMoney(&intasend.SendMoneyRequest{
+		Currency:     intasend.CurrencyKES,
+		Provider:     intasend.ProviderMPESAB2C,
+		Transactions: []intasend.SendMoneyTransaction{{Account: "254700000000", Amount: "10"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ParseCollectionCallback([]byte(`{"state":"COMPLETE","provider":"M-PESA","challenge":"ok"}`), "ok"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ParseCollectionCallback([]byte(`{"state":"COMPLETE","challenge":"forged"}`), "ok"); err != intasend.ErrInvalidChallenge {
+		t.Fatalf("Expected ErrInvalidChallenge, got %v", err)
+	}
+
+	expected := `
+# HELP test_intasend_checkouts_created_total Checkout links created.
+# TYPE test_intasend_checkouts_created_total counter
+test_intasend_checkouts_created_total{currency="KES"} 1
+# HELP test_intasend_payment_outcomes_total Payment outcomes reported by webhooks.
+# TYPE test_intasend_payment_outcomes_total counter
+test_intasend_payment_outcomes_total{provider="M-PESA",state="COMPLETE"} 1
+# HELP test_intasend_payout_batches_total Send-money batches submitted, by returned status.
+# TYPE test_intasend_payout_batches_total counter
+test_intasend_payout_batches_total{status="PENDING"} 1
+# HELP test_intasend_stk_pushes_sent_total STK push collection requests sent.
+# TYPE test_intasend_stk_pushes_sent_total counter
+test_intasend_stk_pushes_sent_total{currency="TZS"} 1
+# HELP test_intasend_webhook_deliveries_total Collection webhooks received.
+# TYPE test_intasend_webhook_deliveries_total counter
+test_intasend_webhook_deliveries_total{reason="",result="accepted"} 1
+test_intasend_webhook_deliveries_total{reason="challenge_mismatch",result="rejected"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTenantCollector(t *testing.T) {
+	collector := NewTenantCollector("test")
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	collector.ForTenant("acme").CheckoutCreated(intasend.CurrencyKES)
+	collector.ForTenant("acme").CheckoutCreated(intasend.CurrencyKES)
+	collector.ForTenant("globex").CheckoutCreated(intasend.CurrencyKES)
+
+	expected := `
+# HELP test_intasend_checkouts_created_total Checkout links created.
+# TYPE test_intasend_checkouts_created_total counter
+test_intasend_checkouts_created_total{currency="KES",tenant="acme"} 2
+test_intasend_checkouts_created_total{currency="KES",tenant="globex"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "test_intasend_checkouts_created_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,191 @@
+package intasendrecon
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// AnomalyKind classifies a ledger anomaly
+type AnomalyKind string
+
+const (
+	// BalanceGap means a running balance moved by a different amount than its
+	// transaction's value, usually because a transaction is missing
+	BalanceGap AnomalyKind = "BALANCE_GAP"
+	// DuplicateTransaction means a transaction ID appears more than once
+	DuplicateTransaction AnomalyKind = "DUPLICATE_TRANSACTION"
+	// FinalBalanceMismatch means the last running balance differs from the
+	// wallet's current balance
+	FinalBalanceMismatch AnomalyKind = "FINAL_BALANCE_MISMATCH"
+)
+
+// BalancePoint is the wallet balance after one transaction
+type BalancePoint struct {
+	TransactionID  string    `json:"transaction_id"`
+	Time           time.Time `json:"time"`
+	Value          float64   `json:"value"`
+	RunningBalance float64   `json:"running_balance"`
+}
+
+// Anomaly is one inconsistency found in a wallet ledger
+type Anomaly struct {
+	Kind          AnomalyKind `json:"kind"`
+	TransactionID string      `json:"transaction_id,omitempty"`
+	Time          time.Time   `json:"time,omitempty"`
+	Expected      float64     `json:"expected"`
+	Actual        float64     `json:"actual"`
+	Detail        string      `json:"detail"`
+}
+
+// LedgerReport is the outcome of verifying a wallet's running balances
+type LedgerReport struct {
+	Wallet         intasend.WalletResp `json:"wallet"`
+	OpeningBalance float64             `json:"opening_balance"` // balance before the oldest transaction
+	History        []BalancePoint      `json:"history"`         // completed transactions, oldest first
+	Skipped        int                 `json:"skipped"`         // pending or failed transactions, which do not move the balance
+	Anomalies      []Anomaly           `json:"anomalies"`
+}
+
+// OK reports whether the ledger is consistent
+func (r *LedgerReport) OK() bool {
+	return len(r.Anomalies) == 0
+}
+
+// BalanceAt returns the running balance after the last transaction at or
+// before t, or the opening balance if t precedes the history
+func (r *LedgerReport) BalanceAt(t time.Time) float64 {
+	i := sort.Search(len(r.History), func(i int) bool { return r.History[i].Time.After(t) })
+	if i == 0 {
+		return r.OpeningBalance
+	}
+	return r.History[i-1].RunningBalance
+}
+
+// VerifyWallet rebuilds the balance history of a wallet from its statement
+// and checks that every running balance equals the previous one plus the
+// transaction value, and that the last one equals the wallet's current
+// balance. Differences up to tolerance are ignored; zero uses
+// DefaultAmountTolerance.
+func VerifyWallet(ctx context.Context, client intasend.Wallets, walletID string, tolerance float64) (*LedgerReport, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+	if walletID == "" {
+		return nil, fmt.Errorf("wallet ID is required")
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultAmountTolerance
+	}
+
+	wallet, err := findWallet(ctx, client, walletID)
+	if err != nil {
+		return nil, err
+	}
+	var txs []intasend.Result
+	for page := 1; ; page++ {
+		resp, err := client.ListWalletTransactions(walletID, &intasend.WalletTransactionsParams{Page: &page}, intasend.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("listing transactions of wallet %s: %w", walletID, err)
+		}
+		txs = append(txs, resp.Results...)
+		if !intasend.HasNextPage(resp.Next) || len(resp.Results) == 0 {
+			break
+		}
+	}
+
+	// Statements may come newest first; keep the API order within equal timestamps
+	if n := len(txs); n > 1 && txs[0].CreatedAt.After(txs[n-1].CreatedAt) {
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			txs[i], txs[j] = txs[j], txs[i]
+		}
+	}
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].CreatedAt.Before(txs[j].CreatedAt) })
+
+	report := &LedgerReport{Wallet: *wallet, OpeningBalance: wallet.CurrentBalance}
+	seen := make(map[string]bool)
+	for _, tx := range txs {
+		if tx.Status != "" && !strings.EqualFold(tx.Status, intasend.TransStatusCompleted) {
+			report.Skipped++
+			continue
+		}
+		if tx.TransactionID != "" && seen[tx.TransactionID] {
+			report.Anomalies = append(report.Anomalies, Anomaly{
+				Kind:          DuplicateTransaction,
+				TransactionID: tx.TransactionID,
+				Time:          tx.CreatedAt,
+				Actual:        tx.Value,
+				Detail:        "transaction listed more than once",
+			})
+			continue
+		}
+		seen[tx.TransactionID] = true
+
+		if len(report.History) == 0 {
+			report.OpeningBalance = round2(tx.RunningBalance - tx.Value)
+		} else {
+			prev := report.History[len(report.History)-1]
+			expected := round2(prev.RunningBalance + tx.Value)
+			if math.Abs(expected-tx.RunningBalance) > tolerance {
+				report.Anomalies = append(report.Anomalies, Anomaly{
+					Kind:          BalanceGap,
+					TransactionID: tx.TransactionID,
+					Time:          tx.CreatedAt,
+					Expected:      expected,
+					Actual:        tx.RunningBalance,
+					Detail: fmt.Sprintf("balance after %s is %.2f, expected %.2f + %.2f = %.2f; %.2f unaccounted for since %s",
+						tx.TransactionID, tx.RunningBalance, prev.RunningBalance, tx.Value, expected, round2(tx.RunningBalance-expected), prev.TransactionID),
+				})
+			}
+		}
+		report.History = append(report.History, BalancePoint{
+			TransactionID:  tx.TransactionID,
+			Time:           tx.CreatedAt,
+			Value:          tx.Value,
+			RunningBalance: tx.RunningBalance,
+		})
+	}
+
+	if n := len(report.History); n > 0 {
+		last := report.History[n-1]
+		if math.Abs(last.RunningBalance-wallet.CurrentBalance) > tolerance {
+			report.Anomalies = append(report.Anomalies, Anomaly{
+				Kind:          FinalBalanceMismatch,
+				TransactionID: last.TransactionID,
+				Time:          last.Time,
+				Expected:      wallet.CurrentBalance,
+				Actual:        last.RunningBalance,
+				Detail: fmt.Sprintf("last running balance %.2f differs from the current balance %.2f by %.2f",
+					last.RunningBalance, wallet.CurrentBalance, round2(wallet.CurrentBalance-last.RunningBalance)),
+			})
+		}
+	}
+	return report, nil
+}
+
+// findWallet pages through the wallets until it finds walletID
+func findWallet(ctx context.Context, client intasend.Wallets, walletID string) (*intasend.WalletResp, error) {
+	for page := 1; ; page++ {
+		resp, err := client.ListWallets(&intasend.ListWalletsParams{Page: &page}, intasend.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("listing wallets: %w", err)
+		}
+		for i := range resp.Results {
+			if resp.Results[i].WalletID == walletID {
+				return &resp.Results[i], nil
+			}
+		}
+		if !intasend.HasNextPage(resp.Next) || len(resp.Results) == 0 {
+			return nil, fmt.Errorf("wallet %s not found", walletID)
+		}
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,101 @@
+package intasendrecon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+	"github.com/techliana/intasend-sdk-golang/intasendtest"
+)
+
+func TestVerifyWallet(t *testing.T) {
+	srv := intasendtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	for _, amount := range []float64{1000, 250} {
+		resp, err := client.CreateCheckoutLink(&intasend.PaymentRequest{Email: "customer@example.com", Amount: amount})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.Complete(resp.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := VerifyWallet(context.Background(), client, "WKES001", 0)
+	if err != nil {
+		t.Fatalf("VerifyWallet failed: %v", err)
+	}
+	if !report.OK() || len(report.History) != 2 || report.OpeningBalance != 0 {
+		t.Errorf("Expected a clean ledger of 2 transactions, got %+v", report)
+	}
+	if last := report.History[1]; last.RunningBalance != report.Wallet.CurrentBalance {
+		t.Errorf("Expected the history to end at the current balance, got %+v", last)
+	}
+
+	if _, err := VerifyWallet(context.Background(), client, "WNONE", 0); err == nil {
+		t.Error("Expected an unknown wallet to fail")
+	}
+}
+
+// stubLedger serves one wallet and a fixed statement, newest first
+type stubLedger struct {
+	wallet intasend.WalletResp
+	txs    []intasend.Result
+}
+
+func (s *stubLedger) ListWallets(params *intasend.ListWalletsParams, opts ...intasend.CallOption) (*intasend.PaginatedWallets, error) {
+	return &intasend.PaginatedWallets{Count: 1, Results: []intasend.WalletResp{s.wallet}}, nil
+}
+
+func (s *stubLedger) ListWalletTransactions(walletID string, params *intasend.WalletTransactionsParams, opts ...intasend.CallOption) (*intasend.TransactionResp, error) {
+	return &intasend.TransactionResp{Count: int64(len(s.txs)), Results: s.txs}, nil
+}
+
+func TestVerifyWalletAnomalies(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tx := func(id string, hour int, value, balance float64, status string) intasend.Result {
+		return intasend.Result{TransactionID: id, CreatedAt: day.Add(time.Duration(hour) * time.Hour), Value: value, RunningBalance: balance, Status: status}
+	}
+	stub := &stubLedger{
+		wallet: intasend.WalletResp{WalletID: "W1", CurrentBalance: 900},
+		txs: []intasend.Result{
+			tx("T5", 5, -100, 750, intasend.TransStatusCompleted),
+			tx("T4", 4, 50, 850, intasend.TransStatusPending),
+			tx("T3", 3, 300, 850, intasend.TransStatusCompleted),
+			tx("T2", 2, 200, 700, intasend.TransStatusCompleted),
+			tx("T2", 2, 200, 700, intasend.TransStatusCompleted),
+			tx("T1", 1, 500, 500, intasend.TransStatusCompleted),
+		},
+	}
+
+	report, err := VerifyWallet(context.Background(), stub, "W1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Skipped != 1 || len(report.History) != 4 {
+		t.Fatalf("Expected 4 entries and 1 skipped, got %+v", report)
+	}
+	kinds := []AnomalyKind{DuplicateTransaction, BalanceGap, FinalBalanceMismatch}
+	if len(report.Anomalies) != len(kinds) {
+		t.Fatalf("Expected %d anomalies, got %+v", len(kinds), report.Anomalies)
+	}
+	for i, kind := range kinds {
+		if report.Anomalies[i].Kind != kind {
+			t.Errorf("Anomaly %d: got %s, want %s", i, report.Anomalies[i].Kind, kind)
+		}
+	}
+	gap := report.Anomalies[1]
+	want := "balance after T3 is 850.00, expected 700.00 + 300.00 = 1000.00; -150.00 unaccounted for since T2"
+	if gap.TransactionID != "T3" || gap.Detail != want {
+		t.Errorf("Unexpected gap:\n got %s\nwant %s", gap.Detail, want)
+	}
+	if got := report.BalanceAt(day.Add(150 * time.Minute)); got != 700 {
+		t.Errorf("Expected a 700 balance at 02:30, got %v", got)
+	}
+	if got := report.BalanceAt(day); got != 0 {
+		t.Errorf("Expected the opening balance before the first transaction, got %v", got)
+	}
+}
@@ -0,0 +1,352 @@
+// Package intasendrecon reconciles a merchant's own order records against
+// the invoices and transactions IntaSend holds for the same period.
+//
+//	r, err := intasendrecon.New(intasendrecon.Config{Client: client})
+//	if err != nil { ... }
+//	report, err := r.Reconcile(ctx, from, to, orders)
+//	for _, item := range report.Items {
+//		if item.Status != intasendrecon.Matched { ... }
+//	}
+//
+// Records are matched to invoices by invoice ID first, then by API reference,
+// and finally by amount within a time window. Each pair, and each record or
+// invoice left unpaired, becomes one report item.
+//
+// VerifyWallet checks a wallet statement on its own: every running balance
+// must follow from the one before it, and the last must equal the wallet's
+// current balance.
+package intasendrecon
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// Status classifies a report item
+type Status string
+
+const (
+	Matched        Status = "MATCHED"
+	AmountMismatch Status = "AMOUNT_MISMATCH"
+	StateMismatch  Status = "STATE_MISMATCH"
+	MissingLocal   Status = "MISSING_LOCAL"  // on IntaSend but not in the caller's records
+	MissingRemote  Status = "MISSING_REMOTE" // in the caller's records but not on IntaSend
+)
+
+// MatchKind says how a record was paired with an invoice
+type MatchKind string
+
+const (
+	ByInvoiceID  MatchKind = "invoice_id"
+	ByAPIRef     MatchKind = "api_ref"
+	ByAmountTime MatchKind = "amount_time"
+)
+
+// Defaults used by New when the config leaves them unset
+const (
+	DefaultAmountTolerance = 0.01
+	DefaultTimeWindow      = 15 * time.Minute
+	DefaultPageSize        = 100
+)
+
+// Record is one of the caller's orders. Fields left empty are not used for
+// matching or comparison.
+type Record struct {
+	ID        string    `json:"id"` // the caller's own identifier, echoed in the report
+	InvoiceID string    `json:"invoice_id,omitempty"`
+	APIRef    string    `json:"api_ref,omitempty"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency,omitempty"`
+	State     string    `json:"state,omitempty"` // expected invoice state, e.g. intasend.StatusComplete
+	Time      time.Time `json:"time"`            // when the order was paid or created
+}
+
+// Item is one line of the report
+type Item struct {
+	Status       Status                `json:"status"`
+	MatchedBy    MatchKind             `json:"matched_by,omitempty"`
+	Record       *Record               `json:"record,omitempty"`
+	Invoice      *intasend.InvoiceItem `json:"invoice,omitempty"`
+	Transactions []intasend.Result     `json:"transactions,omitempty"` // wallet transactions for the invoice
+	Detail       string                `json:"detail,omitempty"`
+}
+
+// Report is the outcome of a reconciliation
+type Report struct {
+	From   time.Time      `json:"from"`
+	To     time.Time      `json:"to"`
+	Items  []Item         `json:"items"`
+	Counts map[Status]int `json:"counts"`
+}
+
+// Filter returns the items with the given status
+func (r *Report) Filter(status Status) []Item {
+	var items []Item
+	for _, item := range r.Items {
+		if item.Status == status {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Clean reports whether every item matched
+func (r *Report) Clean() bool {
+	return r.Counts[Matched] == len(r.Items)
+}
+
+// Client is the part of the IntaSend API the reconciler reads
+type Client interface {
+	intasend.Invoices
+	intasend.Transactions
+}
+
+// Config configures a Reconciler
+type Config struct {
+	Client Client // required
+
+	AmountTolerance float64       // largest amount difference still treated as equal, defaults to DefaultAmountTolerance
+	TimeWindow      time.Duration // how far apart an order and invoice may be when matched by amount, defaults to DefaultTimeWindow
+	PageSize        int           // records fetched per request, defaults to DefaultPageSize
+}
+
+// Reconciler matches records against IntaSend
+type Reconciler struct {
+	cfg Config
+}
+
+// New creates a reconciler from the given configuration
+func New(cfg Config) (*Reconciler, error) {
+	if cfg.Client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+	if cfg.AmountTolerance <= 0 {
+		cfg.AmountTolerance = DefaultAmountTolerance
+	}
+	if cfg.TimeWindow <= 0 {
+		cfg.TimeWindow = DefaultTimeWindow
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = DefaultPageSize
+	}
+	return &Reconciler{cfg: cfg}, nil
+}
+
+// remote is an IntaSend invoice with its transactions
+type remote struct {
+	invoice intasend.InvoiceItem
+	txs     []intasend.Result
+	used    bool
+}
+
+// Reconcile compares records with the invoices created in [from, to). The
+// records should cover the same period; orders paid just outside it show up
+// as missing on one side.
+func (r *Reconciler) Reconcile(ctx context.Context, from, to time.Time, records []Record) (*Report, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("period end must be after its start")
+	}
+	remotes, err := r.fetch(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*remote, len(remotes))
+	byRef := make(map[string][]*remote)
+	for _, rem := range remotes {
+		byID[rem.invoice.InvoiceID] = rem
+		if rem.invoice.APIRef != "" {
+			byRef[rem.invoice.APIRef] = append(byRef[rem.invoice.APIRef], rem)
+		}
+	}
+
+	report := &Report{From: from, To: to, Counts: make(map[Status]int)}
+	add := func(item Item) {
+		report.Items = append(report.Items, item)
+		report.Counts[item.Status]++
+	}
+
+	// Exact keys first, so an amount/time guess never takes an invoice that a
+	// later record names explicitly
+	pending := make([]int, 0, len(records))
+	pairs := make(map[int]Item)
+	for i := range records {
+		rec := &records[i]
+		if rem, ok := byID[rec.InvoiceID]; ok && rec.InvoiceID != "" && !rem.used {
+			pairs[i] = r.compare(rec, rem, ByInvoiceID)
+			continue
+		}
+		if rem := r.pick(rec, byRef[rec.APIRef]); rem != nil && rec.APIRef != "" {
+			pairs[i] = r.compare(rec, rem, ByAPIRef)
+			continue
+		}
+		pending = append(pending, i)
+	}
+	for _, i := range pending {
+		rec := &records[i]
+		if rec.InvoiceID == "" && rec.APIRef == "" {
+			if rem := r.nearest(rec, remotes); rem != nil {
+				pairs[i] = r.compare(rec, rem, ByAmountTime)
+				continue
+			}
+		}
+		pairs[i] = Item{Status: MissingRemote, Record: rec, Detail: missingDetail(rec)}
+	}
+	for i := range records {
+		add(pairs[i])
+	}
+	for _, rem := range remotes {
+		if !rem.used {
+			inv := rem.invoice
+			add(Item{Status: MissingLocal, Invoice: &inv, Transactions: rem.txs})
+		}
+	}
+	return report, nil
+}
+
+// fetch pages through invoices and transactions for the period and joins
+// transactions to their invoices. Invoices are listed newest first, so
+// paging stops at the first page with nothing created since from.
+func (r *Reconciler) fetch(ctx context.Context, from, to time.Time) ([]*remote, error) {
+	var remotes []*remote
+	byID := make(map[string]*remote)
+	size := r.cfg.PageSize
+	for page := 1; ; page++ {
+		resp, err := r.cfg.Client.ListInvoices(&intasend.ListInvoicesParams{Page: &page, PageSize: &size}, intasend.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("listing invoices: %w", err)
+		}
+		older := true
+		for _, inv := range resp.Results {
+			if !inv.CreatedAt.Before(from) {
+				older = false
+			}
+			if inv.CreatedAt.Before(from) || !inv.CreatedAt.Before(to) {
+				continue
+			}
+			rem := &remote{invoice: inv}
+			remotes = append(remotes, rem)
+			byID[inv.InvoiceID] = rem
+		}
+		if older || !intasend.HasNextPage(resp.Next) {
+			break
+		}
+	}
+
+	// The API filters by calendar date in its own time zone, so ask for a day
+	// either side; transactions are only kept if they join to an invoice
+	params := &intasend.ListTransactionsParams{
+		PageSize: &size,
+		DateFrom: from.AddDate(0, 0, -1).Format(time.DateOnly),
+		DateTo:   to.AddDate(0, 0, 1).Format(time.DateOnly),
+	}
+	for page := 1; ; page++ {
+		params.Page = &page
+		resp, err := r.cfg.Client.ListTransactions(params, intasend.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("listing transactions: %w", err)
+		}
+		for _, tx := range resp.Results {
+			if tx.Invoice == nil {
+				continue
+			}
+			if rem, ok := byID[tx.Invoice.InvoiceID]; ok {
+				rem.txs = append(rem.txs, tx)
+			}
+		}
+		if !intasend.HasNextPage(resp.Next) || len(resp.Results) == 0 {
+			break
+		}
+	}
+
+	sort.SliceStable(remotes, func(i, j int) bool {
+		return remotes[i].invoice.CreatedAt.Before(remotes[j].invoice.CreatedAt)
+	})
+	return remotes, nil
+}
+
+// pick returns the first unused invoice among candidates, preferring one
+// whose amount agrees with the record
+func (r *Reconciler) pick(rec *Record, candidates []*remote) *remote {
+	var first *remote
+	for _, rem := range candidates {
+		if rem.used {
+			continue
+		}
+		if r.sameAmount(rec.Amount, rem.invoice.Value) {
+			return rem
+		}
+		if first == nil {
+			first = rem
+		}
+	}
+	return first
+}
+
+// nearest returns the unused invoice with the same amount and currency that
+// is closest in time to the record, within the time window
+func (r *Reconciler) nearest(rec *Record, remotes []*remote) *remote {
+	var best *remote
+	var bestGap time.Duration
+	for _, rem := range remotes {
+		if rem.used || !r.sameAmount(rec.Amount, rem.invoice.Value) {
+			continue
+		}
+		if rec.Currency != "" && !strings.EqualFold(rec.Currency, rem.invoice.Currency) {
+			continue
+		}
+		gap := rec.Time.Sub(rem.invoice.CreatedAt)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap <= r.cfg.TimeWindow && (best == nil || gap < bestGap) {
+			best, bestGap = rem, gap
+		}
+	}
+	return best
+}
+
+// compare pairs a record with an invoice and classifies the pair
+func (r *Reconciler) compare(rec *Record, rem *remote, kind MatchKind) Item {
+	rem.used = true
+	inv := rem.invoice
+	item := Item{Status: Matched, MatchedBy: kind, Record: rec, Invoice: &inv, Transactions: rem.txs}
+
+	var problems []string
+	if !r.sameAmount(rec.Amount, inv.Value) {
+		item.Status = AmountMismatch
+		problems = append(problems, fmt.Sprintf("amount %.2f, IntaSend has %.2f", rec.Amount, inv.Value))
+	}
+	if rec.Currency != "" && !strings.EqualFold(rec.Currency, inv.Currency) {
+		item.Status = AmountMismatch
+		problems = append(problems, fmt.Sprintf("currency %s, IntaSend has %s", rec.Currency, inv.Currency))
+	}
+	if rec.State != "" && !strings.EqualFold(rec.State, inv.State) {
+		if item.Status == Matched {
+			item.Status = StateMismatch
+		}
+		problems = append(problems, fmt.Sprintf("state %s, IntaSend has %s", rec.State, inv.State))
+	}
+	item.Detail = strings.Join(problems, "; ")
+	return item
+}
+
+func (r *Reconciler) sameAmount(a, b float64) bool {
+	return math.Abs(a-b) <= r.cfg.AmountTolerance+1e-9
+}
+
+func missingDetail(rec *Record) string {
+	switch {
+	case rec.InvoiceID != "":
+		return "no invoice " + rec.InvoiceID + " in the period"
+	case rec.APIRef != "":
+		return "no invoice with API reference " + rec.APIRef + " in the period"
+	}
+	return "no invoice with the same amount within the time window"
+}
@@ -0,0 +1,148 @@
+package intasendrecon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+	"github.com/techliana/intasend-sdk-golang/intasendtest"
+)
+
+func TestReconcile(t *testing.T) {
+	srv := intasendtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	checkout := func(amount float64, ref string) string {
+		t.Helper()
+		resp, err := client.CreateCheckoutLink(&intasend.PaymentRequest{Email: "customer@example.com", Amount: amount, APIRef: ref})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.ID
+	}
+	byID := checkout(1000, "")
+	byRef := checkout(250, "order-2")
+	short := checkout(300, "order-3")
+	failed := checkout(400, "order-4")
+	guessed := checkout(75, "")
+	unknown := checkout(990, "someone-else")
+	for _, id := range []string{byID, byRef, short, guessed, unknown} {
+		if err := srv.Complete(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := srv.Fail(failed, "Request cancelled by user"); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	records := []Record{
+		{ID: "1", InvoiceID: byID, Amount: 1000, State: intasend.StatusComplete, Time: now},
+		{ID: "2", APIRef: "order-2", Amount: 250, Currency: "KES", Time: now},
+		{ID: "3", APIRef: "order-3", Amount: 350, Time: now},
+		{ID: "4", APIRef: "order-4", Amount: 400, State: intasend.StatusComplete, Time: now},
+		{ID: "5", Amount: 75, Time: now.Add(2 * time.Minute)},
+		{ID: "6", APIRef: "order-6", Amount: 50, Time: now},
+		{ID: "7", Amount: 75, Time: now.Add(time.Hour)},
+	}
+
+	r, err := New(Config{Client: client, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := r.Reconcile(context.Background(), now.Add(-time.Hour), now.Add(time.Hour), records)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	want := map[string]struct {
+		status Status
+		kind   MatchKind
+	}{
+		"1": {Matched, ByInvoiceID},
+		"2": {Matched, ByAPIRef},
+		"3": {AmountMismatch, ByAPIRef},
+		"4": {StateMismatch, ByAPIRef},
+		"5": {Matched, ByAmountTime},
+		"6": {MissingRemote, ""},
+		"7": {MissingRemote, ""},
+	}
+	for _, item := range report.Items {
+		if item.Record == nil {
+			if item.Status != MissingLocal || item.Invoice.InvoiceID != unknown {
+				t.Errorf("Unexpected unpaired invoice: %+v", item)
+			}
+			continue
+		}
+		w := want[item.Record.ID]
+		if item.Status != w.status || item.MatchedBy != w.kind {
+			t.Errorf("Record %s: got %s by %q (%s), want %s by %q", item.Record.ID, item.Status, item.MatchedBy, item.Detail, w.status, w.kind)
+		}
+	}
+	if item := report.Items[2]; item.Detail != "amount 350.00, IntaSend has 300.00" {
+		t.Errorf("Unexpected mismatch detail: %q", item.Detail)
+	}
+	if item := report.Items[0]; len(item.Transactions) != 1 || !item.Transactions[0].IsDeposit() {
+		t.Errorf("Expected the completed invoice to carry its deposit, got %+v", item.Transactions)
+	}
+	if report.Counts[Matched] != 3 || report.Counts[MissingLocal] != 1 || len(report.Filter(MissingRemote)) != 2 || report.Clean() {
+		t.Errorf("Unexpected counts: %v", report.Counts)
+	}
+}
+
+// pagedClient serves one invoice per page, newest first, and records the
+// requests it receives
+type pagedClient struct {
+	intasend.Invoices
+	intasend.Transactions
+	invoices     []intasend.InvoiceItem
+	invoicePages int
+	txParams     []intasend.ListTransactionsParams
+}
+
+func (c *pagedClient) ListInvoices(params *intasend.ListInvoicesParams, opts ...intasend.CallOption) (*intasend.PaginatedInvoices, error) {
+	c.invoicePages++
+	resp := &intasend.PaginatedInvoices{Count: len(c.invoices)}
+	if i := *params.Page - 1; i < len(c.invoices) {
+		resp.Results = c.invoices[i : i+1]
+	}
+	if *params.Page < len(c.invoices) {
+		next := "next"
+		resp.Next = &next
+	}
+	return resp, nil
+}
+
+func (c *pagedClient) ListTransactions(params *intasend.ListTransactionsParams, opts ...intasend.CallOption) (*intasend.TransactionResp, error) {
+	c.txParams = append(c.txParams, *params)
+	return &intasend.TransactionResp{}, nil
+}
+
+func TestReconcileStopsPaging(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	client := &pagedClient{}
+	for _, day := range []int{2, 1, 0, -1, -2, -3} {
+		client.invoices = append(client.invoices, intasend.InvoiceItem{InvoiceID: fmt.Sprint("INV", day), CreatedAt: from.AddDate(0, 0, day).Add(time.Hour)})
+	}
+
+	r, err := New(Config{Client: client, PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := r.Reconcile(context.Background(), from, from.AddDate(0, 0, 2), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.invoicePages != 4 {
+		t.Errorf("Expected paging to stop at the first invoice before the period, fetched %d pages", client.invoicePages)
+	}
+	if len(report.Items) != 2 || report.Items[0].Invoice.InvoiceID != "INV0" || report.Items[1].Invoice.InvoiceID != "INV1" {
+		t.Errorf("Expected the two invoices in the period, got %+v", report.Items)
+	}
+	if p := client.txParams[0]; p.DateFrom != "2024-02-29" || p.DateTo != "2024-03-04" {
+		t.Errorf("Expected transaction dates padded by a day, got %s to %s", p.DateFrom, p.DateTo)
+	}
+}
@@ -0,0 +1,59 @@
+-- Schema for PostgreSQL 9.5 or later.
+
+CREATE TABLE IF NOT EXISTS intasend_invoices (
+    invoice_id      TEXT PRIMARY KEY,
+    state           TEXT NOT NULL,
+    provider        TEXT,
+    charges         NUMERIC,
+    net_amount      NUMERIC,
+    currency        TEXT,
+    value           NUMERIC,
+    account         TEXT,
+    api_ref         TEXT,
+    clearing_status TEXT,
+    mpesa_reference TEXT,
+    failed_reason   TEXT,
+    subscription_id TEXT,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    synced_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS intasend_invoices_api_ref ON intasend_invoices (api_ref);
+CREATE INDEX IF NOT EXISTS intasend_invoices_created_at ON intasend_invoices (created_at);
+
+CREATE TABLE IF NOT EXISTS intasend_transactions (
+    transaction_id  TEXT PRIMARY KEY,
+    wallet_id       TEXT,
+    invoice_id      TEXT,
+    currency        TEXT,
+    value           NUMERIC,
+    running_balance NUMERIC,
+    narrative       TEXT,
+    trans_type      TEXT,
+    status          TEXT,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    synced_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS intasend_transactions_wallet ON intasend_transactions (wallet_id, created_at);
+CREATE INDEX IF NOT EXISTS intasend_transactions_invoice ON intasend_transactions (invoice_id);
+
+CREATE TABLE IF NOT EXISTS intasend_wallets (
+    wallet_id         TEXT PRIMARY KEY,
+    label             TEXT,
+    currency          TEXT,
+    wallet_type       TEXT,
+    can_disburse      BOOLEAN NOT NULL DEFAULT FALSE,
+    current_balance   NUMERIC,
+    available_balance NUMERIC,
+    updated_at        TIMESTAMPTZ,
+    synced_at         TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS intasend_sync_cursors (
+    resource   TEXT PRIMARY KEY,
+    updated_at TIMESTAMPTZ NOT NULL,
+    synced_at  TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,290 @@
+package intasendsync
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// Store receives the mirrored records. Upserts are keyed by the IntaSend ID
+// and must not replace a row with an older version of itself.
+type Store interface {
+	// Cursor returns the newest updated_at recorded for a resource, or the
+	// zero time if it has never been synced
+	Cursor(ctx context.Context, resource string) (time.Time, error)
+	SetCursor(ctx context.Context, resource string, cursor time.Time) error
+
+	UpsertInvoices(ctx context.Context, invoices []intasend.InvoiceItem) error
+	UpsertTransactions(ctx context.Context, walletID string, txs []intasend.Result) error
+	UpsertWallets(ctx context.Context, wallets []intasend.WalletResp) error
+
+	// ApplyCallback updates an invoice from a collection webhook. Columns the
+	// callback does not carry keep their synced values.
+	ApplyCallback(ctx context.Context, cb *intasend.CollectionCallback) error
+}
+
+// Dialect selects the SQL flavour an SQLStore writes
+type Dialect string
+
+const (
+	SQLite   Dialect = "sqlite"
+	Postgres Dialect = "postgres"
+)
+
+//go:embed schema/*.sql
+var schemas embed.FS
+
+// sqliteTimeLayout sorts lexically in time order, which SQLite relies on
+// when comparing timestamps stored as text
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// Schema returns the CREATE statements for the dialect's tables
+func (d Dialect) Schema() (string, error) {
+	switch d {
+	case SQLite, Postgres:
+		b, err := schemas.ReadFile("schema/" + string(d) + ".sql")
+		return string(b), err
+	}
+	return "", fmt.Errorf("unsupported dialect %q", d)
+}
+
+func (d Dialect) placeholder(n int) string {
+	if d == Postgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// timeArg converts t to the column's representation, or NULL if it is zero
+func (d Dialect) timeArg(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	if d == SQLite {
+		return t.UTC().Format(sqliteTimeLayout)
+	}
+	return t.UTC()
+}
+
+// upsert builds an insert that updates the existing row when its updated_at
+// is not newer than the incoming one
+func (d Dialect) upsert(table, key string, columns []string) string {
+	values := make([]string, len(columns))
+	var set []string
+	for i, col := range columns {
+		values[i] = d.placeholder(i + 1)
+		if col != key {
+			set = append(set, col+" = excluded."+col)
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s WHERE %s.updated_at IS NULL OR %s.updated_at <= excluded.updated_at",
+		table, strings.Join(columns, ", "), strings.Join(values, ", "), key, strings.Join(set, ", "), table, table)
+}
+
+var (
+	invoiceColumns = []string{
+		"invoice_id", "state", "provider", "charges", "net_amount", "currency", "value", "account", "api_ref",
+		"clearing_status", "mpesa_reference", "failed_reason", "subscription_id", "created_at", "updated_at", "synced_at",
+	}
+	callbackColumns = []string{
+		"invoice_id", "state", "provider", "charges", "net_amount", "currency", "value", "account", "api_ref",
+		"failed_reason", "subscription_id", "created_at", "updated_at", "synced_at",
+	}
+	transactionColumns = []string{
+		"transaction_id", "wallet_id", "invoice_id", "currency", "value", "running_balance",
+		"narrative", "trans_type", "status", "created_at", "updated_at", "synced_at",
+	}
+	walletColumns = []string{
+		"wallet_id", "label", "currency", "wallet_type", "can_disburse",
+		"current_balance", "available_balance", "updated_at", "synced_at",
+	}
+	cursorColumns = []string{"resource", "updated_at", "synced_at"}
+)
+
+// SQLStore is a Store over database/sql. Register a SQLite or Postgres
+// driver in the program and pass the opened database.
+type SQLStore struct {
+	db      *sql.DB
+	dialect Dialect
+	now     func() time.Time
+}
+
+// NewSQLStore creates a store writing to db in the given dialect
+func NewSQLStore(db *sql.DB, dialect Dialect) (*SQLStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+	if _, err := dialect.Schema(); err != nil {
+		return nil, err
+	}
+	return &SQLStore{db: db, dialect: dialect, now: time.Now}, nil
+}
+
+// Migrate creates the tables and indexes that do not exist yet
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	schema, err := s.dialect.Schema()
+	if err != nil {
+		return err
+	}
+	for _, stmt := range strings.Split(schema, ";") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("creating schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// Cursor implements Store
+func (s *SQLStore) Cursor(ctx context.Context, resource string) (time.Time, error) {
+	var v any
+	err := s.db.QueryRowContext(ctx, "SELECT updated_at FROM intasend_sync_cursors WHERE resource = "+s.dialect.placeholder(1), resource).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading %s cursor: %w", resource, err)
+	}
+	return scanTime(v)
+}
+
+// SetCursor implements Store. A cursor never moves backwards.
+func (s *SQLStore) SetCursor(ctx context.Context, resource string, cursor time.Time) error {
+	d := s.dialect
+	_, err := s.db.ExecContext(ctx, d.upsert("intasend_sync_cursors", "resource", cursorColumns),
+		resource, d.timeArg(cursor), d.timeArg(s.now()))
+	if err != nil {
+		return fmt.Errorf("saving %s cursor: %w", resource, err)
+	}
+	return nil
+}
+
+// UpsertInvoices implements Store
+func (s *SQLStore) UpsertInvoices(ctx context.Context, invoices []intasend.InvoiceItem) error {
+	d, now := s.dialect, s.now()
+	rows := make([][]any, len(invoices))
+	for i, inv := range invoices {
+		rows[i] = []any{
+			inv.InvoiceID, inv.State, inv.Provider, inv.Charges, amountArg(inv.NetAmount), inv.Currency, inv.Value, inv.Account, inv.APIRef,
+			inv.ClearingStatus, stringArg(inv.MpesaReference), stringArg(inv.FailedReason), inv.SubscriptionID,
+			d.timeArg(inv.CreatedAt), d.timeArg(inv.UpdatedAt), d.timeArg(now),
+		}
+	}
+	return s.exec(ctx, "invoices", d.upsert("intasend_invoices", "invoice_id", invoiceColumns), rows)
+}
+
+// UpsertTransactions implements Store
+func (s *SQLStore) UpsertTransactions(ctx context.Context, walletID string, txs []intasend.Result) error {
+	d, now := s.dialect, s.now()
+	rows := make([][]any, len(txs))
+	for i, tx := range txs {
+		var invoiceID any
+		if tx.Invoice != nil {
+			invoiceID = tx.Invoice.InvoiceID
+		}
+		rows[i] = []any{
+			tx.TransactionID, walletID, invoiceID, tx.Currency, tx.Value, tx.RunningBalance,
+			tx.Narrative, tx.TransType, tx.Status, d.timeArg(tx.CreatedAt), d.timeArg(tx.UpdatedAt), d.timeArg(now),
+		}
+	}
+	return s.exec(ctx, "transactions", d.upsert("intasend_transactions", "transaction_id", transactionColumns), rows)
+}
+
+// UpsertWallets implements Store
+func (s *SQLStore) UpsertWallets(ctx context.Context, wallets []intasend.WalletResp) error {
+	d, now := s.dialect, s.now()
+	rows := make([][]any, len(wallets))
+	for i, w := range wallets {
+		rows[i] = []any{
+			w.WalletID, w.Label, w.Currency, w.WalletType, w.CanDisburse,
+			w.CurrentBalance, w.AvailableBalance, d.timeArg(w.UpdatedAt), d.timeArg(now),
+		}
+	}
+	return s.exec(ctx, "wallets", d.upsert("intasend_wallets", "wallet_id", walletColumns), rows)
+}
+
+// ApplyCallback implements Store
+func (s *SQLStore) ApplyCallback(ctx context.Context, cb *intasend.CollectionCallback) error {
+	d := s.dialect
+	var failedReason any
+	if cb.FailedReason != "" {
+		failedReason = cb.FailedReason
+	}
+	row := []any{
+		cb.InvoiceID, cb.State, cb.Provider, amountArg(cb.Charges), amountArg(cb.NetAmount), cb.Currency, amountArg(cb.Value), cb.Account, cb.APIRef,
+		failedReason, cb.SubscriptionID, d.timeArg(cb.CreatedAt), d.timeArg(cb.UpdatedAt), d.timeArg(s.now()),
+	}
+	return s.exec(ctx, "callback", d.upsert("intasend_invoices", "invoice_id", callbackColumns), [][]any{row})
+}
+
+// exec runs one statement per row in a single database transaction
+func (s *SQLStore) exec(ctx context.Context, what, query string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	defer stmt.Close()
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("writing %s %v: %w", what, row[0], err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	return nil
+}
+
+// scanTime reads a timestamp column, which SQLite drivers return as text
+func scanTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	case []byte:
+		return scanTime(string(v))
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %q", v)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unexpected timestamp type %T", v)
+}
+
+// amountArg parses an amount the API sends as text, storing NULL when it is
+// empty or not a number
+func amountArg(s string) any {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func stringArg(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
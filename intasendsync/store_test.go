@@ -0,0 +1,163 @@
+package intasendsync
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// recorder is a database/sql driver that records statements instead of
+// running them. Queries return cursor as their single row, if set.
+type recorder struct {
+	execs   []recordedExec
+	cursor  driver.Value
+	commits int
+}
+
+type recordedExec struct {
+	query string
+	args  []driver.Value
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return r, nil }
+func (r *recorder) Driver() driver.Driver                        { return nil }
+func (r *recorder) Prepare(query string) (driver.Stmt, error)    { return &recordedStmt{r, query}, nil }
+func (r *recorder) Close() error                                 { return nil }
+func (r *recorder) Begin() (driver.Tx, error)                    { return r, nil }
+func (r *recorder) Commit() error                                { r.commits++; return nil }
+func (r *recorder) Rollback() error                              { return nil }
+
+type recordedStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recordedStmt) Close() error  { return nil }
+func (s *recordedStmt) NumInput() int { return -1 }
+
+func (s *recordedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.r.execs = append(s.r.execs, recordedExec{s.query, args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &recordedRows{value: s.r.cursor, done: s.r.cursor == nil}, nil
+}
+
+type recordedRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *recordedRows) Columns() []string { return []string{"updated_at"} }
+func (r *recordedRows) Close() error      { return nil }
+
+func (r *recordedRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0], r.done = r.value, true
+	return nil
+}
+
+func newRecordedStore(t *testing.T, dialect Dialect) (*SQLStore, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	db := sql.OpenDB(rec)
+	t.Cleanup(func() { db.Close() })
+	store, err := NewSQLStore(db, dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return store, rec
+}
+
+func TestSQLStoreMigrate(t *testing.T) {
+	for _, dialect := range []Dialect{SQLite, Postgres} {
+		store, rec := newRecordedStore(t, dialect)
+		if err := store.Migrate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(rec.execs) != 8 {
+			t.Errorf("%s: expected 4 tables and 4 indexes, got %d statements", dialect, len(rec.execs))
+		}
+		for _, e := range rec.execs {
+			if !strings.Contains(e.query, "CREATE") {
+				t.Errorf("%s: unexpected statement %q", dialect, e.query)
+			}
+		}
+	}
+	if _, err := NewSQLStore(sql.OpenDB(&recorder{}), "mysql"); err == nil {
+		t.Error("Expected an unsupported dialect to fail")
+	}
+}
+
+func TestSQLStoreUpsert(t *testing.T) {
+	ctx := context.Background()
+	updated := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("EAT", 3*60*60))
+	inv := intasend.InvoiceItem{InvoiceID: "INV1", State: intasend.StatusComplete, NetAmount: "96.50", Value: 100, UpdatedAt: updated}
+
+	store, rec := newRecordedStore(t, Postgres)
+	if err := store.UpsertInvoices(ctx, []intasend.InvoiceItem{inv, inv}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.execs) != 2 || rec.commits != 1 {
+		t.Fatalf("Expected 2 rows in one transaction, got %d in %d", len(rec.execs), rec.commits)
+	}
+	e := rec.execs[0]
+	if !strings.HasPrefix(e.query, "INSERT INTO intasend_invoices (invoice_id, state,") ||
+		!strings.Contains(e.query, "$16) ON CONFLICT (invoice_id) DO UPDATE SET state = excluded.state,") ||
+		!strings.HasSuffix(e.query, "WHERE intasend_invoices.updated_at IS NULL OR intasend_invoices.updated_at <= excluded.updated_at") {
+		t.Errorf("Unexpected upsert: %s", e.query)
+	}
+	if e.args[4] != 96.5 || e.args[10] != nil || e.args[13] != nil {
+		t.Errorf("Expected a parsed net amount and NULLs for missing values, got %v", e.args)
+	}
+	if got, ok := e.args[14].(time.Time); !ok || !got.Equal(updated) || got.Location() != time.UTC {
+		t.Errorf("Expected updated_at in UTC, got %v", e.args[14])
+	}
+
+	store, rec = newRecordedStore(t, SQLite)
+	cb := &intasend.CollectionCallback{InvoiceID: "INV1", State: intasend.StatusFailed, Charges: "0.00", Value: "100.00", FailedReason: "Request cancelled by user", UpdatedAt: updated}
+	if err := store.ApplyCallback(ctx, cb); err != nil {
+		t.Fatal(err)
+	}
+	e = rec.execs[0]
+	if strings.Contains(e.query, "clearing_status") || strings.Contains(e.query, "$") {
+		t.Errorf("Expected a partial update with ? placeholders, got %s", e.query)
+	}
+	if e.args[3] != 0.0 || e.args[9] != "Request cancelled by user" || e.args[12] != "2024-03-01T09:30:00.000000Z" {
+		t.Errorf("Unexpected callback args: %v", e.args)
+	}
+}
+
+func TestSQLStoreCursor(t *testing.T) {
+	ctx := context.Background()
+	store, rec := newRecordedStore(t, SQLite)
+
+	got, err := store.Cursor(ctx, InvoicesCursor)
+	if err != nil || !got.IsZero() {
+		t.Fatalf("Expected no cursor, got %v, %v", got, err)
+	}
+
+	rec.cursor = "2024-03-01T09:30:00.000000Z"
+	got, err = store.Cursor(ctx, InvoicesCursor)
+	if err != nil || !got.Equal(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected cursor %v, %v", got, err)
+	}
+
+	if err := store.SetCursor(ctx, TransactionsCursor+"WKES001", got); err != nil {
+		t.Fatal(err)
+	}
+	e := rec.execs[0]
+	if !strings.HasPrefix(e.query, "INSERT INTO intasend_sync_cursors") || e.args[0] != "transactions/WKES001" || e.args[2] != "2024-03-01T12:00:00.000000Z" {
+		t.Errorf("Unexpected cursor write: %s %v", e.query, e.args)
+	}
+}
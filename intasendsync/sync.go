@@ -0,0 +1,288 @@
+// Package intasendsync mirrors IntaSend invoices, transactions and wallets
+// into a local store, so dashboards and reports can query them without
+// calling the API.
+//
+//	db, err := sql.Open("sqlite", "intasend.db") // any SQLite or Postgres driver
+//	if err != nil { ... }
+//	store, err := intasendsync.NewSQLStore(db, intasendsync.SQLite)
+//	if err != nil { ... }
+//	if err := store.Migrate(ctx); err != nil { ... }
+//	s, err := intasendsync.New(intasendsync.Config{Client: client, Store: store})
+//	if err != nil { ... }
+//	go s.Run(ctx)
+//
+// Each pass resumes from a per-resource cursor, the newest updated_at it has
+// stored, and asks the API only for records updated in the narrowest period
+// that still covers it. Rows are upserted by IntaSend ID, so repeating a pass
+// is harmless.
+//
+// Between passes, collection webhooks can be applied with ApplyCallback so
+// invoice states stay current without waiting for the next poll.
+package intasendsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// Cursor names used with Store.Cursor. Transactions keep one cursor per
+// wallet, named TransactionsCursor followed by the wallet ID.
+const (
+	InvoicesCursor     = "invoices"
+	WalletsCursor      = "wallets"
+	TransactionsCursor = "transactions/"
+)
+
+// Defaults used by New when the config leaves them unset
+const (
+	DefaultPageSize = 100
+	DefaultOverlap  = 5 * time.Minute
+	DefaultInterval = time.Minute
+)
+
+// APILocation is the calendar IntaSend evaluates updated_at periods in
+var APILocation = time.FixedZone("EAT", 3*60*60)
+
+// Client is the part of the IntaSend API the syncer reads
+type Client interface {
+	intasend.Invoices
+	intasend.Transactions
+	intasend.Wallets
+}
+
+// Config configures a Syncer
+type Config struct {
+	Client Client // required
+	Store  Store  // required
+
+	PageSize int           // records fetched per request, defaults to DefaultPageSize
+	Overlap  time.Duration // how far before the cursor each pull starts, to catch late writes; defaults to DefaultOverlap
+	Interval time.Duration // time between passes in Run, defaults to DefaultInterval
+
+	// OnSync, if set, is called by Run after every pass
+	OnSync func(*Summary, error)
+}
+
+// Summary counts the rows one pass wrote
+type Summary struct {
+	Invoices     int       `json:"invoices"`
+	Transactions int       `json:"transactions"`
+	Wallets      int       `json:"wallets"`
+	Started      time.Time `json:"started"`
+	Finished     time.Time `json:"finished"`
+}
+
+// Syncer copies IntaSend records into a Store
+type Syncer struct {
+	cfg Config
+	now func() time.Time
+}
+
+// New creates a syncer from the given configuration
+func New(cfg Config) (*Syncer, error) {
+	if cfg.Client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+	if cfg.Store == nil {
+		return nil, fmt.Errorf("store is required")
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = DefaultPageSize
+	}
+	if cfg.Overlap <= 0 {
+		cfg.Overlap = DefaultOverlap
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	return &Syncer{cfg: cfg, now: time.Now}, nil
+}
+
+// Run syncs every Interval until ctx is cancelled, and returns ctx's error.
+// Failed passes are reported to OnSync and retried on the next tick.
+func (s *Syncer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		summary, err := s.Sync(ctx)
+		if s.cfg.OnSync != nil {
+			s.cfg.OnSync(summary, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync makes one pass over wallets, invoices and each wallet's transactions.
+// A resource that fails keeps its cursor and does not stop the others; the
+// errors are joined.
+func (s *Syncer) Sync(ctx context.Context) (*Summary, error) {
+	summary := &Summary{Started: s.now()}
+	var errs []error
+
+	wallets, err := s.syncWallets(ctx, summary)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.syncInvoices(ctx, summary); err != nil {
+		errs = append(errs, err)
+	}
+	for _, w := range wallets {
+		if err := s.syncTransactions(ctx, w.WalletID, summary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	summary.Finished = s.now()
+	return summary, errors.Join(errs...)
+}
+
+// ApplyCallback writes a collection webhook to the store. It does not move
+// the invoices cursor, so the next poll still picks up anything the webhooks
+// missed.
+func (s *Syncer) ApplyCallback(ctx context.Context, cb *intasend.CollectionCallback) error {
+	if cb == nil || cb.InvoiceID == "" {
+		return fmt.Errorf("callback invoice ID is required")
+	}
+	return s.cfg.Store.ApplyCallback(ctx, cb)
+}
+
+// syncWallets lists every wallet, since the transaction pulls need them all,
+// but writes only those changed since the cursor
+func (s *Syncer) syncWallets(ctx context.Context, summary *Summary) ([]intasend.WalletResp, error) {
+	var all []intasend.WalletResp
+	n, err := pull(ctx, s, WalletsCursor,
+		func(page int, _ string) ([]intasend.WalletResp, bool, error) {
+			resp, err := s.cfg.Client.ListWallets(&intasend.ListWalletsParams{Page: &page}, intasend.WithContext(ctx))
+			if err != nil {
+				return nil, false, fmt.Errorf("listing wallets: %w", err)
+			}
+			all = append(all, resp.Results...)
+			return resp.Results, intasend.HasNextPage(resp.Next), nil
+		},
+		func(w intasend.WalletResp) time.Time { return w.UpdatedAt },
+		s.cfg.Store.UpsertWallets)
+	summary.Wallets += n
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (s *Syncer) syncInvoices(ctx context.Context, summary *Summary) error {
+	size := s.cfg.PageSize
+	n, err := pull(ctx, s, InvoicesCursor,
+		func(page int, period string) ([]intasend.InvoiceItem, bool, error) {
+			params := &intasend.ListInvoicesParams{Page: &page, PageSize: &size, UpdatedAt: period}
+			resp, err := s.cfg.Client.ListInvoices(params, intasend.WithContext(ctx))
+			if err != nil {
+				return nil, false, fmt.Errorf("listing invoices: %w", err)
+			}
+			return resp.Results, intasend.HasNextPage(resp.Next), nil
+		},
+		func(inv intasend.InvoiceItem) time.Time { return inv.UpdatedAt },
+		s.cfg.Store.UpsertInvoices)
+	summary.Invoices += n
+	return err
+}
+
+func (s *Syncer) syncTransactions(ctx context.Context, walletID string, summary *Summary) error {
+	size := s.cfg.PageSize
+	n, err := pull(ctx, s, TransactionsCursor+walletID,
+		func(page int, period string) ([]intasend.Result, bool, error) {
+			params := &intasend.ListTransactionsParams{Page: &page, PageSize: &size, WalletID: walletID, UpdatedAt: period}
+			resp, err := s.cfg.Client.ListTransactions(params, intasend.WithContext(ctx))
+			if err != nil {
+				return nil, false, fmt.Errorf("listing transactions of wallet %s: %w", walletID, err)
+			}
+			return resp.Results, intasend.HasNextPage(resp.Next), nil
+		},
+		func(tx intasend.Result) time.Time { return tx.UpdatedAt },
+		func(ctx context.Context, txs []intasend.Result) error {
+			return s.cfg.Store.UpsertTransactions(ctx, walletID, txs)
+		})
+	summary.Transactions += n
+	return err
+}
+
+// pull pages through one resource from its cursor, writes every record
+// updated since then, and advances the cursor once all pages are stored
+func pull[T any](ctx context.Context, s *Syncer, cursor string,
+	fetch func(page int, period string) ([]T, bool, error),
+	updated func(T) time.Time,
+	write func(context.Context, []T) error) (int, error) {
+
+	last, err := s.cfg.Store.Cursor(ctx, cursor)
+	if err != nil {
+		return 0, err
+	}
+	var since time.Time
+	if !last.IsZero() {
+		since = last.Add(-s.cfg.Overlap)
+	}
+	period := updatedPeriod(since, s.now())
+
+	written, newest := 0, last
+	for page := 1; ; page++ {
+		items, more, err := fetch(page, period)
+		if err != nil {
+			return written, err
+		}
+		var changed []T
+		for _, item := range items {
+			t := updated(item)
+			if !since.IsZero() && t.Before(since) {
+				continue
+			}
+			changed = append(changed, item)
+			if t.After(newest) {
+				newest = t
+			}
+		}
+		if len(changed) > 0 {
+			if err := write(ctx, changed); err != nil {
+				return written, err
+			}
+			written += len(changed)
+		}
+		if !more || len(items) == 0 {
+			break
+		}
+	}
+
+	if newest.After(last) {
+		if err := s.cfg.Store.SetCursor(ctx, cursor, newest); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// updatedPeriod returns the narrowest updated_at filter whose start is at or
+// before since, or "" when since is zero or older than the current year.
+// Weeks start on Monday.
+func updatedPeriod(since, now time.Time) string {
+	if since.IsZero() {
+		return ""
+	}
+	now = now.In(APILocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, APILocation)
+	switch {
+	case !since.Before(today):
+		return "today"
+	case !since.Before(today.AddDate(0, 0, -(int(now.Weekday())+6)%7)):
+		return "week"
+	case !since.Before(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, APILocation)):
+		return "month"
+	case !since.Before(time.Date(now.Year(), 1, 1, 0, 0, 0, 0, APILocation)):
+		return "year"
+	}
+	return ""
+}
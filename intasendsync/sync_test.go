@@ -0,0 +1,208 @@
+package intasendsync
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+	"github.com/techliana/intasend-sdk-golang/intasendtest"
+)
+
+// memStore keeps rows in maps, applying the same newer-wins rule as SQLStore
+type memStore struct {
+	mu           sync.Mutex
+	cursors      map[string]time.Time
+	invoices     map[string]intasend.InvoiceItem
+	transactions map[string]intasend.Result
+	txWallet     map[string]string
+	wallets      map[string]intasend.WalletResp
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		cursors:      make(map[string]time.Time),
+		invoices:     make(map[string]intasend.InvoiceItem),
+		transactions: make(map[string]intasend.Result),
+		txWallet:     make(map[string]string),
+		wallets:      make(map[string]intasend.WalletResp),
+	}
+}
+
+func (m *memStore) Cursor(ctx context.Context, resource string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cursors[resource], nil
+}
+
+func (m *memStore) SetCursor(ctx context.Context, resource string, cursor time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cursor.After(m.cursors[resource]) {
+		m.cursors[resource] = cursor
+	}
+	return nil
+}
+
+func (m *memStore) UpsertInvoices(ctx context.Context, invoices []intasend.InvoiceItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, inv := range invoices {
+		if old, ok := m.invoices[inv.InvoiceID]; !ok || !old.UpdatedAt.After(inv.UpdatedAt) {
+			m.invoices[inv.InvoiceID] = inv
+		}
+	}
+	return nil
+}
+
+func (m *memStore) UpsertTransactions(ctx context.Context, walletID string, txs []intasend.Result) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tx := range txs {
+		m.transactions[tx.TransactionID] = tx
+		m.txWallet[tx.TransactionID] = walletID
+	}
+	return nil
+}
+
+func (m *memStore) UpsertWallets(ctx context.Context, wallets []intasend.WalletResp) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range wallets {
+		m.wallets[w.WalletID] = w
+	}
+	return nil
+}
+
+func (m *memStore) ApplyCallback(ctx context.Context, cb *intasend.CollectionCallback) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inv := m.invoices[cb.InvoiceID]
+	if inv.UpdatedAt.After(cb.UpdatedAt) {
+		return nil
+	}
+	inv.InvoiceID, inv.State, inv.UpdatedAt = cb.InvoiceID, cb.State, cb.UpdatedAt
+	m.invoices[cb.InvoiceID] = inv
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	srv := intasendtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	checkout := func(amount float64) string {
+		t.Helper()
+		resp, err := client.CreateCheckoutLink(&intasend.PaymentRequest{Email: "customer@example.com", Amount: amount})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.ID
+	}
+	paid := checkout(1000)
+	open := checkout(250)
+	time.Sleep(5 * time.Millisecond)
+	if err := srv.Complete(paid); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newMemStore()
+	s, err := New(Config{Client: client, Store: store, PageSize: 1, Overlap: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	first, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if first.Invoices != 2 || first.Transactions != 1 || first.Wallets != 3 {
+		t.Fatalf("Unexpected first pass: %+v", first)
+	}
+	if store.invoices[paid].State != intasend.StatusComplete {
+		t.Errorf("Unexpected invoices after the first pass: %+v", store.invoices)
+	}
+	for id, wallet := range store.txWallet {
+		if wallet != "WKES001" {
+			t.Errorf("Expected transaction %s in WKES001, got %s", id, wallet)
+		}
+	}
+	if store.cursors[InvoicesCursor].IsZero() || store.cursors[TransactionsCursor+"WKES001"].IsZero() {
+		t.Errorf("Expected cursors to be saved, got %v", store.cursors)
+	}
+
+	// Nothing changed, so only the newest rows, inside the overlap, are read again
+	second, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Invoices != 1 || second.Transactions != 1 || second.Wallets != 1 {
+		t.Errorf("Expected only the overlap to be rewritten, got %+v", second)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if err := srv.Fail(open, "Request cancelled by user"); err != nil {
+		t.Fatal(err)
+	}
+	third, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := store.invoices[open]
+	if third.Invoices != 2 || failed.State != intasend.StatusFailed || !store.cursors[InvoicesCursor].Equal(failed.UpdatedAt) {
+		t.Errorf("Expected the failed invoice and the cursor to follow it, got %+v (%s)", third, failed.State)
+	}
+}
+
+func TestApplyCallback(t *testing.T) {
+	srv := intasendtest.NewServer()
+	defer srv.Close()
+	store := newMemStore()
+	s, err := New(Config{Client: srv.Client(), Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	now := time.Now()
+	if err := s.ApplyCallback(ctx, &intasend.CollectionCallback{InvoiceID: "INV1", State: intasend.StatusComplete, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	// A late, older callback must not undo the newer state
+	if err := s.ApplyCallback(ctx, &intasend.CollectionCallback{InvoiceID: "INV1", State: intasend.StatusProcessing, UpdatedAt: now.Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.invoices["INV1"].State; got != intasend.StatusComplete {
+		t.Errorf("Expected COMPLETE, got %s", got)
+	}
+	if !store.cursors[InvoicesCursor].IsZero() {
+		t.Error("Expected callbacks to leave the cursor alone")
+	}
+	if err := s.ApplyCallback(ctx, &intasend.CollectionCallback{}); err == nil {
+		t.Error("Expected a callback without an invoice ID to fail")
+	}
+}
+
+func TestUpdatedPeriod(t *testing.T) {
+	// Wednesday 2024-03-13, 10:00 in Nairobi
+	now := time.Date(2024, 3, 13, 7, 0, 0, 0, time.UTC)
+	tests := []struct {
+		since time.Time
+		want  string
+	}{
+		{time.Time{}, ""},
+		{time.Date(2024, 3, 13, 1, 0, 0, 0, APILocation), "today"},
+		{time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC), "today"}, // already the 13th in Nairobi
+		{time.Date(2024, 3, 11, 0, 0, 0, 0, APILocation), "week"},
+		{time.Date(2024, 3, 10, 23, 0, 0, 0, APILocation), "month"},
+		{time.Date(2024, 2, 29, 0, 0, 0, 0, APILocation), "year"},
+		{time.Date(2023, 12, 31, 0, 0, 0, 0, APILocation), ""},
+	}
+	for _, tt := range tests {
+		if got := updatedPeriod(tt.since, now); got != tt.want {
+			t.Errorf("updatedPeriod(%v) = %q, want %q", tt.since, got, tt.want)
+		}
+	}
+}
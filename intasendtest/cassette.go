@@ -0,0 +1,373 @@
+package intasendtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// Mode selects whether a Cassette records live traffic or replays fixtures
+type Mode int
+
+const (
+	ModeReplay Mode = iota // serve recorded interactions, never touch the network
+	ModeRecord             // forward to the real API and capture the traffic
+)
+
+// CassetteModeEnv is the environment variable UseCassette reads to decide
+// the mode ("record" to capture, anything else to replay)
+const CassetteModeEnv = "INTASEND_CASSETTE_MODE"
+
+// Matcher selects which parts of a request must match a recorded interaction
+type Matcher uint8
+
+const (
+	MatchMethod Matcher = 1 << iota
+	MatchPath
+	MatchQuery
+	MatchBody
+
+	// MatchDefault matches on every part of the request
+	MatchDefault = MatchMethod | MatchPath | MatchQuery | MatchBody
+)
+
+// Redacted replaces secrets and personal data in recorded fixtures
+const Redacted = "REDACTED"
+
+// DefaultRedactedHeaders lists headers whose values are never written to disk
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"X-IntaSend-Public-API-Key",
+	"X-IntaSend-Public-Key-Id",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// DefaultRedactedFields lists JSON fields and query parameters holding
+// credentials or customer PII
+var DefaultRedactedFields = []string{
+	"phone_number",
+	"email",
+	"first_name",
+	"last_name",
+	"name",
+	"account",
+	"id_number",
+	"address",
+	"zipcode",
+	"customer_comment",
+	"signature",
+	"challenge",
+	"token",
+}
+
+// Interaction is a single recorded request/response pair
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the redacted form of an outgoing request
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Query   string      `json:"query,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the redacted form of a response
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is an http.RoundTripper that records or replays API traffic
+type Cassette struct {
+	Path      string            // fixture file
+	Mode      Mode              // record or replay
+	Matcher   Matcher           // parts of the request used for replay matching
+	Transport http.RoundTripper // upstream transport used in record mode
+
+	RedactHeaders []string // headers to redact, defaults to DefaultRedactedHeaders
+	RedactFields  []string // JSON fields/query params to redact, defaults to DefaultRedactedFields
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassette creates a cassette for the fixture at path. In replay mode the
+// fixture is loaded immediately and must exist.
+func NewCassette(path string, mode Mode) (*Cassette, error) {
+	c := &Cassette{
+		Path:          path,
+		Mode:          mode,
+		Matcher:       MatchDefault,
+		Transport:     http.DefaultTransport,
+		RedactHeaders: DefaultRedactedHeaders,
+		RedactFields:  DefaultRedactedFields,
+	}
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &c.interactions); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		c.used = make([]bool, len(c.interactions))
+	}
+	return c, nil
+}
+
+// UseCassette installs a cassette on the client's transport for the duration
+// of a test. Set INTASEND_CASSETTE_MODE=record to capture fresh fixtures.
+func UseCassette(t testing.TB, client *intasend.Client, path string) *Cassette {
+	t.Helper()
+
+	mode := ModeReplay
+	if strings.EqualFold(os.Getenv(CassetteModeEnv), "record") {
+		mode = ModeRecord
+	}
+	c, err := NewCassette(path, mode)
+	if err != nil {
+		t.Fatalf("intasendtest: %v", err)
+	}
+	if mode == ModeRecord && client.HTTPClient.Transport != nil {
+		c.Transport = client.HTTPClient.Transport
+	}
+
+	httpClient := *client.HTTPClient
+	httpClient.Transport = c
+	client.HTTPClient = &httpClient
+
+	t.Cleanup(func() {
+		if err := c.Save(); err != nil {
+			t.Errorf("intasendtest: %v", err)
+		}
+	})
+	return c
+}
+
+// Interactions returns the interactions recorded or loaded so far
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Save writes recorded interactions to the fixture file. It is a no-op in replay mode.
+func (c *Cassette) Save() error {
+	if c.Mode != ModeRecord {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(c.Path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := c.recordRequest(req, body)
+
+	if c.Mode == ModeRecord {
+		return c.record(req, recorded)
+	}
+	return c.replay(req, recorded)
+}
+
+func (c *Cassette) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    c.redactHeaders(resp.Header),
+			Body:       c.redactBody(body),
+		},
+	})
+	c.mu.Unlock()
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, in := range c.interactions {
+		if c.used[i] || !c.matches(in.Request, recorded) {
+			continue
+		}
+		c.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Headers.Clone(),
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("intasendtest: no recorded interaction for %s %s in %s", recorded.Method, recorded.Path, c.Path)
+}
+
+func (c *Cassette) matches(a, b RecordedRequest) bool {
+	m := c.Matcher
+	if m == 0 {
+		m = MatchDefault
+	}
+	if m&MatchMethod != 0 && a.Method != b.Method {
+		return false
+	}
+	if m&MatchPath != 0 && a.Path != b.Path {
+		return false
+	}
+	if m&MatchQuery != 0 && a.Query != b.Query {
+		return false
+	}
+	if m&MatchBody != 0 && a.Body != b.Body {
+		return false
+	}
+	return true
+}
+
+func (c *Cassette) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	query := req.URL.Query()
+	for key := range query {
+		if c.isRedactedField(key) {
+			query.Set(key, Redacted)
+		}
+	}
+	return RecordedRequest{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Query:   query.Encode(),
+		Headers: c.redactHeaders(req.Header),
+		Body:    c.redactBody(body),
+	}
+}
+
+func (c *Cassette) redactHeaders(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for _, name := range c.RedactHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, Redacted)
+		}
+	}
+	return out
+}
+
+// redactBody replaces sensitive JSON fields and normalises key order so
+// recorded and live bodies compare equal. Numbers are kept as written, so
+// large IDs and amounts are not rounded. Non-JSON bodies are kept as-is.
+func (c *Cassette) redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.Decode(new(interface{})) != io.EOF {
+		return string(body)
+	}
+	out, err := json.Marshal(c.redactValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(out)
+}
+
+func (c *Cassette) redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, field := range val {
+			if c.isRedactedField(key) && field != nil {
+				val[key] = Redacted
+				continue
+			}
+			val[key] = c.redactValue(field)
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = c.redactValue(val[i])
+		}
+		return val
+	case string:
+		if u, err := url.Parse(val); err == nil && u.Scheme != "" && u.RawQuery != "" {
+			q := u.Query()
+			for key := range q {
+				if c.isRedactedField(key) {
+					q.Set(key, Redacted)
+				}
+			}
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+func (c *Cassette) isRedactedField(name string) bool {
+	for _, f := range c.RedactFields {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
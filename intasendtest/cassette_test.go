@@ -0,0 +1,139 @@
+package intasendtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkout.json")
+
+	// Record against the fake server standing in for the sandbox
+	srv := NewServer()
+	client := srv.Client()
+	rec, err := NewCassette(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Transport = client.HTTPClient.Transport
+	client.HTTPClient.Transport = rec
+
+	checkout, err := client.CreateCheckoutLink(&intasend.PaymentRequest{
+		Email:     "jane@example.com",
+		FirstName: "Jane",
+		Amount:    250,
+		APIRef:    "order-42",
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckoutLink failed: %v", err)
+	}
+	if err := srv.Complete(checkout.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetPaymentStatus(checkout.ID); err != nil {
+		t.Fatalf("GetPaymentStatus failed: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"jane@example.com", "Jane", DefaultToken, DefaultPublishableKey} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Cassette leaks %q", secret)
+		}
+	}
+
+	// Replay with the server gone
+	replay, err := NewCassette(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline := intasend.NewClient(DefaultPublishableKey, DefaultToken, true, false)
+	offline.BaseURL = srv.URL
+	offline.APIBaseURL = srv.URL
+	offline.HTTPClient.Transport = replay
+
+	replayed, err := offline.CreateCheckoutLink(&intasend.PaymentRequest{
+		Email:     "someone-else@example.com",
+		FirstName: "Other",
+		Amount:    250,
+		APIRef:    "order-42",
+	})
+	if err != nil {
+		t.Fatalf("Replayed CreateCheckoutLink failed: %v", err)
+	}
+	if replayed.ID != checkout.ID {
+		t.Errorf("Expected replayed ID %s, got %s", checkout.ID, replayed.ID)
+	}
+
+	status, err := offline.GetPaymentStatus(checkout.ID)
+	if err != nil {
+		t.Fatalf("Replayed GetPaymentStatus failed: %v", err)
+	}
+	if !status.IsCompleted() {
+		t.Errorf("Expected replayed status COMPLETE, got %s", status.Invoice.State)
+	}
+
+	if _, err := offline.GetPaymentStatus(checkout.ID); err == nil {
+		t.Error("Expected an error once the recorded interactions are used up")
+	}
+}
+
+func TestCassetteBodyMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.json")
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	checkout, err := client.QuickCheckout("", "a@example.com", 10, intasend.CurrencyKES, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := NewCassette(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Transport = client.HTTPClient.Transport
+	client.HTTPClient.Transport = rec
+	if _, err := client.GetPaymentStatus(checkout.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := NewCassette(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.HTTPClient.Transport = replay
+	if _, err := client.GetPaymentStatus("SOMEOTHER"); err == nil {
+		t.Error("Expected body mismatch to fail replay")
+	}
+
+	replay.Matcher = MatchMethod | MatchPath
+	if _, err := client.GetPaymentStatus("SOMEOTHER"); err != nil {
+		t.Errorf("Expected method/path matching to ignore the body, got %v", err)
+	}
+}
+
+func TestCassetteRedactBody(t *testing.T) {
+	c := &Cassette{RedactFields: []string{"token"}}
+	body := `{"token":"secret","id":12345678901234567890,"amount":100.10,"rate":1e-7}`
+	if got, want := c.redactBody([]byte(body)), `{"amount":100.10,"id":12345678901234567890,"rate":1e-7,"token":"REDACTED"}`; got != want {
+		t.Errorf("Expected numbers kept as written, got %s", got)
+	}
+	if got := c.redactBody([]byte(`{"a":1} trailing`)); got != `{"a":1} trailing` {
+		t.Errorf("Expected a body that is not one JSON value kept as-is, got %s", got)
+	}
+}
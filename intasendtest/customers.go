@@ -0,0 +1,168 @@
+package intasendtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// resolveCustomerLocked returns the customer a collection is for. A
+// customerID must name a stored customer; otherwise the customer is matched
+// by email or phone number, or created from details. The customer's provider
+// is updated to the one being used.
+func (s *Server) resolveCustomerLocked(customerID string, details intasend.Customer, provider string) (intasend.Customer, bool) {
+	var customer *intasend.Customer
+	if customerID != "" {
+		customer = s.customers[customerID]
+		if customer == nil {
+			return intasend.Customer{}, false
+		}
+	} else {
+		customer = s.findCustomerLocked(details.Email, details.PhoneNumber)
+		if customer == nil {
+			customer = s.addCustomerLocked(details)
+		}
+	}
+	customer.Provider = provider
+	return *customer, true
+}
+
+func (s *Server) findCustomerLocked(email, phone string) *intasend.Customer {
+	for _, id := range s.customerOrder {
+		c := s.customers[id]
+		if (email != "" && c.Email == email) || (phone != "" && c.PhoneNumber == phone) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) addCustomerLocked(details intasend.Customer) *intasend.Customer {
+	now := time.Now()
+	customer := details
+	customer.CustomerID = s.nextID("CUS")
+	customer.CreatedAt, customer.UpdatedAt = now, now
+	s.customers[customer.CustomerID] = &customer
+	s.customerOrder = append(s.customerOrder, customer.CustomerID)
+	return &customer
+}
+
+func (s *Server) handleCreateCustomer(w http.ResponseWriter, r *http.Request) {
+	var req intasend.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Email == "" && req.PhoneNumber == "" {
+		writeError(w, http.StatusBadRequest, "email or phone number is required")
+		return
+	}
+
+	s.mu.Lock()
+	if s.findCustomerLocked(req.Email, req.PhoneNumber) != nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "customer with this email or phone number already exists")
+		return
+	}
+	customer := *s.addCustomerLocked(intasend.Customer{
+		Email:       req.Email,
+		PhoneNumber: req.PhoneNumber,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Country:     req.Country,
+		Address:     req.Address,
+		City:        req.City,
+		State:       req.State,
+		ZipCode:     req.ZipCode,
+	})
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, customer)
+}
+
+func (s *Server) handleListCustomers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	search := strings.ToLower(q.Get("search"))
+
+	s.mu.Lock()
+	var customers []intasend.Customer
+	for i := len(s.customerOrder) - 1; i >= 0; i-- {
+		c := s.customers[s.customerOrder[i]]
+		if v := q.Get("email"); v != "" && c.Email != v {
+			continue
+		}
+		if v := q.Get("phone_number"); v != "" && c.PhoneNumber != v {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(c.Email), search) && !strings.Contains(c.PhoneNumber, search) {
+			continue
+		}
+		customers = append(customers, *c)
+	}
+	s.mu.Unlock()
+
+	start, end, next, prev := paginate(r, len(customers))
+	writeJSON(w, http.StatusOK, intasend.PaginatedCustomers{
+		Count:    len(customers),
+		Next:     next,
+		Previous: prev,
+		Results:  nonNil(customers[start:end]),
+	})
+}
+
+func (s *Server) handleGetCustomer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	customer, ok := s.customers[r.PathValue("id")]
+	var resp intasend.Customer
+	if ok {
+		resp = *customer
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "customer not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleUpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	var update intasend.CustomerUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	customer, ok := s.customers[r.PathValue("id")]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "customer not found")
+		return
+	}
+	for _, f := range []struct {
+		src *string
+		dst *string
+	}{
+		{update.Email, &customer.Email},
+		{update.PhoneNumber, &customer.PhoneNumber},
+		{update.FirstName, &customer.FirstName},
+		{update.LastName, &customer.LastName},
+		{update.Country, &customer.Country},
+		{update.Address, &customer.Address},
+		{update.City, &customer.City},
+		{update.State, &customer.State},
+		{update.ZipCode, &customer.ZipCode},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	customer.UpdatedAt = time.Now()
+	resp := *customer
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,378 @@
+package intasendtest
+
+import (
+	"sync"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// Call records a single invocation on a Fake
+type Call struct {
+	Method string
+	Args   []interface{}
+	Opts   []intasend.CallOption // the call options passed, in order
+}
+
+// Settings applies the call's options, e.g. to check the context or an
+// idempotency key the caller passed
+func (c Call) Settings() intasend.CallSettings {
+	return intasend.ResolveCallOptions(c.Opts...)
+}
+
+// Fake is a hand-written implementation of intasend.API for unit tests.
+//
+// Each method delegates to the matching ...Func field when set. Unset
+// methods return an empty response and a nil error. Every invocation is
+// recorded, with its call options, and can be inspected with Calls and
+// CallsTo; the options are not applied.
+type Fake struct {
+	CreateCheckoutLinkFunc      func(req *intasend.PaymentRequest) (*intasend.PaymentResponse, error)
+	SendIntaSendXBPushFunc      func(req *intasend.IntaSendXBPushRequest) (*intasend.IntaSendXBPushResponse, error)
+	GetPaymentStatusFunc        func(invoiceID string) (*intasend.PaymentStatus, error)
+	InitiateSendMoneyFunc       func(req *intasend.SendMoneyRequest) (*intasend.SendMoneyResponse, error)
+	GetSendMoneyStatusFunc      func(trackingID string) (*intasend.SendMoneyResponse, error)
+	ListWalletsFunc             func(params *intasend.ListWalletsParams) (*intasend.PaginatedWallets, error)
+	ListWalletTransactionsFunc  func(walletID string, params *intasend.WalletTransactionsParams) (*intasend.TransactionResp, error)
+	ListTransactionsFunc        func(params *intasend.ListTransactionsParams) (*intasend.TransactionResp, error)
+	GetTransactionFunc          func(transactionID string) (*intasend.Result, error)
+	ListInvoicesFunc            func(params *intasend.ListInvoicesParams) (*intasend.PaginatedInvoices, error)
+	GetInvoiceFunc              func(invoiceID string) (*intasend.InvoiceItem, error)
+	CreatePaymentLinkFunc       func(req *intasend.PaymentLinkRequest) (*intasend.PaymentLink, error)
+	ListPaymentLinksFunc        func(params *intasend.ListPaymentLinksParams) (*intasend.PaginatedPaymentLinks, error)
+	GetPaymentLinkFunc          func(linkID string) (*intasend.PaymentLink, error)
+	UpdatePaymentLinkFunc       func(linkID string, update *intasend.PaymentLinkUpdate) (*intasend.PaymentLink, error)
+	DeactivatePaymentLinkFunc   func(linkID string) (*intasend.PaymentLink, error)
+	CreatePlanFunc              func(req *intasend.PlanRequest) (*intasend.Plan, error)
+	ListPlansFunc               func(params *intasend.ListPlansParams) (*intasend.PaginatedPlans, error)
+	GetPlanFunc                 func(planID string) (*intasend.Plan, error)
+	CreateSubscriptionFunc      func(req *intasend.SubscriptionRequest) (*intasend.Subscription, error)
+	ListSubscriptionsFunc       func(params *intasend.ListSubscriptionsParams) (*intasend.PaginatedSubscriptions, error)
+	GetSubscriptionFunc         func(subscriptionID string) (*intasend.Subscription, error)
+	CancelSubscriptionFunc      func(subscriptionID string, req *intasend.CancelSubscriptionRequest) (*intasend.Subscription, error)
+	PauseSubscriptionFunc       func(subscriptionID string) (*intasend.Subscription, error)
+	ResumeSubscriptionFunc      func(subscriptionID string) (*intasend.Subscription, error)
+	ListSubscriptionChargesFunc func(subscriptionID string, params *intasend.ListChargesParams) (*intasend.PaginatedCharges, error)
+	CreateCustomerFunc          func(req *intasend.CustomerRequest) (*intasend.Customer, error)
+	ListCustomersFunc           func(params *intasend.ListCustomersParams) (*intasend.PaginatedCustomers, error)
+	SearchCustomersFunc         func(query string) (*intasend.PaginatedCustomers, error)
+	GetCustomerFunc             func(customerID string) (*intasend.Customer, error)
+	UpdateCustomerFunc          func(customerID string, update *intasend.CustomerUpdate) (*intasend.Customer, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Ensure Fake satisfies every domain interface
+var _ intasend.API = (*Fake)(nil)
+
+// Calls returns every recorded invocation in order
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallsTo returns the recorded invocations of a single method
+func (f *Fake) CallsTo(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []Call
+	for _, c := range f.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset clears the recorded invocations
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+func (f *Fake) record(method string, opts []intasend.CallOption, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Args: args, Opts: opts})
+}
+
+// CreateCheckoutLink implements intasend.Collections
+func (f *Fake) CreateCheckoutLink(req *intasend.PaymentRequest, opts ...intasend.CallOption) (*intasend.PaymentResponse, error) {
+	f.record("CreateCheckoutLink", opts, req)
+	if f.CreateCheckoutLinkFunc != nil {
+		return f.CreateCheckoutLinkFunc(req)
+	}
+	return &intasend.PaymentResponse{}, nil
+}
+
+// SendIntaSendXBPush implements intasend.Collections
+func (f *Fake) SendIntaSendXBPush(req *intasend.IntaSendXBPushRequest, opts ...intasend.CallOption) (*intasend.IntaSendXBPushResponse, error) {
+	f.record("SendIntaSendXBPush", opts, req)
+	if f.SendIntaSendXBPushFunc != nil {
+		return f.SendIntaSendXBPushFunc(req)
+	}
+	return &intasend.IntaSendXBPushResponse{}, nil
+}
+
+// GetPaymentStatus implements intasend.Collections
+func (f *Fake) GetPaymentStatus(invoiceID string, opts ...intasend.CallOption) (*intasend.PaymentStatus, error) {
+	f.record("GetPaymentStatus", opts, invoiceID)
+	if f.GetPaymentStatusFunc != nil {
+		return f.GetPaymentStatusFunc(invoiceID)
+	}
+	return &intasend.PaymentStatus{}, nil
+}
+
+// InitiateSendMoney implements intasend.Payouts
+func (f *Fake) InitiateSendMoney(req *intasend.SendMoneyRequest, opts ...intasend.CallOption) (*intasend.SendMoneyResponse, error) {
+	f.record("InitiateSendMoney", opts, req)
+	if f.InitiateSendMoneyFunc != nil {
+		return f.InitiateSendMoneyFunc(req)
+	}
+	return &intasend.SendMoneyResponse{}, nil
+}
+
+// GetSendMoneyStatus implements intasend.Payouts
+func (f *Fake) GetSendMoneyStatus(trackingID string, opts ...intasend.CallOption) (*intasend.SendMoneyResponse, error) {
+	f.record("GetSendMoneyStatus", opts, trackingID)
+	if f.GetSendMoneyStatusFunc != nil {
+		return f.GetSendMoneyStatusFunc(trackingID)
+	}
+	return &intasend.SendMoneyResponse{TrackingID: trackingID}, nil
+}
+
+// ListWallets implements intasend.Wallets
+func (f *Fake) ListWallets(params *intasend.ListWalletsParams, opts ...intasend.CallOption) (*intasend.PaginatedWallets, error) {
+	f.record("ListWallets", opts, params)
+	if f.ListWalletsFunc != nil {
+		return f.ListWalletsFunc(params)
+	}
+	return &intasend.PaginatedWallets{}, nil
+}
+
+// ListWalletTransactions implements intasend.Wallets
+func (f *Fake) ListWalletTransactions(walletID string, params *intasend.WalletTransactionsParams, opts ...intasend.CallOption) (*intasend.TransactionResp, error) {
+	f.record("ListWalletTransactions", opts, walletID, params)
+	if f.ListWalletTransactionsFunc != nil {
+		return f.ListWalletTransactionsFunc(walletID, params)
+	}
+	return &intasend.TransactionResp{}, nil
+}
+
+// ListTransactions implements intasend.Transactions
+func (f *Fake) ListTransactions(params *intasend.ListTransactionsParams, opts ...intasend.CallOption) (*intasend.TransactionResp, error) {
+	f.record("ListTransactions", opts, params)
+	if f.ListTransactionsFunc != nil {
+		return f.ListTransactionsFunc(params)
+	}
+	return &intasend.TransactionResp{}, nil
+}
+
+// GetTransaction implements intasend.Transactions
+func (f *Fake) GetTransaction(transactionID string, opts ...intasend.CallOption) (*intasend.Result, error) {
+	f.record("GetTransaction", opts, transactionID)
+	if f.GetTransactionFunc != nil {
+		return f.GetTransactionFunc(transactionID)
+	}
+	return &intasend.Result{}, nil
+}
+
+// ListInvoices implements intasend.Invoices
+func (f *Fake) ListInvoices(params *intasend.ListInvoicesParams, opts ...intasend.CallOption) (*intasend.PaginatedInvoices, error) {
+	f.record("ListInvoices", opts, params)
+	if f.ListInvoicesFunc != nil {
+		return f.ListInvoicesFunc(params)
+	}
+	return &intasend.PaginatedInvoices{}, nil
+}
+
+// GetInvoice implements intasend.Invoices
+func (f *Fake) GetInvoice(invoiceID string, opts ...intasend.CallOption) (*intasend.InvoiceItem, error) {
+	f.record("GetInvoice", opts, invoiceID)
+	if f.GetInvoiceFunc != nil {
+		return f.GetInvoiceFunc(invoiceID)
+	}
+	return &intasend.InvoiceItem{}, nil
+}
+
+// CreatePaymentLink implements intasend.PaymentLinks
+func (f *Fake) CreatePaymentLink(req *intasend.PaymentLinkRequest, opts ...intasend.CallOption) (*intasend.PaymentLink, error) {
+	f.record("CreatePaymentLink", opts, req)
+	if f.CreatePaymentLinkFunc != nil {
+		return f.CreatePaymentLinkFunc(req)
+	}
+	return &intasend.PaymentLink{}, nil
+}
+
+// ListPaymentLinks implements intasend.PaymentLinks
+func (f *Fake) ListPaymentLinks(params *intasend.ListPaymentLinksParams, opts ...intasend.CallOption) (*intasend.PaginatedPaymentLinks, error) {
+	f.record("ListPaymentLinks", opts, params)
+	if f.ListPaymentLinksFunc != nil {
+		return f.ListPaymentLinksFunc(params)
+	}
+	return &intasend.PaginatedPaymentLinks{}, nil
+}
+
+// GetPaymentLink implements intasend.PaymentLinks
+func (f *Fake) GetPaymentLink(linkID string, opts ...intasend.CallOption) (*intasend.PaymentLink, error) {
+	f.record("GetPaymentLink", opts, linkID)
+	if f.GetPaymentLinkFunc != nil {
+		return f.GetPaymentLinkFunc(linkID)
+	}
+	return &intasend.PaymentLink{}, nil
+}
+
+// UpdatePaymentLink implements intasend.PaymentLinks
+func (f *Fake) UpdatePaymentLink(linkID string, update *intasend.PaymentLinkUpdate, opts ...intasend.CallOption) (*intasend.PaymentLink, error) {
+	f.record("UpdatePaymentLink", opts, linkID, update)
+	if f.UpdatePaymentLinkFunc != nil {
+		return f.UpdatePaymentLinkFunc(linkID, update)
+	}
+	return &intasend.PaymentLink{}, nil
+}
+
+// DeactivatePaymentLink implements intasend.PaymentLinks
+func (f *Fake) DeactivatePaymentLink(linkID string, opts ...intasend.CallOption) (*intasend.PaymentLink, error) {
+	f.record("DeactivatePaymentLink", opts, linkID)
+	if f.DeactivatePaymentLinkFunc != nil {
+		return f.DeactivatePaymentLinkFunc(linkID)
+	}
+	return &intasend.PaymentLink{}, nil
+}
+
+// CreatePlan implements intasend.Subscriptions
+func (f *Fake) CreatePlan(req *intasend.PlanRequest, opts ...intasend.CallOption) (*intasend.Plan, error) {
+	f.record("CreatePlan", opts, req)
+	if f.CreatePlanFunc != nil {
+		return f.CreatePlanFunc(req)
+	}
+	return &intasend.Plan{}, nil
+}
+
+// ListPlans implements intasend.Subscriptions
+func (f *Fake) ListPlans(params *intasend.ListPlansParams, opts ...intasend.CallOption) (*intasend.PaginatedPlans, error) {
+	f.record("ListPlans", opts, params)
+	if f.ListPlansFunc != nil {
+		return f.ListPlansFunc(params)
+	}
+	return &intasend.PaginatedPlans{}, nil
+}
+
+// GetPlan implements intasend.Subscriptions
+func (f *Fake) GetPlan(planID string, opts ...intasend.CallOption) (*intasend.Plan, error) {
+	f.record("GetPlan", opts, planID)
+	if f.GetPlanFunc != nil {
+		return f.GetPlanFunc(planID)
+	}
+	return &intasend.Plan{}, nil
+}
+
+// CreateSubscription implements intasend.Subscriptions
+func (f *Fake) CreateSubscription(req *intasend.SubscriptionRequest, opts ...intasend.CallOption) (*intasend.Subscription, error) {
+	f.record("CreateSubscription", opts, req)
+	if f.CreateSubscriptionFunc != nil {
+		return f.CreateSubscriptionFunc(req)
+	}
+	return &intasend.Subscription{}, nil
+}
+
+// ListSubscriptions implements intasend.Subscriptions
+func (f *Fake) ListSubscriptions(params *intasend.ListSubscriptionsParams, opts ...intasend.CallOption) (*intasend.PaginatedSubscriptions, error) {
+	f.record("ListSubscriptions", opts, params)
+	if f.ListSubscriptionsFunc != nil {
+		return f.ListSubscriptionsFunc(params)
+	}
+	return &intasend.PaginatedSubscriptions{}, nil
+}
+
+// GetSubscription implements intasend.Subscriptions
+func (f *Fake) GetSubscription(subscriptionID string, opts ...intasend.CallOption) (*intasend.Subscription, error) {
+	f.record("GetSubscription", opts, subscriptionID)
+	if f.GetSubscriptionFunc != nil {
+		return f.GetSubscriptionFunc(subscriptionID)
+	}
+	return &intasend.Subscription{}, nil
+}
+
+// CancelSubscription implements intasend.Subscriptions
+func (f *Fake) CancelSubscription(subscriptionID string, req *intasend.CancelSubscriptionRequest, opts ...intasend.CallOption) (*intasend.Subscription, error) {
+	f.record("CancelSubscription", opts, subscriptionID, req)
+	if f.CancelSubscriptionFunc != nil {
+		return f.CancelSubscriptionFunc(subscriptionID, req)
+	}
+	return &intasend.Subscription{}, nil
+}
+
+// PauseSubscription implements intasend.Subscriptions
+func (f *Fake) PauseSubscription(subscriptionID string, opts ...intasend.CallOption) (*intasend.Subscription, error) {
+	f.record("PauseSubscription", opts, subscriptionID)
+	if f.PauseSubscriptionFunc != nil {
+		return f.PauseSubscriptionFunc(subscriptionID)
+	}
+	return &intasend.Subscription{}, nil
+}
+
+// ResumeSubscription implements intasend.Subscriptions
+func (f *Fake) ResumeSubscription(subscriptionID string, opts ...intasend.CallOption) (*intasend.Subscription, error) {
+	f.record("ResumeSubscription", opts, subscriptionID)
+	if f.ResumeSubscriptionFunc != nil {
+		return f.ResumeSubscriptionFunc(subscriptionID)
+	}
+	return &intasend.Subscription{}, nil
+}
+
+// ListSubscriptionCharges implements intasend.Subscriptions
+func (f *Fake) ListSubscriptionCharges(subscriptionID string, params *intasend.ListChargesParams, opts ...intasend.CallOption) (*intasend.PaginatedCharges, error) {
+	f.record("ListSubscriptionCharges", opts, subscriptionID, params)
+	if f.ListSubscriptionChargesFunc != nil {
+		return f.ListSubscriptionChargesFunc(subscriptionID, params)
+	}
+	return &intasend.PaginatedCharges{}, nil
+}
+
+// CreateCustomer implements intasend.Customers
+func (f *Fake) CreateCustomer(req *intasend.CustomerRequest, opts ...intasend.CallOption) (*intasend.Customer, error) {
+	f.record("CreateCustomer", opts, req)
+	if f.CreateCustomerFunc != nil {
+		return f.CreateCustomerFunc(req)
+	}
+	return &intasend.Customer{}, nil
+}
+
+// ListCustomers implements intasend.Customers
+func (f *Fake) ListCustomers(params *intasend.ListCustomersParams, opts ...intasend.CallOption) (*intasend.PaginatedCustomers, error) {
+	f.record("ListCustomers", opts, params)
+	if f.ListCustomersFunc != nil {
+		return f.ListCustomersFunc(params)
+	}
+	return &intasend.PaginatedCustomers{}, nil
+}
+
+// SearchCustomers implements intasend.Customers
+func (f *Fake) SearchCustomers(query string, opts ...intasend.CallOption) (*intasend.PaginatedCustomers, error) {
+	f.record("SearchCustomers", opts, query)
+	if f.SearchCustomersFunc != nil {
+		return f.SearchCustomersFunc(query)
+	}
+	return &intasend.PaginatedCustomers{}, nil
+}
+
+// GetCustomer implements intasend.Customers
+func (f *Fake) GetCustomer(customerID string, opts ...intasend.CallOption) (*intasend.Customer, error) {
+	f.record("GetCustomer", opts, customerID)
+	if f.GetCustomerFunc != nil {
+		return f.GetCustomerFunc(customerID)
+	}
+	return &intasend.Customer{}, nil
+}
+
+// UpdateCustomer implements intasend.Customers
+func (f *Fake) UpdateCustomer(customerID string, update *intasend.CustomerUpdate, opts ...intasend.CallOption) (*intasend.Customer, error) {
+	f.record("UpdateCustomer", opts, customerID, update)
+	if f.UpdateCustomerFunc != nil {
+		return f.UpdateCustomerFunc(customerID, update)
+	}
+	return &intasend.Customer{}, nil
+}
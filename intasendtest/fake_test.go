@@ -0,0 +1,32 @@
+package intasendtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+type ctxKey struct{}
+
+func TestFakeRecordsCallOptions(t *testing.T) {
+	fake := &Fake{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "payout-1")
+	req := &intasend.SendMoneyRequest{Currency: intasend.CurrencyKES}
+	if _, err := fake.InitiateSendMoney(req, intasend.WithContext(ctx), intasend.WithIdempotencyKey("batch-7")); err != nil {
+		t.Fatal(err)
+	}
+	fake.GetInvoice("INV1")
+
+	calls := fake.CallsTo("InitiateSendMoney")
+	if len(calls) != 1 || calls[0].Args[0] != req || len(calls[0].Opts) != 2 {
+		t.Fatalf("Unexpected calls: %+v", calls)
+	}
+	settings := calls[0].Settings()
+	if settings.Context.Value(ctxKey{}) != "payout-1" || settings.Headers["Idempotency-Key"] != "batch-7" {
+		t.Errorf("Expected the caller's context and idempotency key, got %+v", settings)
+	}
+	if get := fake.CallsTo("GetInvoice")[0]; get.Opts != nil || get.Settings().Context == nil {
+		t.Errorf("Expected no options and a background context, got %+v", get)
+	}
+}
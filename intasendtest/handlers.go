@@ -0,0 +1,775 @@
+package intasendtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// routes registers the handlers for every endpoint the fake understands
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/checkout/", s.publicKey(s.handleCheckout))
+	mux.HandleFunc("POST /api/v1/payment/status/", s.publicKey(s.handleStatus))
+	mux.HandleFunc("POST /api/v1/payment/intasend-xb-push/", s.bearer(s.handleXBPush))
+	mux.HandleFunc("POST /api/v1/send-money/initiate/", s.bearer(s.handleSendMoney))
+	mux.HandleFunc("POST /api/v1/send-money/status/", s.bearer(s.handleSendMoneyStatus))
+	mux.HandleFunc("GET /api/v1/wallets/", s.bearer(s.handleListWallets))
+	mux.HandleFunc("GET /api/v1/wallets/{id}/transactions/", s.bearer(s.handleWalletTransactions))
+	mux.HandleFunc("GET /api/v1/transactions/", s.bearer(s.handleListTransactions))
+	mux.HandleFunc("GET /api/v1/transactions/{id}/", s.bearer(s.handleGetTransaction))
+	mux.HandleFunc("GET /api/v1/invoices/", s.bearer(s.handleListInvoices))
+	mux.HandleFunc("GET /api/v1/invoices/{id}/", s.bearer(s.handleGetInvoice))
+	mux.HandleFunc("POST /api/v1/paymentlinks/", s.bearer(s.handleCreatePaymentLink))
+	mux.HandleFunc("GET /api/v1/paymentlinks/", s.bearer(s.handleListPaymentLinks))
+	mux.HandleFunc("GET /api/v1/paymentlinks/{id}/", s.bearer(s.handleGetPaymentLink))
+	mux.HandleFunc("PATCH /api/v1/paymentlinks/{id}/", s.bearer(s.handleUpdatePaymentLink))
+	mux.HandleFunc("POST /api/v1/subscriptions/plans/{$}", s.bearer(s.handleCreatePlan))
+	mux.HandleFunc("GET /api/v1/subscriptions/plans/{$}", s.bearer(s.handleListPlans))
+	mux.HandleFunc("GET /api/v1/subscriptions/plans/{id}/", s.bearer(s.handleGetPlan))
+	mux.HandleFunc("POST /api/v1/subscriptions/", s.bearer(s.handleCreateSubscription))
+	mux.HandleFunc("GET /api/v1/subscriptions/", s.bearer(s.handleListSubscriptions))
+	mux.HandleFunc("GET /api/v1/subscriptions/{id}/", s.bearer(s.handleGetSubscription))
+	mux.HandleFunc("POST /api/v1/subscriptions/{id}/{action}/", s.bearer(s.handleSubscriptionAction))
+	// plans/{id}/ and {id}/charges/ would both match plans/charges/, so charges
+	// match a wildcard and plans/ is anchored to keep clear of {id}/{action}/
+	mux.HandleFunc("GET /api/v1/subscriptions/{id}/{collection}/", s.bearer(s.handleListCharges))
+	mux.HandleFunc("POST /api/v1/customers/", s.bearer(s.handleCreateCustomer))
+	mux.HandleFunc("GET /api/v1/customers/", s.bearer(s.handleListCustomers))
+	mux.HandleFunc("GET /api/v1/customers/{id}/", s.bearer(s.handleGetCustomer))
+	mux.HandleFunc("PATCH /api/v1/customers/{id}/", s.bearer(s.handleUpdateCustomer))
+	return s.scripted(mux)
+}
+
+// scripted applies configured latency and faults before dispatching
+func (s *Server) scripted(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		w.Header().Set("X-Request-ID", s.nextID("REQ"))
+		var delay time.Duration
+		for prefix, d := range s.latency {
+			if strings.HasPrefix(r.URL.Path, prefix) && d > delay {
+				delay = d
+			}
+		}
+		var fault *Fault
+		for i, f := range s.faults {
+			if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+				continue
+			}
+			if !strings.HasPrefix(r.URL.Path, f.Path) {
+				continue
+			}
+			copied := *f
+			fault = &copied
+			f.Times--
+			if f.Times <= 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+			break
+		}
+		s.mu.Unlock()
+
+		if fault != nil {
+			delay += fault.Latency
+		}
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if fault == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if fault.Drop {
+			if hj, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hj.Hijack(); err == nil {
+					conn.Close()
+					return
+				}
+			}
+			panic(http.ErrAbortHandler)
+		}
+		if fault.Body == "" {
+			writeError(w, fault.Status, http.StatusText(fault.Status))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(fault.Status)
+		w.Write([]byte(fault.Body))
+	})
+}
+
+// bearer rejects requests without the expected secret token
+func (s *Server) bearer(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		want := "Bearer " + s.token
+		s.mu.Unlock()
+		if r.Header.Get("Authorization") != want {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Invalid token."})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// publicKey rejects requests without the expected publishable key
+func (s *Server) publicKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		want := s.publishableKey
+		s.mu.Unlock()
+		if r.Header.Get("X-IntaSend-Public-API-Key") != want {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Invalid public key."})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleCheckout(w http.ResponseWriter, r *http.Request) {
+	var req intasend.PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Amount <= 0 {
+		writeError(w, http.StatusBadRequest, "amount must be greater than zero")
+		return
+	}
+
+	provider := intasend.ProviderMPESA
+	if req.Method == intasend.MethodCard {
+		provider = intasend.ProviderCard
+	}
+	s.mu.Lock()
+	customer, ok := s.resolveCustomerLocked(req.CustomerID, intasend.Customer{
+		PhoneNumber: req.PhoneNumber,
+		Email:       req.Email,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Country:     req.Country,
+		Address:     req.Address,
+		City:        req.City,
+		State:       req.State,
+		ZipCode:     req.ZipCode,
+	}, provider)
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "customer not found")
+		return
+	}
+	account := customer.Email
+	if account == "" {
+		account = customer.PhoneNumber
+	}
+	rec := s.newInvoiceLocked(provider, string(req.Currency), account, req.APIRef, req.Amount, "")
+	rec.customer = customer
+	inv := rec.invoice
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, intasend.PaymentResponse{
+		ID:           inv.InvoiceID,
+		URL:          fmt.Sprintf("%s/checkout/%s/express/", s.URL, inv.InvoiceID),
+		Signature:    "intasendtest",
+		TrackingID:   inv.InvoiceID,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Email:        req.Email,
+		APIRef:       req.APIRef,
+		Method:       string(req.Method),
+		Host:         s.URL,
+		RedirectURL:  req.RedirectURL,
+		Amount:       req.Amount,
+		Currency:     string(req.Currency),
+		MobileTarrif: string(req.MobileTarrif),
+		CardTarrif:   string(req.CardTarrif),
+		CreatedAt:    inv.CreatedAt,
+		UpdatedAt:    inv.UpdatedAt,
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		InvoiceID string `json:"invoice_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	rec, ok := s.invoices[req.InvoiceID]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "invoice not found")
+		return
+	}
+	changed := s.autoAdvance && s.advanceLocked(rec)
+	inv, customer := rec.invoice, rec.customer
+	cb := s.callbackLocked(rec)
+	s.mu.Unlock()
+
+	if changed {
+		s.deliver(cb)
+	}
+
+	writeJSON(w, http.StatusOK, intasend.PaymentStatus{
+		Invoice: intasend.Invoice{
+			ID:           inv.InvoiceID,
+			InvoiceID:    inv.InvoiceID,
+			State:        inv.State,
+			Provider:     inv.Provider,
+			Charges:      inv.Charges,
+			NetAmount:    inv.NetAmount,
+			Currency:     inv.Currency,
+			Value:        inv.Value,
+			Account:      inv.Account,
+			APIRef:       inv.APIRef,
+			Host:         inv.Host,
+			FailedReason: inv.FailedReason,
+			CreatedAt:    inv.CreatedAt.Format(time.RFC3339Nano),
+			UpdatedAt:    inv.UpdatedAt.Format(time.RFC3339Nano),
+		},
+		Meta: intasend.Meta{
+			ID:        inv.InvoiceID,
+			Customer:  customer,
+			CreatedAt: inv.CreatedAt.Format(time.RFC3339Nano),
+			UpdatedAt: inv.UpdatedAt.Format(time.RFC3339Nano),
+		},
+	})
+}
+
+func (s *Server) handleXBPush(w http.ResponseWriter, r *http.Request) {
+	var req intasend.IntaSendXBPushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	amount, err := strconv.ParseFloat(req.Amount, 64)
+	if err != nil || amount <= 0 {
+		writeError(w, http.StatusBadRequest, "amount must be a positive number")
+		return
+	}
+	if req.WalletID != "" {
+		s.mu.Lock()
+		wallet := s.walletLocked(req.WalletID, "")
+		s.mu.Unlock()
+		if wallet == nil {
+			writeError(w, http.StatusBadRequest, "wallet not found")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	customer, ok := s.resolveCustomerLocked(req.CustomerID, intasend.Customer{PhoneNumber: req.PhoneNumber}, "INTASEND-XB-PUSH")
+	if !ok || customer.PhoneNumber == "" {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "customer with a phone number not found")
+		return
+	}
+	rec := s.newInvoiceLocked("INTASEND-XB-PUSH", string(req.Currency), customer.PhoneNumber, req.APIRef, amount, req.WalletID)
+	rec.customer = customer
+	inv := rec.invoice
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, intasend.IntaSendXBPushResponse{
+		ID:        inv.InvoiceID,
+		Invoice:   invoiceTx(inv),
+		Customer:  customer,
+		CreatedAt: inv.CreatedAt,
+		UpdatedAt: inv.UpdatedAt,
+	})
+}
+
+func (s *Server) handleSendMoney(w http.ResponseWriter, r *http.Request) {
+	var req intasend.SendMoneyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if len(req.Transactions) == 0 {
+		writeError(w, http.StatusBadRequest, "transactions are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A repeated Idempotency-Key returns the batch it created instead of a new one
+	key := r.Header.Get("Idempotency-Key")
+	if trackingID, ok := s.payoutKeys[key]; ok && key != "" {
+		writeJSON(w, http.StatusOK, s.payouts[trackingID].resp)
+		return
+	}
+
+	wallet := s.walletLocked(req.WalletID, string(req.Currency))
+	if wallet == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("no %s wallet available", req.Currency))
+		return
+	}
+
+	now := time.Now()
+	resp := intasend.SendMoneyResponse{
+		FileID:            s.nextID("FILE"),
+		TrackingID:        s.nextID("TRK"),
+		BatchReference:    req.BatchReference,
+		Status:            intasend.StatusPending,
+		StatusCode:        "BP101",
+		Nonce:             s.nextID("N"),
+		Wallet:            *wallet,
+		TransactionsCount: len(req.Transactions),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	for i, tx := range req.Transactions {
+		amount, err := strconv.ParseFloat(tx.Amount, 64)
+		if err != nil || amount <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("transactions[%d]: amount must be a positive number", i))
+			return
+		}
+		resp.TotalAmount += amount
+		resp.Transactions = append(resp.Transactions, intasend.SendMoneyTransactionStatus{
+			TransactionID:      s.nextID("STX"),
+			Status:             intasend.StatusPending,
+			StatusCode:         "TP101",
+			StatusDescription:  "New transaction",
+			RequestReferenceID: s.nextID("REQ"),
+			Name:               tx.Name,
+			Account:            tx.Account,
+			Amount:             amount,
+			Narrative:          tx.Narrative,
+		})
+	}
+	resp.TotalAmountEstimate = resp.TotalAmount
+	s.payouts[resp.TrackingID] = &payoutRecord{resp: resp, outcome: intasend.StatusComplete}
+	if key != "" {
+		s.payoutKeys[key] = resp.TrackingID
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleSendMoneyStatus(w http.ResponseWriter, r *http.Request) {
+	var req intasend.SendMoneyStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TrackingID == "" {
+		writeError(w, http.StatusBadRequest, "tracking_id is required")
+		return
+	}
+	s.mu.Lock()
+	rec, ok := s.payouts[req.TrackingID]
+	var resp intasend.SendMoneyResponse
+	if ok {
+		if s.autoAdvance {
+			s.advancePayoutLocked(rec)
+		}
+		resp = rec.resp
+		resp.Transactions = append([]intasend.SendMoneyTransactionStatus(nil), rec.resp.Transactions...)
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "payout not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleListWallets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var wallets []intasend.WalletResp
+	for _, wallet := range s.wallets {
+		if c := q.Get("currency"); c != "" && wallet.Currency != c {
+			continue
+		}
+		if t := q.Get("wallet_type"); t != "" && wallet.WalletType != t {
+			continue
+		}
+		if l := q.Get("label"); l != "" && wallet.Label != l {
+			continue
+		}
+		if cd := q.Get("can_disburse"); cd != "" && strconv.FormatBool(wallet.CanDisburse) != cd {
+			continue
+		}
+		if !updatedIn(wallet.UpdatedAt, q.Get("updated_at")) {
+			continue
+		}
+		wallets = append(wallets, *wallet)
+	}
+	s.mu.Unlock()
+
+	start, end, next, prev := paginate(r, len(wallets))
+	writeJSON(w, http.StatusOK, intasend.PaginatedWallets{
+		Count:    len(wallets),
+		Next:     next,
+		Previous: prev,
+		Results:  nonNil(wallets[start:end]),
+	})
+}
+
+func (s *Server) handleWalletTransactions(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	s.mu.Lock()
+	if s.walletLocked(walletID, "") == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "wallet not found")
+		return
+	}
+	txns := s.transactionsLocked(func(rec txRecord) bool { return rec.walletID == walletID })
+	s.mu.Unlock()
+
+	writeTransactions(w, r, txns)
+}
+
+func (s *Server) handleListTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	txns := s.transactionsLocked(func(rec txRecord) bool {
+		tx := rec.tx
+		if v := q.Get("wallet_id"); v != "" && rec.walletID != v {
+			return false
+		}
+		if v := q.Get("currency"); v != "" && tx.Currency != v {
+			return false
+		}
+		if v := q.Get("trans_type"); v != "" && tx.TransType != v {
+			return false
+		}
+		if v := q.Get("status"); v != "" && tx.Status != v {
+			return false
+		}
+		day := tx.CreatedAt.Format("2006-01-02")
+		if v := q.Get("date"); v != "" && day != v {
+			return false
+		}
+		if v := q.Get("date_from"); v != "" && day < v {
+			return false
+		}
+		if v := q.Get("date_to"); v != "" && day > v {
+			return false
+		}
+		return updatedIn(tx.UpdatedAt, q.Get("updated_at"))
+	})
+	s.mu.Unlock()
+
+	writeTransactions(w, r, txns)
+}
+
+func (s *Server) handleGetTransaction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rec := range s.transactions {
+		if rec.tx.TransactionID == id {
+			writeJSON(w, http.StatusOK, rec.tx)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "transaction not found")
+}
+
+func (s *Server) handleListInvoices(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var invoices []intasend.InvoiceItem
+	for i := len(s.invoiceOrder) - 1; i >= 0; i-- {
+		inv := s.invoices[s.invoiceOrder[i]].invoice
+		if v := q.Get("state"); v != "" && inv.State != v {
+			continue
+		}
+		if v := q.Get("currency"); v != "" && inv.Currency != v {
+			continue
+		}
+		if v := q.Get("api_ref"); v != "" && inv.APIRef != v {
+			continue
+		}
+		if !updatedIn(inv.UpdatedAt, q.Get("updated_at")) {
+			continue
+		}
+		invoices = append(invoices, inv)
+	}
+	s.mu.Unlock()
+
+	start, end, next, prev := paginate(r, len(invoices))
+	writeJSON(w, http.StatusOK, intasend.PaginatedInvoices{
+		Count:    len(invoices),
+		Next:     next,
+		Previous: prev,
+		Results:  nonNil(invoices[start:end]),
+	})
+}
+
+func (s *Server) handleGetInvoice(w http.ResponseWriter, r *http.Request) {
+	inv, ok := s.Invoice(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "invoice not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, inv)
+}
+
+func (s *Server) handleCreatePaymentLink(w http.ResponseWriter, r *http.Request) {
+	var req intasend.PaymentLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Title == "" || req.Currency == "" {
+		writeError(w, http.StatusBadRequest, "title and currency are required")
+		return
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	link := &intasend.PaymentLink{
+		LinkID:       s.nextID("LNK"),
+		Title:        req.Title,
+		Currency:     string(req.Currency),
+		Amount:       req.Amount,
+		Description:  req.Description,
+		RedirectURL:  req.RedirectURL,
+		MobileTarrif: string(req.MobileTarrif),
+		CardTarrif:   string(req.CardTarrif),
+		IsActive:     true,
+		ExpiresAt:    req.ExpiresAt,
+		Styles:       req.Styles,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	link.URL = fmt.Sprintf("%s/pay/%s/", s.URL, link.LinkID)
+	s.paymentLinks[link.LinkID] = link
+	s.linkOrder = append(s.linkOrder, link.LinkID)
+	resp := *link
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) handleListPaymentLinks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var links []intasend.PaymentLink
+	for i := len(s.linkOrder) - 1; i >= 0; i-- {
+		link := s.paymentLinks[s.linkOrder[i]]
+		if v := q.Get("currency"); v != "" && link.Currency != v {
+			continue
+		}
+		if v := q.Get("is_active"); v != "" && strconv.FormatBool(link.IsActive) != v {
+			continue
+		}
+		links = append(links, *link)
+	}
+	s.mu.Unlock()
+
+	start, end, next, prev := paginate(r, len(links))
+	writeJSON(w, http.StatusOK, intasend.PaginatedPaymentLinks{
+		Count:    len(links),
+		Next:     next,
+		Previous: prev,
+		Results:  nonNil(links[start:end]),
+	})
+}
+
+func (s *Server) handleGetPaymentLink(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	link, ok := s.paymentLinks[r.PathValue("id")]
+	var resp intasend.PaymentLink
+	if ok {
+		resp = *link
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "payment link not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleUpdatePaymentLink(w http.ResponseWriter, r *http.Request) {
+	var update intasend.PaymentLinkUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	link, ok := s.paymentLinks[r.PathValue("id")]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "payment link not found")
+		return
+	}
+	if update.Title != nil {
+		link.Title = *update.Title
+	}
+	if update.Amount != nil {
+		link.Amount = *update.Amount
+	}
+	if update.Description != nil {
+		link.Description = *update.Description
+	}
+	if update.RedirectURL != nil {
+		link.RedirectURL = *update.RedirectURL
+	}
+	if update.MobileTarrif != nil {
+		link.MobileTarrif = string(*update.MobileTarrif)
+	}
+	if update.CardTarrif != nil {
+		link.CardTarrif = string(*update.CardTarrif)
+	}
+	if update.ExpiresAt != nil {
+		link.ExpiresAt = update.ExpiresAt
+	}
+	if update.IsActive != nil {
+		link.IsActive = *update.IsActive
+	}
+	if update.Styles != nil {
+		link.Styles = update.Styles
+	}
+	link.UpdatedAt = time.Now()
+	resp := *link
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// newInvoiceLocked creates a PENDING invoice that will complete when advanced
+func (s *Server) newInvoiceLocked(provider, currency, account, apiRef string, amount float64, walletID string) *invoiceRecord {
+	now := time.Now()
+	charges := math.Round(amount*3.5) / 100 // flat 3.5% fee
+	rec := &invoiceRecord{
+		invoice: intasend.InvoiceItem{
+			InvoiceID: s.nextID("INV"),
+			State:     intasend.StatusPending,
+			Provider:  provider,
+			Charges:   charges,
+			NetAmount: fmt.Sprintf("%.2f", amount-charges),
+			Currency:  currency,
+			Value:     amount,
+			Account:   account,
+			APIRef:    apiRef,
+			Host:      s.URL,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		walletID: walletID,
+		outcome:  intasend.StatusComplete,
+	}
+	s.invoices[rec.invoice.InvoiceID] = rec
+	s.invoiceOrder = append(s.invoiceOrder, rec.invoice.InvoiceID)
+	return rec
+}
+
+// transactionsLocked returns matching transactions, newest first
+func (s *Server) transactionsLocked(match func(txRecord) bool) []intasend.Result {
+	var txns []intasend.Result
+	for _, rec := range s.transactions {
+		if match(rec) {
+			txns = append(txns, rec.tx)
+		}
+	}
+	for i, j := 0, len(txns)-1; i < j; i, j = i+1, j-1 {
+		txns[i], txns[j] = txns[j], txns[i]
+	}
+	return txns
+}
+
+// apiZone is the calendar the updated_at filters are evaluated in
+var apiZone = time.FixedZone("EAT", 3*60*60)
+
+// updatedIn reports whether t falls in an updated_at period. Weeks start on
+// Monday; an empty period matches everything.
+func updatedIn(t time.Time, period string) bool {
+	now := time.Now().In(apiZone)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, apiZone)
+	switch period {
+	case "":
+		return true
+	case "today":
+		return !t.Before(today)
+	case "yesterday":
+		return !t.Before(today.AddDate(0, 0, -1)) && t.Before(today)
+	case "week":
+		return !t.Before(today.AddDate(0, 0, -(int(now.Weekday())+6)%7))
+	case "month":
+		return !t.Before(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, apiZone))
+	case "year":
+		return !t.Before(time.Date(now.Year(), 1, 1, 0, 0, 0, 0, apiZone))
+	}
+	return false
+}
+
+func writeTransactions(w http.ResponseWriter, r *http.Request, txns []intasend.Result) {
+	start, end, next, prev := paginate(r, len(txns))
+	var nextVal, prevVal interface{}
+	if next != nil {
+		nextVal = *next
+	}
+	if prev != nil {
+		prevVal = *prev
+	}
+	writeJSON(w, http.StatusOK, intasend.TransactionResp{
+		Count:    int64(len(txns)),
+		Next:     nextVal,
+		Previous: prevVal,
+		Results:  nonNil(txns[start:end]),
+	})
+}
+
+// paginate resolves the page window and next/previous links for a list response
+func paginate(r *http.Request, total int) (start, end int, next, prev *string) {
+	q := r.URL.Query()
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(q.Get("page_size"))
+	if err != nil || size < 1 {
+		size = defaultPageSize
+	}
+
+	start = (page - 1) * size
+	if start > total {
+		start = total
+	}
+	end = start + size
+	if end > total {
+		end = total
+	}
+
+	link := func(p int) *string {
+		u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+		lq := r.URL.Query()
+		lq.Set("page", strconv.Itoa(p))
+		u.RawQuery = lq.Encode()
+		s := u.String()
+		return &s
+	}
+	if end < total {
+		next = link(page + 1)
+	}
+	if page > 1 {
+		prev = link(page - 1)
+	}
+	return start, end, next, prev
+}
+
+// nonNil makes empty pages encode as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,521 @@
+// Package intasendtest provides an in-memory IntaSend API for integration tests.
+//
+// Server wraps an httptest.Server that understands the endpoints used by the
+// SDK (checkout, payment status, IntaSend-XB push, send-money, wallets,
+// transactions, invoices, payment links, subscriptions and customers).
+// Invoices and payouts move through the same PENDING -> PROCESSING ->
+// COMPLETE/FAILED lifecycle as the real API, faults and latency can be
+// scripted per route, and state changes can be delivered as
+// CollectionCallback webhooks to a configured URL.
+package intasendtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// Default credentials accepted by a Server
+const (
+	DefaultPublishableKey = "ISPubKey_test_intasendtest"
+	DefaultToken          = "ISSecretKey_test_intasendtest"
+)
+
+// defaultPageSize mirrors the page size used by the IntaSend list endpoints
+const defaultPageSize = 10
+
+// Fault describes a scripted failure for requests matching Method and Path
+type Fault struct {
+	Method  string        // optional, matches any method when empty
+	Path    string        // optional path prefix, matches any path when empty
+	Status  int           // HTTP status to return (defaults to 500)
+	Body    string        // response body (defaults to a JSON error message)
+	Latency time.Duration // optional delay before responding
+	Drop    bool          // close the connection without writing a response
+	Times   int           // number of requests affected; <= 0 means one
+}
+
+// WebhookDelivery records a callback sent by the server
+type WebhookDelivery struct {
+	Callback   intasend.CollectionCallback
+	StatusCode int
+	Err        error
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithCredentials overrides the publishable key and token accepted by the server
+func WithCredentials(publishableKey, token string) Option {
+	return func(s *Server) {
+		s.publishableKey = publishableKey
+		s.token = token
+	}
+}
+
+// WithCallbackURL enables CollectionCallback webhooks for invoice state changes
+func WithCallbackURL(callbackURL, challenge string) Option {
+	return func(s *Server) {
+		s.callbackURL = callbackURL
+		s.challenge = challenge
+	}
+}
+
+// WithAutoAdvance makes every payment or send-money status poll move the
+// invoice or batch one step through its lifecycle
+func WithAutoAdvance() Option {
+	return func(s *Server) {
+		s.autoAdvance = true
+	}
+}
+
+// WithWallets replaces the default wallets held by the server
+func WithWallets(wallets ...intasend.WalletResp) Option {
+	return func(s *Server) {
+		s.wallets = nil
+		for i := range wallets {
+			w := wallets[i]
+			s.wallets = append(s.wallets, &w)
+		}
+	}
+}
+
+// Server is a fake IntaSend API backed by in-memory state
+type Server struct {
+	URL string
+
+	srv *httptest.Server
+
+	mu             sync.Mutex
+	publishableKey string
+	token          string
+	callbackURL    string
+	challenge      string
+	autoAdvance    bool
+	seq            int
+	invoices       map[string]*invoiceRecord
+	invoiceOrder   []string
+	payouts        map[string]*payoutRecord
+	payoutKeys     map[string]string // Idempotency-Key -> tracking ID
+	paymentLinks   map[string]*intasend.PaymentLink
+	linkOrder      []string
+	plans          map[string]*intasend.Plan
+	planOrder      []string
+	subscriptions  map[string]*subscriptionRecord
+	subOrder       []string
+	customers      map[string]*intasend.Customer
+	customerOrder  []string
+	wallets        []*intasend.WalletResp
+	transactions   []txRecord
+	faults         []*Fault
+	latency        map[string]time.Duration
+	deliveries     []WebhookDelivery
+	webhookClient  *http.Client
+}
+
+type invoiceRecord struct {
+	invoice  intasend.InvoiceItem
+	customer intasend.Customer
+	walletID string
+	outcome  string
+	reason   string
+}
+
+type txRecord struct {
+	walletID string
+	tx       intasend.Result
+}
+
+type payoutRecord struct {
+	resp    intasend.SendMoneyResponse
+	outcome string
+}
+
+// NewServer starts a fake IntaSend API. Call Close when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		publishableKey: DefaultPublishableKey,
+		token:          DefaultToken,
+		invoices:       make(map[string]*invoiceRecord),
+		payouts:        make(map[string]*payoutRecord),
+		payoutKeys:     make(map[string]string),
+		paymentLinks:   make(map[string]*intasend.PaymentLink),
+		customers:      make(map[string]*intasend.Customer),
+		plans:          make(map[string]*intasend.Plan),
+		subscriptions:  make(map[string]*subscriptionRecord),
+		latency:        make(map[string]time.Duration),
+		webhookClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+	s.wallets = []*intasend.WalletResp{
+		{WalletID: "WKES001", Label: "default", CanDisburse: true, Currency: string(intasend.CurrencyKES), WalletType: string(intasend.WalletTypeFilterSettlement)},
+		{WalletID: "WUGX001", Label: "default", CanDisburse: true, Currency: string(intasend.CurrencyUGX), WalletType: string(intasend.WalletTypeFilterSettlement)},
+		{WalletID: "WTZS001", Label: "default", CanDisburse: true, Currency: string(intasend.CurrencyTZS), WalletType: string(intasend.WalletTypeFilterSettlement)},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.srv = httptest.NewServer(s.routes())
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns an SDK client configured to talk to this server
+func (s *Server) Client() *intasend.Client {
+	s.mu.Lock()
+	publishableKey, token := s.publishableKey, s.token
+	s.mu.Unlock()
+
+	client := intasend.NewClient(publishableKey, token, true, false)
+	client.BaseURL = s.URL
+	client.APIBaseURL = s.URL
+	client.HTTPClient = s.srv.Client()
+	return client
+}
+
+// InjectFault scripts a failure for matching requests
+func (s *Server) InjectFault(f Fault) {
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	if f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// SetLatency delays every response whose path starts with pathPrefix
+func (s *Server) SetLatency(pathPrefix string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency[pathPrefix] = d
+}
+
+// SetOutcome decides the final state an invoice reaches when advanced
+// (intasend.StatusComplete or intasend.StatusFailed)
+func (s *Server) SetOutcome(invoiceID, state, failedReason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.invoices[invoiceID]
+	if !ok {
+		return fmt.Errorf("invoice %s not found", invoiceID)
+	}
+	rec.outcome = state
+	rec.reason = failedReason
+	return nil
+}
+
+// Invoice returns a copy of the current state of an invoice
+func (s *Server) Invoice(invoiceID string) (intasend.InvoiceItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.invoices[invoiceID]
+	if !ok {
+		return intasend.InvoiceItem{}, false
+	}
+	return rec.invoice, true
+}
+
+// Deliveries returns the webhooks sent so far
+func (s *Server) Deliveries() []WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]WebhookDelivery(nil), s.deliveries...)
+}
+
+// Advance moves an invoice one step through its lifecycle and returns the new state
+func (s *Server) Advance(invoiceID string) (string, error) {
+	s.mu.Lock()
+	rec, ok := s.invoices[invoiceID]
+	if !ok {
+		s.mu.Unlock()
+		return "", fmt.Errorf("invoice %s not found", invoiceID)
+	}
+	changed := s.advanceLocked(rec)
+	state := rec.invoice.State
+	cb := s.callbackLocked(rec)
+	s.mu.Unlock()
+
+	if changed {
+		s.deliver(cb)
+	}
+	return state, nil
+}
+
+// Complete drives an invoice to COMPLETE
+func (s *Server) Complete(invoiceID string) error {
+	return s.settle(invoiceID, intasend.StatusComplete, "")
+}
+
+// Fail drives an invoice to FAILED with the given reason
+func (s *Server) Fail(invoiceID, reason string) error {
+	return s.settle(invoiceID, intasend.StatusFailed, reason)
+}
+
+// maxSettleSteps bounds settle; an invoice is final after two steps
+const maxSettleSteps = 10
+
+func (s *Server) settle(invoiceID, outcome, reason string) error {
+	if err := s.SetOutcome(invoiceID, outcome, reason); err != nil {
+		return err
+	}
+	var state string
+	for range maxSettleSteps {
+		var err error
+		if state, err = s.Advance(invoiceID); err != nil {
+			return err
+		}
+		if isFinal(state) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invoice %s did not settle after %d steps, still %s", invoiceID, maxSettleSteps, state)
+}
+
+// AdvancePayout moves a send-money batch one step through its lifecycle
+func (s *Server) AdvancePayout(trackingID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.payouts[trackingID]
+	if !ok {
+		return "", fmt.Errorf("payout %s not found", trackingID)
+	}
+	s.advancePayoutLocked(rec)
+	return rec.resp.Status, nil
+}
+
+// advancePayoutLocked moves the batch one step and reports whether it changed
+func (s *Server) advancePayoutLocked(rec *payoutRecord) bool {
+	switch rec.resp.Status {
+	case intasend.StatusPending:
+		rec.resp.Status = intasend.StatusProcessing
+	case intasend.StatusProcessing:
+		rec.resp.Status = rec.outcome
+		if rec.outcome == intasend.StatusComplete {
+			s.debitPayoutLocked(rec)
+		}
+		for i := range rec.resp.Transactions {
+			rec.resp.Transactions[i].Status = rec.outcome
+		}
+	default:
+		return false
+	}
+	rec.resp.UpdatedAt = time.Now()
+	return true
+}
+
+// SetPayoutOutcome decides the final state a send-money batch reaches when advanced
+func (s *Server) SetPayoutOutcome(trackingID, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.payouts[trackingID]
+	if !ok {
+		return fmt.Errorf("payout %s not found", trackingID)
+	}
+	rec.outcome = state
+	return nil
+}
+
+func isFinal(state string) bool {
+	return state == intasend.StatusComplete || state == intasend.StatusFailed || state == intasend.StatusCancelled
+}
+
+// advanceLocked moves the invoice one step and reports whether it changed
+func (s *Server) advanceLocked(rec *invoiceRecord) bool {
+	switch rec.invoice.State {
+	case intasend.StatusPending:
+		rec.invoice.State = intasend.StatusProcessing
+	case intasend.StatusProcessing:
+		rec.invoice.State = rec.outcome
+		if rec.outcome == intasend.StatusFailed {
+			reason := rec.reason
+			if reason == "" {
+				reason = "Request cancelled by user"
+			}
+			rec.invoice.FailedReason = &reason
+		} else {
+			rec.invoice.ClearingStatus = "AVAILABLE"
+			s.creditInvoiceLocked(rec)
+		}
+	default:
+		return false
+	}
+	rec.invoice.UpdatedAt = time.Now()
+	return true
+}
+
+// creditInvoiceLocked records the deposit for a completed invoice in its wallet
+func (s *Server) creditInvoiceLocked(rec *invoiceRecord) {
+	wallet := s.walletLocked(rec.walletID, rec.invoice.Currency)
+	if wallet == nil {
+		return
+	}
+	net, _ := strconv.ParseFloat(rec.invoice.NetAmount, 64)
+	wallet.CurrentBalance += net
+	wallet.AvailableBalance += net
+	wallet.UpdatedAt = time.Now()
+
+	tx := invoiceTx(rec.invoice)
+	s.recordTxLocked(wallet, intasend.Result{
+		Invoice:   &tx,
+		Value:     net,
+		Narrative: "Payment received",
+		TransType: intasend.TransTypeDeposit,
+	})
+}
+
+// debitPayoutLocked records the withdrawal for a completed send-money batch
+func (s *Server) debitPayoutLocked(rec *payoutRecord) {
+	wallet := s.walletLocked(rec.resp.Wallet.WalletID, rec.resp.Wallet.Currency)
+	if wallet == nil {
+		return
+	}
+	wallet.CurrentBalance -= rec.resp.TotalAmount
+	wallet.AvailableBalance -= rec.resp.TotalAmount
+	wallet.UpdatedAt = time.Now()
+	rec.resp.Wallet = *wallet
+
+	s.recordTxLocked(wallet, intasend.Result{
+		Value:     -rec.resp.TotalAmount,
+		Narrative: "Send money " + rec.resp.TrackingID,
+		TransType: intasend.TransTypeWithdrawal,
+	})
+}
+
+// recordTxLocked appends a completed wallet transaction using the wallet's
+// current balance as the running balance
+func (s *Server) recordTxLocked(wallet *intasend.WalletResp, tx intasend.Result) {
+	now := time.Now()
+	tx.TransactionID = s.nextID("TXN")
+	tx.Currency = wallet.Currency
+	tx.RunningBalance = wallet.CurrentBalance
+	tx.Status = intasend.TransStatusCompleted
+	tx.CreatedAt = now
+	tx.UpdatedAt = now
+	s.transactions = append(s.transactions, txRecord{walletID: wallet.WalletID, tx: tx})
+}
+
+// walletLocked finds a wallet by ID, falling back to the first wallet in the currency
+func (s *Server) walletLocked(walletID, currency string) *intasend.WalletResp {
+	for _, w := range s.wallets {
+		if walletID != "" && w.WalletID == walletID {
+			return w
+		}
+	}
+	if walletID != "" {
+		return nil
+	}
+	for _, w := range s.wallets {
+		if w.Currency == currency {
+			return w
+		}
+	}
+	return nil
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s%05d", prefix, s.seq)
+}
+
+func (s *Server) callbackLocked(rec *invoiceRecord) intasend.CollectionCallback {
+	inv := rec.invoice
+	return intasend.CollectionCallback{
+		InvoiceID:    inv.InvoiceID,
+		State:        inv.State,
+		Provider:     inv.Provider,
+		Charges:      fmt.Sprintf("%.2f", inv.Charges),
+		NetAmount:    inv.NetAmount,
+		Currency:     inv.Currency,
+		Value:        fmt.Sprintf("%.2f", inv.Value),
+		Account:      inv.Account,
+		APIRef:       inv.APIRef,
+		Host:         inv.Host,
+		FailedReason: inv.GetFailureReason(),
+		CreatedAt:    inv.CreatedAt,
+		UpdatedAt:    inv.UpdatedAt,
+		Challenge:    s.challenge,
+
+		SubscriptionID: inv.SubscriptionID,
+	}
+}
+
+// deliver posts a callback to the configured URL and records the outcome
+func (s *Server) deliver(cb intasend.CollectionCallback) {
+	s.mu.Lock()
+	callbackURL := s.callbackURL
+	s.mu.Unlock()
+	if callbackURL == "" {
+		return
+	}
+
+	delivery := WebhookDelivery{Callback: cb}
+	body, err := cb.Marshal()
+	if err == nil {
+		var resp *http.Response
+		resp, err = s.webhookClient.Post(callbackURL, "application/json", bytes.NewReader(body))
+		if err == nil {
+			delivery.StatusCode = resp.StatusCode
+			resp.Body.Close()
+		}
+	}
+	delivery.Err = err
+
+	s.mu.Lock()
+	s.deliveries = append(s.deliveries, delivery)
+	s.mu.Unlock()
+}
+
+func invoiceTx(inv intasend.InvoiceItem) intasend.InvoiceTx {
+	var mpesaRef interface{}
+	if inv.MpesaReference != nil {
+		mpesaRef = *inv.MpesaReference
+	}
+	var failedReason interface{}
+	if inv.FailedReason != nil {
+		failedReason = *inv.FailedReason
+	}
+	return intasend.InvoiceTx{
+		InvoiceID:      inv.InvoiceID,
+		State:          inv.State,
+		Provider:       inv.Provider,
+		Charges:        inv.Charges,
+		NetAmount:      inv.NetAmount,
+		Currency:       inv.Currency,
+		Value:          inv.Value,
+		Account:        inv.Account,
+		APIRef:         inv.APIRef,
+		ClearingStatus: inv.ClearingStatus,
+		MpesaReference: mpesaRef,
+		Host:           inv.Host,
+		CardInfo:       inv.CardInfo,
+		RetryCount:     int64(inv.RetryCount),
+		FailedReason:   failedReason,
+		CreatedAt:      inv.CreatedAt,
+		UpdatedAt:      inv.UpdatedAt,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, intasend.ErrorResponse{Message: message})
+}
@@ -0,0 +1,534 @@
+package intasendtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+func TestCheckoutLifecycle(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		callbacks []intasend.CollectionCallback
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		cb, err := intasend.UnmarshalCollectionCallback(body)
+		if err != nil {
+			t.Errorf("Failed to parse callback: %v", err)
+		}
+		mu.Lock()
+		callbacks = append(callbacks, cb)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	srv := NewServer(WithCallbackURL(hook.URL, "secret-challenge"))
+	defer srv.Close()
+	client := srv.Client()
+
+	checkout, err := client.CreateCheckoutLink(&intasend.PaymentRequest{
+		Email:  "customer@example.com",
+		Amount: 1000,
+		APIRef: "order-1",
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckoutLink failed: %v", err)
+	}
+
+	status, err := client.GetPaymentStatus(checkout.ID)
+	if err != nil {
+		t.Fatalf("GetPaymentStatus failed: %v", err)
+	}
+	if !status.IsPending() {
+		t.Errorf("Expected new invoice to be PENDING, got %s", status.Invoice.State)
+	}
+
+	if err := srv.Complete(checkout.ID); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	status, err = client.GetPaymentStatus(checkout.ID)
+	if err != nil {
+		t.Fatalf("GetPaymentStatus failed: %v", err)
+	}
+	if !status.IsCompleted() {
+		t.Errorf("Expected invoice to be COMPLETE, got %s", status.Invoice.State)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(callbacks) != 2 {
+		t.Fatalf("Expected 2 callbacks (PROCESSING, COMPLETE), got %d", len(callbacks))
+	}
+	if callbacks[1].State != intasend.StatusComplete || callbacks[1].Challenge != "secret-challenge" {
+		t.Errorf("Unexpected final callback: %+v", callbacks[1])
+	}
+
+	txns, err := client.ListTransactions(&intasend.ListTransactionsParams{TransType: intasend.TransTypeDeposit})
+	if err != nil {
+		t.Fatalf("ListTransactions failed: %v", err)
+	}
+	if txns.Count != 1 || txns.Results[0].GetInvoiceID() != checkout.ID {
+		t.Errorf("Expected one deposit for %s, got %+v", checkout.ID, txns.Results)
+	}
+}
+
+func TestAutoAdvanceAndFailure(t *testing.T) {
+	srv := NewServer(WithAutoAdvance())
+	defer srv.Close()
+	client := srv.Client()
+
+	push, err := client.SendIntaSendXBPush(&intasend.IntaSendXBPushRequest{
+		Amount:      "3013",
+		PhoneNumber: "256759739706",
+		Currency:    intasend.CurrencyUGX,
+	})
+	if err != nil {
+		t.Fatalf("SendIntaSendXBPush failed: %v", err)
+	}
+	if err := srv.SetOutcome(push.Invoice.InvoiceID, intasend.StatusFailed, "Insufficient balance"); err != nil {
+		t.Fatal(err)
+	}
+
+	var status *intasend.PaymentStatus
+	for i := 0; i < 3; i++ {
+		status, err = client.GetPaymentStatus(push.Invoice.InvoiceID)
+		if err != nil {
+			t.Fatalf("GetPaymentStatus failed: %v", err)
+		}
+	}
+	if !status.IsFailed() || status.GetFailureReason() != "Insufficient balance" {
+		t.Errorf("Expected FAILED with reason, got %s (%s)", status.Invoice.State, status.GetFailureReason())
+	}
+}
+
+func TestSettleStuckInvoice(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	checkout, err := srv.Client().QuickCheckout("", "a@example.com", 10, intasend.CurrencyKES, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A state the lifecycle does not know never advances
+	srv.mu.Lock()
+	srv.invoices[checkout.ID].invoice.State = "ON_HOLD"
+	srv.mu.Unlock()
+	if err := srv.Complete(checkout.ID); err == nil || !strings.Contains(err.Error(), "did not settle") {
+		t.Errorf("Expected settling to give up, got %v", err)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	srv.InjectFault(Fault{Path: "/api/v1/wallets/", Status: http.StatusTooManyRequests, Times: 2})
+
+	for i := 0; i < 2; i++ {
+		_, err := client.ListWallets(nil)
+		if err == nil || !strings.Contains(err.Error(), "429") {
+			t.Fatalf("Expected 429 error on attempt %d, got %v", i+1, err)
+		}
+	}
+	wallets, err := client.ListWallets(nil)
+	if err != nil {
+		t.Fatalf("Expected fault to be exhausted, got %v", err)
+	}
+	if wallets.Count != 3 {
+		t.Errorf("Expected 3 default wallets, got %d", wallets.Count)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	client.HTTPClient.Timeout = 20 * time.Millisecond
+
+	srv.SetLatency("/api/v1/invoices/", 200*time.Millisecond)
+	if _, err := client.ListInvoices(nil); err == nil {
+		t.Error("Expected timeout error")
+	}
+}
+
+func TestSendMoneyLifecycle(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	resp, err := client.InitiateSendMoney(&intasend.SendMoneyRequest{
+		Currency: intasend.CurrencyKES,
+		Provider: intasend.ProviderMPESAB2C,
+		Transactions: []intasend.SendMoneyTransaction{
+			{Name: "Jane", Account: "254712345678", Amount: "150"},
+			{Name: "John", Account: "254712345679", Amount: "50"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateSendMoney failed: %v", err)
+	}
+	if resp.TotalAmount != 200 || len(resp.Transactions) != 2 {
+		t.Errorf("Unexpected send-money response: %+v", resp)
+	}
+
+	for _, want := range []string{intasend.StatusProcessing, intasend.StatusComplete} {
+		state, err := srv.AdvancePayout(resp.TrackingID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != want {
+			t.Errorf("Expected payout state %s, got %s", want, state)
+		}
+	}
+
+	txns, err := client.ListWalletTransactions(resp.Wallet.WalletID, nil)
+	if err != nil {
+		t.Fatalf("ListWalletTransactions failed: %v", err)
+	}
+	if txns.Count != 1 || !txns.Results[0].IsWithdrawal() {
+		t.Errorf("Expected one withdrawal, got %+v", txns.Results)
+	}
+
+	status, err := client.GetSendMoneyStatus(resp.TrackingID)
+	if err != nil {
+		t.Fatalf("GetSendMoneyStatus failed: %v", err)
+	}
+	if status.Status != intasend.StatusComplete || status.Transactions[1].Status != intasend.StatusComplete {
+		t.Errorf("Unexpected send-money status: %+v", status)
+	}
+	if _, err := client.GetSendMoneyStatus("TRK-missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected 404 for an unknown tracking ID, got %v", err)
+	}
+}
+
+func TestSendMoneyIdempotency(t *testing.T) {
+	srv := NewServer(WithAutoAdvance())
+	defer srv.Close()
+	client := srv.Client()
+
+	req := &intasend.SendMoneyRequest{
+		Currency:     intasend.CurrencyKES,
+		Provider:     intasend.ProviderMPESAB2C,
+		Transactions: []intasend.SendMoneyTransaction{{Name: "Jane", Account: "254712345678", Amount: "150"}},
+	}
+	first, err := client.InitiateSendMoney(req, intasend.WithIdempotencyKey("payroll-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := client.InitiateSendMoney(req, intasend.WithIdempotencyKey("payroll-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := client.InitiateSendMoney(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.TrackingID != first.TrackingID || other.TrackingID == first.TrackingID {
+		t.Errorf("Expected only the repeated key to return the same batch, got %s, %s, %s", first.TrackingID, again.TrackingID, other.TrackingID)
+	}
+
+	// Auto-advance moves the batch one step per status check
+	for _, want := range []string{intasend.StatusProcessing, intasend.StatusComplete, intasend.StatusComplete} {
+		status, err := client.GetSendMoneyStatus(first.TrackingID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Status != want {
+			t.Errorf("Expected %s, got %s", want, status.Status)
+		}
+	}
+}
+
+func TestRejectsBadCredentials(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client := srv.Client()
+	client.Token = "ISSecretKey_test_wrong"
+	if _, err := client.ListInvoices(nil); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected 401 for bad token, got %v", err)
+	}
+}
+
+func TestInvoicePagination(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	for i := 0; i < 12; i++ {
+		if _, err := client.QuickCheckout("254712345678", "", 10, intasend.CurrencyKES, "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page := 2
+	invoices, err := client.ListInvoices(&intasend.ListInvoicesParams{Page: &page})
+	if err != nil {
+		t.Fatalf("ListInvoices failed: %v", err)
+	}
+	if invoices.Count != 12 || len(invoices.Results) != 2 {
+		t.Errorf("Expected 2 of 12 invoices on page 2, got %d of %d", len(invoices.Results), invoices.Count)
+	}
+	if invoices.Next != nil || invoices.Previous == nil {
+		t.Errorf("Expected only a previous link on the last page")
+	}
+	for period, want := range map[string]int{"today": 12, "yesterday": 0} {
+		invoices, err := client.ListInvoices(&intasend.ListInvoicesParams{UpdatedAt: period})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if invoices.Count != want {
+			t.Errorf("Expected %d invoices updated %s, got %d", want, period, invoices.Count)
+		}
+	}
+}
+
+func TestPaymentLinks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	link, err := client.CreatePaymentLink(&intasend.PaymentLinkRequest{
+		Title:     "Donations",
+		Currency:  intasend.CurrencyKES,
+		ExpiresAt: &expires,
+		Styles:    &intasend.Styles{CtaBgColor: "#0a7d4f"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePaymentLink failed: %v", err)
+	}
+	if link.LinkID == "" || link.URL == "" || !link.IsOpenAmount() || !link.AcceptsPayments(time.Now()) {
+		t.Errorf("Unexpected link: %+v", link)
+	}
+	if link.Styles == nil || link.Styles.CtaBgColor != "#0a7d4f" {
+		t.Errorf("Expected branding to round-trip, got %+v", link.Styles)
+	}
+	if link.AcceptsPayments(expires) {
+		t.Error("Expected link to stop accepting payments at its expiry")
+	}
+	if _, err := client.CreatePaymentLink(&intasend.PaymentLinkRequest{Title: "Tickets", Currency: intasend.CurrencyKES, Amount: 1500}); err != nil {
+		t.Fatal(err)
+	}
+
+	amount := 250.0
+	updated, err := client.UpdatePaymentLink(link.LinkID, &intasend.PaymentLinkUpdate{Amount: &amount})
+	if err != nil {
+		t.Fatalf("UpdatePaymentLink failed: %v", err)
+	}
+	if updated.Amount != 250 || updated.Title != "Donations" {
+		t.Errorf("Expected only the amount to change, got %+v", updated)
+	}
+
+	if _, err := client.DeactivatePaymentLink(link.LinkID); err != nil {
+		t.Fatalf("DeactivatePaymentLink failed: %v", err)
+	}
+	got, err := client.GetPaymentLink(link.LinkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.IsActive {
+		t.Error("Expected link to be inactive")
+	}
+
+	active, pageSize := true, 1
+	links, err := client.ListPaymentLinks(&intasend.ListPaymentLinksParams{IsActive: &active, PageSize: &pageSize})
+	if err != nil {
+		t.Fatalf("ListPaymentLinks failed: %v", err)
+	}
+	if links.Count != 1 || len(links.Results) != 1 || links.Results[0].Title != "Tickets" {
+		t.Errorf("Expected only the active link, got %+v", links)
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer hook.Close()
+
+	srv := NewServer(WithCallbackURL(hook.URL, ""))
+	defer srv.Close()
+	client := srv.Client()
+
+	plan, err := client.CreatePlan(&intasend.PlanRequest{
+		Name:     "Pro",
+		Amount:   1000,
+		Currency: intasend.CurrencyKES,
+		Interval: intasend.IntervalMonthly,
+	})
+	if err != nil {
+		t.Fatalf("CreatePlan failed: %v", err)
+	}
+
+	sub, err := client.CreateSubscription(&intasend.SubscriptionRequest{
+		PlanID: plan.PlanID,
+		Email:  "customer@example.com",
+		APIRef: "member-1",
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscription failed: %v", err)
+	}
+	if sub.Status != intasend.SubscriptionPending || sub.URL == "" || sub.CustomerID == "" {
+		t.Errorf("Unexpected new subscription: %+v", sub)
+	}
+
+	charges, err := client.ListSubscriptionCharges(sub.SubscriptionID, nil)
+	if err != nil {
+		t.Fatalf("ListSubscriptionCharges failed: %v", err)
+	}
+	if charges.Count != 1 {
+		t.Fatalf("Expected the first charge, got %+v", charges)
+	}
+	if err := srv.Complete(charges.Results[0].InvoiceID); err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err = client.GetSubscription(sub.SubscriptionID)
+	if err != nil {
+		t.Fatalf("GetSubscription failed: %v", err)
+	}
+	if !sub.IsActive() || sub.NextChargeAt == nil {
+		t.Errorf("Expected an active subscription after the first payment, got %+v", sub)
+	}
+	deliveries := srv.Deliveries()
+	if len(deliveries) == 0 || !deliveries[len(deliveries)-1].Callback.IsSubscriptionPayment() {
+		t.Errorf("Expected callbacks to carry the subscription ID, got %+v", deliveries)
+	}
+
+	invoiceID, err := srv.RenewSubscription(sub.SubscriptionID)
+	if err != nil {
+		t.Fatalf("RenewSubscription failed: %v", err)
+	}
+	if err := srv.Fail(invoiceID, "Insufficient balance"); err != nil {
+		t.Fatal(err)
+	}
+	sub, err = client.GetSubscription(sub.SubscriptionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Status != intasend.SubscriptionPastDue {
+		t.Errorf("Expected PAST_DUE after a failed renewal, got %s", sub.Status)
+	}
+	failed, err := client.ListSubscriptionCharges(sub.SubscriptionID, &intasend.ListChargesParams{State: intasend.StatusFailed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed.Count != 1 || failed.Results[0].InvoiceID != invoiceID || failed.Results[0].IsPaid() {
+		t.Errorf("Expected the failed renewal, got %+v", failed.Results)
+	}
+
+	if sub, err = client.PauseSubscription(sub.SubscriptionID); err != nil || sub.Status != intasend.SubscriptionPaused {
+		t.Fatalf("PauseSubscription: %+v, %v", sub, err)
+	}
+	if _, err := srv.RenewSubscription(sub.SubscriptionID); err == nil {
+		t.Error("Expected renewing a paused subscription to fail")
+	}
+	if sub, err = client.ResumeSubscription(sub.SubscriptionID); err != nil || sub.Status != intasend.SubscriptionPastDue {
+		// resuming keeps the outstanding failed charge
+		t.Fatalf("ResumeSubscription: %+v, %v", sub, err)
+	}
+	if sub, err = client.CancelSubscription(sub.SubscriptionID, nil); err != nil || !sub.IsCancelled() {
+		t.Fatalf("CancelSubscription: %+v, %v", sub, err)
+	}
+
+	subs, err := client.ListSubscriptions(&intasend.ListSubscriptionsParams{Status: intasend.SubscriptionCancelled})
+	if err != nil {
+		t.Fatalf("ListSubscriptions failed: %v", err)
+	}
+	if subs.Count != 1 || subs.Results[0].SubscriptionID != sub.SubscriptionID {
+		t.Errorf("Expected the cancelled subscription, got %+v", subs)
+	}
+}
+
+func TestCustomers(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	customer, err := client.CreateCustomer(&intasend.CustomerRequest{
+		Email:       "jane@example.com",
+		PhoneNumber: "254712345678",
+		FirstName:   "Jane",
+	})
+	if err != nil {
+		t.Fatalf("CreateCustomer failed: %v", err)
+	}
+	if customer.CustomerID == "" || customer.CreatedAt.IsZero() {
+		t.Errorf("Unexpected customer: %+v", customer)
+	}
+	if _, err := client.CreateCustomer(&intasend.CustomerRequest{Email: "jane@example.com"}); err == nil {
+		t.Error("Expected a duplicate email to be rejected")
+	}
+
+	lastName := "Wanjiru"
+	updated, err := client.UpdateCustomer(customer.CustomerID, &intasend.CustomerUpdate{LastName: &lastName})
+	if err != nil {
+		t.Fatalf("UpdateCustomer failed: %v", err)
+	}
+	if updated.FullName() != "Jane Wanjiru" || updated.Email != "jane@example.com" {
+		t.Errorf("Expected only the last name to change, got %+v", updated)
+	}
+
+	// Checkout by customer ID picks up the stored details
+	checkout, err := client.CreateCheckoutLink(&intasend.PaymentRequest{CustomerID: customer.CustomerID, Amount: 500})
+	if err != nil {
+		t.Fatalf("CreateCheckoutLink failed: %v", err)
+	}
+	status, err := client.GetPaymentStatus(checkout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Meta.Customer.CustomerID != customer.CustomerID || status.GetCustomerName() != "Jane Wanjiru" {
+		t.Errorf("Expected checkout for the stored customer, got %+v", status.Meta.Customer)
+	}
+	if _, err := client.CreateCheckoutLink(&intasend.PaymentRequest{CustomerID: "CUS-missing", Amount: 500}); err == nil {
+		t.Error("Expected an unknown customer ID to be rejected")
+	}
+
+	push, err := client.SendIntaSendXBPush(&intasend.IntaSendXBPushRequest{
+		Amount:     "1000",
+		CustomerID: customer.CustomerID,
+		Currency:   intasend.CurrencyUGX,
+	})
+	if err != nil {
+		t.Fatalf("SendIntaSendXBPush failed: %v", err)
+	}
+	if push.Customer.CustomerID != customer.CustomerID || push.Invoice.Account != "254712345678" {
+		t.Errorf("Expected the push to use the stored phone number, got %+v", push)
+	}
+
+	// A checkout with new contact details creates a customer
+	if _, err := client.QuickCheckout("", "sam@example.com", 100, intasend.CurrencyKES, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetCustomer(customer.CustomerID)
+	if err != nil {
+		t.Fatalf("GetCustomer failed: %v", err)
+	}
+	if got.Provider != "INTASEND-XB-PUSH" {
+		t.Errorf("Expected the last-used provider to be recorded, got %q", got.Provider)
+	}
+
+	found, err := client.SearchCustomers("example.com")
+	if err != nil {
+		t.Fatalf("SearchCustomers failed: %v", err)
+	}
+	if found.Count != 2 {
+		t.Errorf("Expected both customers to match, got %+v", found.Results)
+	}
+	byPhone, err := client.ListCustomers(&intasend.ListCustomersParams{PhoneNumber: "254712345678"})
+	if err != nil {
+		t.Fatalf("ListCustomers failed: %v", err)
+	}
+	if byPhone.Count != 1 || byPhone.Results[0].CustomerID != customer.CustomerID {
+		t.Errorf("Expected the customer with that phone number, got %+v", byPhone.Results)
+	}
+}
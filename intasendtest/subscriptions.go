@@ -0,0 +1,384 @@
+package intasendtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/techliana/intasend-sdk-golang"
+)
+
+// subscriptionRecord tracks a subscription and the invoices of its billing cycles
+type subscriptionRecord struct {
+	sub     intasend.Subscription
+	plan    intasend.Plan
+	account string
+	charges []intasend.RecurringCharge // oldest first; State is read from the invoice
+}
+
+// RenewSubscription starts the next billing cycle of a subscription by
+// creating a PENDING invoice for it, and returns the invoice ID. Drive the
+// invoice with Advance, Complete or Fail like any other; its callbacks carry
+// the subscription ID.
+func (s *Server) RenewSubscription(subscriptionID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.subscriptions[subscriptionID]
+	if !ok {
+		return "", fmt.Errorf("subscription %s not found", subscriptionID)
+	}
+	switch s.subscriptionLocked(rec).Status {
+	case intasend.SubscriptionPaused, intasend.SubscriptionCancelled:
+		return "", fmt.Errorf("subscription %s is %s", subscriptionID, rec.sub.Status)
+	}
+	return s.chargeLocked(rec).InvoiceID, nil
+}
+
+// chargeLocked creates the invoice for the subscription's next billing period
+func (s *Server) chargeLocked(rec *subscriptionRecord) intasend.RecurringCharge {
+	start := time.Now()
+	if n := len(rec.charges); n > 0 {
+		start = rec.charges[n-1].PeriodEnd
+	}
+	end := addInterval(start, rec.plan.Interval, rec.plan.IntervalCount)
+
+	inv := s.newInvoiceLocked(intasend.ProviderCard, rec.plan.Currency, rec.account, rec.sub.APIRef, rec.plan.Amount, "")
+	inv.invoice.SubscriptionID = rec.sub.SubscriptionID
+
+	charge := intasend.RecurringCharge{
+		ChargeID:       s.nextID("CHG"),
+		SubscriptionID: rec.sub.SubscriptionID,
+		PlanID:         rec.plan.PlanID,
+		InvoiceID:      inv.invoice.InvoiceID,
+		Amount:         rec.plan.Amount,
+		Currency:       rec.plan.Currency,
+		Attempt:        1,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		CreatedAt:      time.Now(),
+	}
+	rec.charges = append(rec.charges, charge)
+	return charge
+}
+
+// chargesLocked returns the subscription's charges, newest first, with
+// their state taken from the linked invoices
+func (s *Server) chargesLocked(rec *subscriptionRecord) []intasend.RecurringCharge {
+	charges := make([]intasend.RecurringCharge, 0, len(rec.charges))
+	for i := len(rec.charges) - 1; i >= 0; i-- {
+		charge := rec.charges[i]
+		if inv, ok := s.invoices[charge.InvoiceID]; ok {
+			charge.State = inv.invoice.State
+			charge.FailedReason = inv.invoice.FailedReason
+			if inv.invoice.IsCompleted() {
+				charged := inv.invoice.UpdatedAt
+				charge.ChargedAt = &charged
+			}
+		}
+		charges = append(charges, charge)
+	}
+	return charges
+}
+
+// subscriptionLocked derives the subscription's status and current period
+// from its stored state and the outcome of its charges
+func (s *Server) subscriptionLocked(rec *subscriptionRecord) intasend.Subscription {
+	sub := rec.sub
+	if sub.Status == intasend.SubscriptionPaused || sub.Status == intasend.SubscriptionCancelled {
+		return sub
+	}
+
+	charges := s.chargesLocked(rec)
+	var paid *intasend.RecurringCharge
+	for i := range charges {
+		if charges[i].IsPaid() {
+			paid = &charges[i]
+			break
+		}
+	}
+	switch {
+	case paid == nil:
+		sub.Status = intasend.SubscriptionPending
+	case charges[0].State == intasend.StatusFailed:
+		sub.Status = intasend.SubscriptionPastDue
+	default:
+		sub.Status = intasend.SubscriptionActive
+	}
+	if paid != nil {
+		start, end := paid.PeriodStart, paid.PeriodEnd
+		sub.CurrentPeriodStart, sub.CurrentPeriodEnd = &start, &end
+		if !sub.CancelAtPeriodEnd {
+			sub.NextChargeAt = &end
+		}
+	}
+	return sub
+}
+
+func addInterval(t time.Time, interval intasend.BillingInterval, count int) time.Time {
+	if count < 1 {
+		count = 1
+	}
+	switch interval {
+	case intasend.IntervalDaily:
+		return t.AddDate(0, 0, count)
+	case intasend.IntervalWeekly:
+		return t.AddDate(0, 0, 7*count)
+	case intasend.IntervalYearly:
+		return t.AddDate(count, 0, 0)
+	default:
+		return t.AddDate(0, count, 0)
+	}
+}
+
+func (s *Server) handleCreatePlan(w http.ResponseWriter, r *http.Request) {
+	var req intasend.PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Name == "" || req.Amount <= 0 || req.Currency == "" || req.Interval == "" {
+		writeError(w, http.StatusBadRequest, "name, amount, currency and interval are required")
+		return
+	}
+	if req.IntervalCount < 1 {
+		req.IntervalCount = 1
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	plan := &intasend.Plan{
+		PlanID:        s.nextID("PLN"),
+		Name:          req.Name,
+		Amount:        req.Amount,
+		Currency:      string(req.Currency),
+		Interval:      req.Interval,
+		IntervalCount: req.IntervalCount,
+		TrialDays:     req.TrialDays,
+		Description:   req.Description,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	s.plans[plan.PlanID] = plan
+	s.planOrder = append(s.planOrder, plan.PlanID)
+	resp := *plan
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) handleListPlans(w http.ResponseWriter, r *http.Request) {
+	currency := r.URL.Query().Get("currency")
+	s.mu.Lock()
+	var plans []intasend.Plan
+	for i := len(s.planOrder) - 1; i >= 0; i-- {
+		plan := s.plans[s.planOrder[i]]
+		if currency != "" && plan.Currency != currency {
+			continue
+		}
+		plans = append(plans, *plan)
+	}
+	s.mu.Unlock()
+
+	start, end, next, prev := paginate(r, len(plans))
+	writeJSON(w, http.StatusOK, intasend.PaginatedPlans{
+		Count:    len(plans),
+		Next:     next,
+		Previous: prev,
+		Results:  nonNil(plans[start:end]),
+	})
+}
+
+func (s *Server) handleGetPlan(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	plan, ok := s.plans[r.PathValue("id")]
+	var resp intasend.Plan
+	if ok {
+		resp = *plan
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "plan not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req intasend.SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	plan, ok := s.plans[req.PlanID]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "plan not found")
+		return
+	}
+	customer, ok := s.resolveCustomerLocked(req.CustomerID, intasend.Customer{
+		Email:       req.Email,
+		PhoneNumber: req.PhoneNumber,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+	}, intasend.ProviderCard)
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "customer not found")
+		return
+	}
+	account := customer.Email
+	if account == "" {
+		account = customer.PhoneNumber
+	}
+
+	now := time.Now()
+	rec := &subscriptionRecord{
+		sub: intasend.Subscription{
+			SubscriptionID: s.nextID("SUB"),
+			PlanID:         plan.PlanID,
+			CustomerID:     customer.CustomerID,
+			Status:         intasend.SubscriptionPending,
+			APIRef:         req.APIRef,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		},
+		plan:    *plan,
+		account: account,
+	}
+	charge := s.chargeLocked(rec)
+	rec.sub.URL = fmt.Sprintf("%s/checkout/%s/express/", s.URL, charge.InvoiceID)
+	s.subscriptions[rec.sub.SubscriptionID] = rec
+	s.subOrder = append(s.subOrder, rec.sub.SubscriptionID)
+	resp := s.subscriptionLocked(rec)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var subs []intasend.Subscription
+	for i := len(s.subOrder) - 1; i >= 0; i-- {
+		sub := s.subscriptionLocked(s.subscriptions[s.subOrder[i]])
+		if v := q.Get("plan_id"); v != "" && sub.PlanID != v {
+			continue
+		}
+		if v := q.Get("customer_id"); v != "" && sub.CustomerID != v {
+			continue
+		}
+		if v := q.Get("status"); v != "" && sub.Status != v {
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	s.mu.Unlock()
+
+	start, end, next, prev := paginate(r, len(subs))
+	writeJSON(w, http.StatusOK, intasend.PaginatedSubscriptions{
+		Count:    len(subs),
+		Next:     next,
+		Previous: prev,
+		Results:  nonNil(subs[start:end]),
+	})
+}
+
+func (s *Server) handleGetSubscription(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	rec, ok := s.subscriptions[r.PathValue("id")]
+	var resp intasend.Subscription
+	if ok {
+		resp = s.subscriptionLocked(rec)
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "subscription not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleSubscriptionAction(w http.ResponseWriter, r *http.Request) {
+	var cancel intasend.CancelSubscriptionRequest
+	if r.PathValue("action") == "cancel" {
+		if err := json.NewDecoder(r.Body).Decode(&cancel); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.subscriptions[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "subscription not found")
+		return
+	}
+	current := s.subscriptionLocked(rec)
+	if current.IsCancelled() {
+		writeError(w, http.StatusBadRequest, "subscription is cancelled")
+		return
+	}
+
+	now := time.Now()
+	switch r.PathValue("action") {
+	case "cancel":
+		if cancel.AtPeriodEnd {
+			rec.sub.CancelAtPeriodEnd = true
+		} else {
+			rec.sub.Status = intasend.SubscriptionCancelled
+			rec.sub.CancelledAt = &now
+		}
+	case "pause":
+		rec.sub.Status = intasend.SubscriptionPaused
+		rec.sub.PausedAt = &now
+	case "resume":
+		if current.Status != intasend.SubscriptionPaused {
+			writeError(w, http.StatusBadRequest, "subscription is not paused")
+			return
+		}
+		rec.sub.Status = intasend.SubscriptionActive
+		rec.sub.PausedAt = nil
+	default:
+		writeError(w, http.StatusNotFound, "unknown subscription action")
+		return
+	}
+	rec.sub.UpdatedAt = now
+	writeJSON(w, http.StatusOK, s.subscriptionLocked(rec))
+}
+
+func (s *Server) handleListCharges(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("collection") != "charges" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	state := r.URL.Query().Get("state")
+
+	s.mu.Lock()
+	rec, ok := s.subscriptions[r.PathValue("id")]
+	var charges []intasend.RecurringCharge
+	if ok {
+		for _, charge := range s.chargesLocked(rec) {
+			if state == "" || charge.State == state {
+				charges = append(charges, charge)
+			}
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "subscription not found")
+		return
+	}
+
+	start, end, next, prev := paginate(r, len(charges))
+	writeJSON(w, http.StatusOK, intasend.PaginatedCharges{
+		Count:    len(charges),
+		Next:     next,
+		Previous: prev,
+		Results:  nonNil(charges[start:end]),
+	})
+}
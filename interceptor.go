@@ -0,0 +1,118 @@
+package intasend
+
+import (
+	"context"
+	"fmt"
+)
+
+// Operation names passed to interceptors
+const (
+	OpCheckoutCreate         = "checkout.create"
+	OpCollectionXBPush       = "collection.xb_push"
+	OpPaymentStatus          = "payment.status"
+	OpSendMoneyInitiate      = "send_money.initiate"
+	OpSendMoneyStatus        = "send_money.status"
+	OpWalletsList            = "wallets.list"
+	OpWalletTransactionsList = "wallets.transactions"
+	OpTransactionsList       = "transactions.list"
+	OpTransactionGet         = "transactions.get"
+	OpInvoicesList           = "invoices.list"
+	OpInvoiceGet             = "invoices.get"
+	OpPaymentLinkCreate      = "payment_links.create"
+	OpPaymentLinksList       = "payment_links.list"
+	OpPaymentLinkGet         = "payment_links.get"
+	OpPaymentLinkUpdate      = "payment_links.update"
+	OpPaymentLinkDeactivate  = "payment_links.deactivate"
+	OpPlanCreate             = "subscriptions.plan_create"
+	OpPlansList              = "subscriptions.plans_list"
+	OpPlanGet                = "subscriptions.plan_get"
+	OpSubscriptionCreate     = "subscriptions.create"
+	OpSubscriptionsList      = "subscriptions.list"
+	OpSubscriptionGet        = "subscriptions.get"
+	OpSubscriptionCancel     = "subscriptions.cancel"
+	OpSubscriptionPause      = "subscriptions.pause"
+	OpSubscriptionResume     = "subscriptions.resume"
+	OpSubscriptionCharges    = "subscriptions.charges"
+	OpCustomerCreate         = "customers.create"
+	OpCustomersList          = "customers.list"
+	OpCustomersSearch        = "customers.search"
+	OpCustomerGet            = "customers.get"
+	OpCustomerUpdate         = "customers.update"
+)
+
+// Invocation describes a logical SDK operation as seen by interceptors.
+// Interceptors may replace Request or ResourceID before calling next, as long
+// as Request keeps the type the operation expects.
+type Invocation struct {
+	Operation  string      // logical operation name, e.g. OpSendMoneyInitiate
+	Request    interface{} // typed request (e.g. *SendMoneyRequest, *ListInvoicesParams), nil if none
+	ResourceID string      // ID addressed by the operation (invoice, transaction or wallet ID), if any
+
+	// Headers are extra HTTP headers sent with the request (e.g. tenant IDs)
+	Headers map[string]string
+}
+
+// SetHeader adds an HTTP header to the request made for this invocation
+func (inv *Invocation) SetHeader(key, value string) {
+	if inv.Headers == nil {
+		inv.Headers = make(map[string]string)
+	}
+	inv.Headers[key] = value
+}
+
+// Handler executes an invocation and returns the typed response (e.g. *SendMoneyResponse)
+type Handler func(ctx context.Context, inv *Invocation) (interface{}, error)
+
+// Interceptor wraps every SDK operation. It may inspect or modify the
+// invocation, short-circuit by returning without calling next, or inspect and
+// replace the typed response or error.
+type Interceptor func(ctx context.Context, inv *Invocation, next Handler) (interface{}, error)
+
+// Use appends interceptors to the client's chain. The first interceptor added is the outermost.
+func (c *Client) Use(interceptors ...Interceptor) {
+	c.Interceptors = append(c.Interceptors, interceptors...)
+}
+
+// chainInterceptors builds a handler that runs interceptors in order around final
+func chainInterceptors(interceptors []Interceptor, final Handler) Handler {
+	h := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], h
+		h = func(ctx context.Context, inv *Invocation) (interface{}, error) {
+			return interceptor(ctx, inv, next)
+		}
+	}
+	return h
+}
+
+// invoke runs an operation through the client's interceptor chain. do performs
+// the actual call using the (possibly modified) invocation and context.
+func invoke[T any](c *Client, inv *Invocation, opts []CallOption, do func(inv *Invocation, opts []CallOption) (*T, error)) (*T, error) {
+	co := newCallOptions(opts)
+	final := func(ctx context.Context, inv *Invocation) (interface{}, error) {
+		callOpts := append(opts[:len(opts):len(opts)], WithContext(ctx))
+		for k, v := range inv.Headers {
+			callOpts = append(callOpts, WithHeader(k, v))
+		}
+		return do(inv, callOpts)
+	}
+
+	out, err := chainInterceptors(c.Interceptors, final)(co.ctx, inv)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := out.(*T)
+	if !ok {
+		return nil, fmt.Errorf("%s: interceptor returned %T, want %T", inv.Operation, out, result)
+	}
+	return result, nil
+}
+
+// requestAs returns the invocation's request as the type the operation expects
+func requestAs[T any](inv *Invocation) (T, error) {
+	req, ok := inv.Request.(T)
+	if !ok && inv.Request != nil {
+		return req, fmt.Errorf("%s: request has type %T, want %T", inv.Operation, inv.Request, req)
+	}
+	return req, nil
+}
@@ -0,0 +1,108 @@
+package intasend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterceptorChain(t *testing.T) {
+	var tenant string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant-ID")
+		w.Write([]byte(`{"tracking_id":"TRK1","status":"Preview and approve"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient("pk", "token", true, false)
+	client.APIBaseURL = srv.URL
+
+	var order []string
+	client.Use(
+		func(ctx context.Context, inv *Invocation, next Handler) (interface{}, error) {
+			order = append(order, "outer:"+inv.Operation)
+			inv.SetHeader("X-Tenant-ID", "tenant-7")
+			return next(ctx, inv)
+		},
+		func(ctx context.Context, inv *Invocation, next Handler) (interface{}, error) {
+			order = append(order, "inner:"+inv.Operation)
+			req := inv.Request.(*SendMoneyRequest)
+			req.BatchReference = "audited-" + req.BatchReference
+			out, err := next(ctx, inv)
+			if resp, ok := out.(*SendMoneyResponse); ok {
+				order = append(order, "response:"+resp.TrackingID)
+			}
+			return out, err
+		},
+	)
+
+	req := &SendMoneyRequest{
+		Currency:       CurrencyKES,
+		Provider:       ProviderMPESAB2C,
+		BatchReference: "b1",
+		Transactions:   []SendMoneyTransaction{{Account: "254700000000", Amount: "10"}},
+	}
+	resp, err := client.InitiateSendMoney(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.TrackingID != "TRK1" {
+		t.Errorf("Expected TRK1, got %s", resp.TrackingID)
+	}
+	if req.BatchReference != "audited-b1" {
+		t.Errorf("Expected interceptor to modify request, got %s", req.BatchReference)
+	}
+	if tenant != "tenant-7" {
+		t.Errorf("Expected tenant header, got %q", tenant)
+	}
+	want := []string{"outer:" + OpSendMoneyInitiate, "inner:" + OpSendMoneyInitiate, "response:TRK1"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("Expected order %v, got %v", want, order)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	client := NewClient("pk", "token", true, false)
+	client.BaseURL = "http://127.0.0.1:0" // never reached
+
+	client.Use(func(ctx context.Context, inv *Invocation, next Handler) (interface{}, error) {
+		if inv.Operation == OpInvoiceGet {
+			return &InvoiceItem{InvoiceID: inv.ResourceID, State: StatusComplete}, nil
+		}
+		if inv.Operation == OpTransactionGet {
+			return &InvoiceItem{}, nil
+		}
+		return next(ctx, inv)
+	})
+
+	invoice, err := client.GetInvoice("CACHED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoice.InvoiceID != "CACHED" || !invoice.IsCompleted() {
+		t.Errorf("Expected cached invoice, got %+v", invoice)
+	}
+
+	if _, err := client.GetTransaction("TX1"); err == nil || !strings.Contains(err.Error(), "interceptor returned") {
+		t.Errorf("Expected type mismatch error, got %v", err)
+	}
+}
+
+func TestInterceptorSeesDeactivateUpdate(t *testing.T) {
+	client := NewClient("pk", "token", true, false)
+	client.APIBaseURL = "http://127.0.0.1:0" // never reached
+
+	client.Use(func(ctx context.Context, inv *Invocation, next Handler) (interface{}, error) {
+		update, ok := inv.Request.(*PaymentLinkUpdate)
+		if !ok || update.IsActive == nil || *update.IsActive {
+			t.Errorf("Expected the deactivating update as the request, got %#v", inv.Request)
+		}
+		return &PaymentLink{LinkID: inv.ResourceID}, nil
+	})
+	if link, err := client.DeactivatePaymentLink("LNK1"); err != nil || link.LinkID != "LNK1" {
+		t.Errorf("Unexpected result %+v, %v", link, err)
+	}
+}
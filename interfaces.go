@@ -0,0 +1,79 @@
+package intasend
+
+// Collections covers receiving payments: hosted checkout, STK push and status checks
+type Collections interface {
+	CreateCheckoutLink(req *PaymentRequest, opts ...CallOption) (*PaymentResponse, error)
+	SendIntaSendXBPush(req *IntaSendXBPushRequest, opts ...CallOption) (*IntaSendXBPushResponse, error)
+	GetPaymentStatus(invoiceID string, opts ...CallOption) (*PaymentStatus, error)
+}
+
+// Payouts covers send-money disbursements
+type Payouts interface {
+	InitiateSendMoney(req *SendMoneyRequest, opts ...CallOption) (*SendMoneyResponse, error)
+	GetSendMoneyStatus(trackingID string, opts ...CallOption) (*SendMoneyResponse, error)
+}
+
+// Wallets covers wallet listing and wallet statements
+type Wallets interface {
+	ListWallets(params *ListWalletsParams, opts ...CallOption) (*PaginatedWallets, error)
+	ListWalletTransactions(walletID string, params *WalletTransactionsParams, opts ...CallOption) (*TransactionResp, error)
+}
+
+// Transactions covers the account-wide transaction ledger
+type Transactions interface {
+	ListTransactions(params *ListTransactionsParams, opts ...CallOption) (*TransactionResp, error)
+	GetTransaction(transactionID string, opts ...CallOption) (*Result, error)
+}
+
+// Invoices covers collection invoices
+type Invoices interface {
+	ListInvoices(params *ListInvoicesParams, opts ...CallOption) (*PaginatedInvoices, error)
+	GetInvoice(invoiceID string, opts ...CallOption) (*InvoiceItem, error)
+}
+
+// PaymentLinks covers reusable payment links
+type PaymentLinks interface {
+	CreatePaymentLink(req *PaymentLinkRequest, opts ...CallOption) (*PaymentLink, error)
+	ListPaymentLinks(params *ListPaymentLinksParams, opts ...CallOption) (*PaginatedPaymentLinks, error)
+	GetPaymentLink(linkID string, opts ...CallOption) (*PaymentLink, error)
+	UpdatePaymentLink(linkID string, update *PaymentLinkUpdate, opts ...CallOption) (*PaymentLink, error)
+	DeactivatePaymentLink(linkID string, opts ...CallOption) (*PaymentLink, error)
+}
+
+// Subscriptions covers billing plans, subscriptions and their recurring charges
+type Subscriptions interface {
+	CreatePlan(req *PlanRequest, opts ...CallOption) (*Plan, error)
+	ListPlans(params *ListPlansParams, opts ...CallOption) (*PaginatedPlans, error)
+	GetPlan(planID string, opts ...CallOption) (*Plan, error)
+	CreateSubscription(req *SubscriptionRequest, opts ...CallOption) (*Subscription, error)
+	ListSubscriptions(params *ListSubscriptionsParams, opts ...CallOption) (*PaginatedSubscriptions, error)
+	GetSubscription(subscriptionID string, opts ...CallOption) (*Subscription, error)
+	CancelSubscription(subscriptionID string, req *CancelSubscriptionRequest, opts ...CallOption) (*Subscription, error)
+	PauseSubscription(subscriptionID string, opts ...CallOption) (*Subscription, error)
+	ResumeSubscription(subscriptionID string, opts ...CallOption) (*Subscription, error)
+	ListSubscriptionCharges(subscriptionID string, params *ListChargesParams, opts ...CallOption) (*PaginatedCharges, error)
+}
+
+// Customers covers stored customer records
+type Customers interface {
+	CreateCustomer(req *CustomerRequest, opts ...CallOption) (*Customer, error)
+	ListCustomers(params *ListCustomersParams, opts ...CallOption) (*PaginatedCustomers, error)
+	SearchCustomers(query string, opts ...CallOption) (*PaginatedCustomers, error)
+	GetCustomer(customerID string, opts ...CallOption) (*Customer, error)
+	UpdateCustomer(customerID string, update *CustomerUpdate, opts ...CallOption) (*Customer, error)
+}
+
+// API is the full set of operations implemented by Client
+type API interface {
+	Collections
+	Payouts
+	Wallets
+	Transactions
+	Invoices
+	PaymentLinks
+	Subscriptions
+	Customers
+}
+
+// Ensure Client satisfies every domain interface
+var _ API = (*Client)(nil)
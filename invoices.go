@@ -26,6 +26,7 @@ type InvoiceItem struct {
 	FailedReason   *string   `json:"failed_reason"`
 	FailedCode     *string   `json:"failed_code"`
 	FailedCodeLink *string   `json:"failed_code_link"`
+	SubscriptionID string    `json:"subscription_id,omitempty"` // set when the invoice is a recurring subscription charge
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
@@ -40,15 +41,28 @@ type PaginatedInvoices struct {
 
 // ListInvoicesParams defines optional filters and pagination for listing invoices
 type ListInvoicesParams struct {
-	Page     *int   // optional page number
-	PageSize *int   // optional page size
-	State    string // optional filter by state (PENDING, COMPLETED, FAILED, etc.)
-	Currency string // optional filter by currency
-	APIRef   string // optional filter by API reference
+	Page      *int   // optional page number
+	PageSize  *int   // optional page size
+	State     string // optional filter by state (PENDING, COMPLETED, FAILED, etc.)
+	Currency  string // optional filter by currency
+	APIRef    string // optional filter by API reference
+	UpdatedAt string // optional filter by updated_at (today, yesterday, week, month, year)
 }
 
 // ListInvoices retrieves a paginated list of invoices with optional filters
-func (c *Client) ListInvoices(params *ListInvoicesParams) (*PaginatedInvoices, error) {
+func (c *Client) ListInvoices(params *ListInvoicesParams, opts ...CallOption) (*PaginatedInvoices, error) {
+	return invoke(c, &Invocation{Operation: OpInvoicesList, Request: params}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaginatedInvoices, error) {
+			params, err := requestAs[*ListInvoicesParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listInvoices(params, opts)
+		})
+}
+
+// listInvoices builds the query and fetches one page of invoices
+func (c *Client) listInvoices(params *ListInvoicesParams, opts []CallOption) (*PaginatedInvoices, error) {
 	if c.Token == "" {
 		return nil, fmt.Errorf("token is required to list invoices")
 	}
@@ -71,11 +85,19 @@ func (c *Client) ListInvoices(params *ListInvoicesParams) (*PaginatedInvoices, e
 		if params.APIRef != "" {
 			queryParams["api_ref"] = params.APIRef
 		}
+		if params.UpdatedAt != "" {
+			queryParams["updated_at"] = params.UpdatedAt
+		}
 	}
 
 	// Use the HTTP wrapper to make the request
 	var result PaginatedInvoices
-	err := c.GetJSON("api/v1/invoices/", queryParams, true, &result)
+	err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    "api/v1/invoices/",
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +106,15 @@ func (c *Client) ListInvoices(params *ListInvoicesParams) (*PaginatedInvoices, e
 }
 
 // GetInvoice retrieves a single invoice by its ID
-func (c *Client) GetInvoice(invoiceID string) (*InvoiceItem, error) {
+func (c *Client) GetInvoice(invoiceID string, opts ...CallOption) (*InvoiceItem, error) {
+	return invoke(c, &Invocation{Operation: OpInvoiceGet, ResourceID: invoiceID}, opts,
+		func(inv *Invocation, opts []CallOption) (*InvoiceItem, error) {
+			return c.getInvoice(inv.ResourceID, opts)
+		})
+}
+
+// getInvoice fetches a single invoice
+func (c *Client) getInvoice(invoiceID string, opts []CallOption) (*InvoiceItem, error) {
 	if c.Token == "" {
 		return nil, fmt.Errorf("token is required to get invoice")
 	}
@@ -95,7 +125,7 @@ func (c *Client) GetInvoice(invoiceID string) (*InvoiceItem, error) {
 	// Use the common HTTP JSON helper
 	endpoint := fmt.Sprintf("api/v1/invoices/%s/", url.PathEscape(invoiceID))
 	var invoice InvoiceItem
-	if err := c.GetJSON(endpoint, nil, true, &invoice); err != nil {
+	if err := c.call(&RequestOptions{Method: GET, Endpoint: endpoint, UseToken: true}, &invoice, opts); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,94 @@
+package intasend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment identifies the IntaSend environment a client or key belongs to
+type Environment string
+
+// IntaSend environments
+const (
+	EnvironmentSandbox Environment = "sandbox"
+	EnvironmentLive    Environment = "live"
+	EnvironmentUnknown Environment = "unknown" // key without a recognised prefix
+)
+
+// Key prefixes IntaSend issues per environment
+const (
+	PublishableKeyTestPrefix = "ISPubKey_test_"
+	PublishableKeyLivePrefix = "ISPubKey_live_"
+	SecretKeyTestPrefix      = "ISSecretKey_test_"
+	SecretKeyLivePrefix      = "ISSecretKey_live_"
+)
+
+// KeyEnvironment returns the environment a publishable key or secret token
+// was issued for, judging by its prefix
+func KeyEnvironment(key string) Environment {
+	switch {
+	case strings.HasPrefix(key, PublishableKeyTestPrefix), strings.HasPrefix(key, SecretKeyTestPrefix):
+		return EnvironmentSandbox
+	case strings.HasPrefix(key, PublishableKeyLivePrefix), strings.HasPrefix(key, SecretKeyLivePrefix):
+		return EnvironmentLive
+	default:
+		return EnvironmentUnknown
+	}
+}
+
+// Environment returns the environment the client sends requests to
+func (c *Client) Environment() Environment {
+	if c.Test {
+		return EnvironmentSandbox
+	}
+	return EnvironmentLive
+}
+
+// KeyMismatchError is returned when a key was issued for a different
+// environment than the one the client targets. IntaSend would reject such a
+// request with a 401, so VerifyCredentials, and every request when
+// Client.StrictKeys is set, fails before sending it.
+type KeyMismatchError struct {
+	Key            string      // "publishable key" or "token"
+	KeyEnvironment Environment // environment the key was issued for
+	Environment    Environment // environment the client targets
+}
+
+func (e *KeyMismatchError) Error() string {
+	hint := "set Test to false"
+	if e.Environment == EnvironmentLive {
+		hint = "set Test to true"
+	}
+	return fmt.Sprintf("%s is a %s key but the client targets %s (%s or use %s keys)",
+		e.Key, e.KeyEnvironment, e.Environment, hint, e.Environment)
+}
+
+// CheckKeys compares the prefixes of the configured keys with the client's
+// environment. Keys without a recognised prefix are not checked.
+func (c *Client) CheckKeys() error {
+	if err := c.checkKey("publishable key", c.PublishableKey); err != nil {
+		return err
+	}
+	return c.checkKey("token", c.Token)
+}
+
+func (c *Client) checkKey(name, key string) error {
+	keyEnv := KeyEnvironment(key)
+	if keyEnv == EnvironmentUnknown || keyEnv == c.Environment() {
+		return nil
+	}
+	return &KeyMismatchError{Key: name, KeyEnvironment: keyEnv, Environment: c.Environment()}
+}
+
+// checkRequestKeys checks only the keys a request is about to send
+func (c *Client) checkRequestKeys(opts *RequestOptions) error {
+	if opts.UseAPIKey {
+		if err := c.checkKey("publishable key", c.PublishableKey); err != nil {
+			return err
+		}
+	}
+	if opts.UseToken {
+		return c.checkKey("token", c.Token)
+	}
+	return nil
+}
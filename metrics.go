@@ -0,0 +1,57 @@
+package intasend
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Metrics receives business events from the client. Implementations must be
+// safe for concurrent use; see the intasendprom package for a Prometheus adapter.
+type Metrics interface {
+	CheckoutCreated(currency CurrencyType)
+	STKPushSent(currency CurrencyType)
+	PaymentOutcome(state, provider string)
+	PayoutBatch(status string)
+	WebhookDelivery(accepted bool, reason string)
+}
+
+// Webhook rejection reasons reported to Metrics.WebhookDelivery
+const (
+	WebhookRejectMalformed         = "malformed"
+	WebhookRejectChallengeMismatch = "challenge_mismatch"
+)
+
+// PayoutBatchSubmitFailed is the status reported to Metrics.PayoutBatch when
+// a send-money batch could not be submitted
+const PayoutBatchSubmitFailed = "SUBMIT_FAILED"
+
+// ErrInvalidChallenge is returned when a webhook's challenge does not match the expected value
+var ErrInvalidChallenge = errors.New("webhook challenge mismatch")
+
+// ParseCollectionCallback decodes a collection webhook body and verifies its
+// challenge. Pass an empty challenge to skip verification. Accepted and
+// rejected deliveries, and the payment outcome they carry, are reported to
+// the client's Metrics.
+func (c *Client) ParseCollectionCallback(data []byte, challenge string) (*CollectionCallback, error) {
+	cb, err := UnmarshalCollectionCallback(data)
+	if err != nil {
+		c.recordWebhook(false, WebhookRejectMalformed)
+		return nil, fmt.Errorf("failed to parse callback: %w", err)
+	}
+	if challenge != "" && cb.Challenge != challenge {
+		c.recordWebhook(false, WebhookRejectChallengeMismatch)
+		return nil, ErrInvalidChallenge
+	}
+
+	c.recordWebhook(true, "")
+	if c.Metrics != nil {
+		c.Metrics.PaymentOutcome(cb.State, cb.Provider)
+	}
+	return &cb, nil
+}
+
+func (c *Client) recordWebhook(accepted bool, reason string) {
+	if c.Metrics != nil {
+		c.Metrics.WebhookDelivery(accepted, reason)
+	}
+}
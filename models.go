@@ -7,6 +7,7 @@ import (
 
 // PaymentRequest represents the payment checkout request payload
 type PaymentRequest struct {
+	CustomerID   string            `json:"customer_id,omitempty"` // optional, an existing customer whose details prefill checkout
 	PhoneNumber  string            `json:"phone_number,omitempty"`
 	Email        string            `json:"email,omitempty"`
 	Amount       float64           `json:"amount,omitempty"`
@@ -211,12 +212,14 @@ type CollectionCallback struct {
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 	Challenge      string    `json:"challenge"`
+	SubscriptionID string    `json:"subscription_id,omitempty"` // set for recurring subscription charges
 }
 
 // IntaSendXBPushRequest represents the payload for initiating an IntaSend-XB STK push
 type IntaSendXBPushRequest struct {
 	Amount       string       `json:"amount"`
-	PhoneNumber  string       `json:"phone_number"`
+	PhoneNumber  string       `json:"phone_number,omitempty"` // required unless CustomerID is set
+	CustomerID   string       `json:"customer_id,omitempty"`  // optional, an existing customer to charge
 	Currency     CurrencyType `json:"currency"`
 	APIRef       string       `json:"api_ref,omitempty"`
 	WalletID     string       `json:"wallet_id,omitempty"`
@@ -225,28 +228,14 @@ type IntaSendXBPushRequest struct {
 
 // IntaSendXBPushResponse represents the response from the IntaSend-XB STK push endpoint
 type IntaSendXBPushResponse struct {
-	ID              string             `json:"id"`
-	Invoice         InvoiceTx          `json:"invoice"`
-	Customer        IntaSendXBCustomer `json:"customer"`
-	PaymentLink     *string            `json:"payment_link"`
-	CustomerComment *string            `json:"customer_comment"`
-	Refundable      bool               `json:"refundable"`
-	CreatedAt       time.Time          `json:"created_at"`
-	UpdatedAt       time.Time          `json:"updated_at"`
-}
-
-// IntaSendXBCustomer holds customer info returned in the IntaSend-XB push response
-type IntaSendXBCustomer struct {
-	CustomerID  string    `json:"customer_id"`
-	PhoneNumber string    `json:"phone_number"`
-	Email       *string   `json:"email"`
-	FirstName   *string   `json:"first_name"`
-	LastName    *string   `json:"last_name"`
-	Country     *string   `json:"country"`
-	ZipCode     *string   `json:"zipcode"`
-	Provider    string    `json:"provider"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              string    `json:"id"`
+	Invoice         InvoiceTx `json:"invoice"`
+	Customer        Customer  `json:"customer"`
+	PaymentLink     *string   `json:"payment_link"`
+	CustomerComment *string   `json:"customer_comment"`
+	Refundable      bool      `json:"refundable"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 type TransactionResp struct {
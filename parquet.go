@@ -0,0 +1,337 @@
+package intasend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// The Parquet writer below produces just enough of the format for flat
+// exports: required columns of UTF-8 strings, doubles and millisecond
+// timestamps, PLAIN encoded and uncompressed, one data page per column per
+// row group. Metadata is written with the Thrift compact protocol.
+
+type parquetKind int
+
+const (
+	parquetString parquetKind = iota
+	parquetDouble
+	parquetTimestamp
+)
+
+// Parquet enum values used in the metadata
+const (
+	parquetTypeInt64          = 2
+	parquetTypeDouble         = 5
+	parquetTypeByteArray      = 6
+	parquetRequired           = 0
+	parquetConvertedUTF8      = 0
+	parquetConvertedTimestamp = 9 // TIMESTAMP_MILLIS
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageData           = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+type parquetColumn struct {
+	name string
+	kind parquetKind
+}
+
+type parquetColumnChunk struct {
+	offset int64
+	size   int64
+}
+
+type parquetRowGroup struct {
+	rows    int64
+	size    int64
+	columns []parquetColumnChunk
+}
+
+// parquetWriter buffers one row group at a time; call flush to bound memory
+type parquetWriter struct {
+	w      io.Writer
+	cols   []parquetColumn
+	values []bytes.Buffer
+	rows   int64
+	offset int64
+	groups []parquetRowGroup
+}
+
+func newParquetWriter(w io.Writer, cols []parquetColumn) *parquetWriter {
+	return &parquetWriter{w: w, cols: cols, values: make([]bytes.Buffer, len(cols))}
+}
+
+// writeRow appends one row; values must match the column kinds in order
+func (p *parquetWriter) writeRow(values ...any) error {
+	if len(values) != len(p.cols) {
+		return fmt.Errorf("parquet: got %d values for %d columns", len(values), len(p.cols))
+	}
+	if p.offset == 0 {
+		if err := p.write(parquetMagic); err != nil {
+			return err
+		}
+	}
+	var scratch [8]byte
+	for i, v := range values {
+		buf := &p.values[i]
+		switch p.cols[i].kind {
+		case parquetString:
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("parquet: column %s expects a string, got %T", p.cols[i].name, v)
+			}
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(s)))
+			buf.Write(scratch[:4])
+			buf.WriteString(s)
+		case parquetDouble:
+			f, ok := v.(float64)
+			if !ok {
+				return fmt.Errorf("parquet: column %s expects a float64, got %T", p.cols[i].name, v)
+			}
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(f))
+			buf.Write(scratch[:])
+		case parquetTimestamp:
+			t, ok := v.(time.Time)
+			if !ok {
+				return fmt.Errorf("parquet: column %s expects a time.Time, got %T", p.cols[i].name, v)
+			}
+			binary.LittleEndian.PutUint64(scratch[:], uint64(t.UnixMilli()))
+			buf.Write(scratch[:])
+		}
+	}
+	p.rows++
+	return nil
+}
+
+// flush writes the buffered rows as a row group
+func (p *parquetWriter) flush() error {
+	if p.rows == 0 {
+		return nil
+	}
+	group := parquetRowGroup{rows: p.rows}
+	for i := range p.cols {
+		data := p.values[i].Bytes()
+		var header thriftWriter
+		header.i32(1, parquetPageData)
+		header.i32(2, int32(len(data)))
+		header.i32(3, int32(len(data)))
+		header.beginStruct(5) // data_page_header
+		header.i32(1, int32(p.rows))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.endStruct()
+		header.stop()
+
+		chunk := parquetColumnChunk{offset: p.offset, size: int64(header.buf.Len() + len(data))}
+		if err := p.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(data); err != nil {
+			return err
+		}
+		group.columns = append(group.columns, chunk)
+		group.size += chunk.size
+		p.values[i].Reset()
+	}
+	p.groups = append(p.groups, group)
+	p.rows = 0
+	return nil
+}
+
+// close flushes the last row group and writes the footer
+func (p *parquetWriter) close() error {
+	if p.offset == 0 {
+		if err := p.write(parquetMagic); err != nil {
+			return err
+		}
+	}
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	var total int64
+	for _, g := range p.groups {
+		total += g.rows
+	}
+	var meta thriftWriter
+	meta.i32(1, 1) // version
+	meta.beginList(2, thriftStruct, len(p.cols)+1)
+	meta.beginElem()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(p.cols)))
+	meta.endElem()
+	for _, col := range p.cols {
+		meta.beginElem()
+		switch col.kind {
+		case parquetString:
+			meta.i32(1, parquetTypeByteArray)
+		case parquetDouble:
+			meta.i32(1, parquetTypeDouble)
+		case parquetTimestamp:
+			meta.i32(1, parquetTypeInt64)
+		}
+		meta.i32(3, parquetRequired)
+		meta.binary(4, col.name)
+		switch col.kind {
+		case parquetString:
+			meta.i32(6, parquetConvertedUTF8)
+		case parquetTimestamp:
+			meta.i32(6, parquetConvertedTimestamp)
+		}
+		meta.endElem()
+	}
+	meta.i64(3, total)
+	meta.beginList(4, thriftStruct, len(p.groups))
+	for _, g := range p.groups {
+		meta.beginElem()
+		meta.beginList(1, thriftStruct, len(g.columns))
+		for i, c := range g.columns {
+			col := p.cols[i]
+			meta.beginElem()
+			meta.i64(2, c.offset)
+			meta.beginStruct(3) // meta_data
+			switch col.kind {
+			case parquetString:
+				meta.i32(1, parquetTypeByteArray)
+			case parquetDouble:
+				meta.i32(1, parquetTypeDouble)
+			case parquetTimestamp:
+				meta.i32(1, parquetTypeInt64)
+			}
+			meta.beginList(2, thriftI32, 2)
+			meta.varint(zigzag(parquetEncodingPlain))
+			meta.varint(zigzag(parquetEncodingRLE))
+			meta.beginList(3, thriftBinary, 1)
+			meta.rawBinary(col.name)
+			meta.i32(4, parquetCodecUncompressed)
+			meta.i64(5, g.rows)
+			meta.i64(6, c.size)
+			meta.i64(7, c.size)
+			meta.i64(9, c.offset)
+			meta.endStruct()
+			meta.endElem()
+		}
+		meta.i64(2, g.size)
+		meta.i64(3, g.rows)
+		meta.endElem()
+	}
+	meta.binary(6, "intasend-sdk-golang")
+	meta.stop()
+
+	footer := meta.buf.Bytes()
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	for _, b := range [][]byte{footer, length[:], parquetMagic} {
+		if err := p.write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol, tracking
+// the last field ID of each nested struct for delta encoding
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16
+	cur  int16
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.cur; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag(int64(id)))
+	}
+	t.cur = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.rawBinary(s)
+}
+
+func (t *thriftWriter) rawBinary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+	} else {
+		t.buf.WriteByte(0xf0 | elem)
+		t.varint(uint64(size))
+	}
+}
+
+// beginStruct starts a struct-typed field
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginElem()
+}
+
+func (t *thriftWriter) endStruct() {
+	t.endElem()
+}
+
+// beginElem starts a struct that is a list element
+func (t *thriftWriter) beginElem() {
+	t.last = append(t.last, t.cur)
+	t.cur = 0
+}
+
+func (t *thriftWriter) endElem() {
+	t.stop()
+	t.cur = t.last[len(t.last)-1]
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	t.buf.Write(b[:n])
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
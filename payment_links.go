@@ -0,0 +1,255 @@
+package intasend
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// PaymentLinkRequest is the payload for creating a reusable payment link
+type PaymentLinkRequest struct {
+	Title        string       `json:"title"`
+	Currency     CurrencyType `json:"currency"`
+	Amount       float64      `json:"amount,omitempty"` // fixed amount; leave 0 to let the customer enter any amount
+	Description  string       `json:"description,omitempty"`
+	RedirectURL  string       `json:"redirect_url,omitempty"`
+	MobileTarrif TarriffType  `json:"mobile_tarrif,omitempty"`
+	CardTarrif   TarriffType  `json:"card_tarrif,omitempty"`
+	ExpiresAt    *time.Time   `json:"expires_at,omitempty"` // optional, the link stops accepting payments after this time
+	Styles       *Styles      `json:"styles,omitempty"`     // optional checkout branding
+}
+
+// PaymentLinkUpdate holds the fields to change on a payment link; nil fields are left as they are
+type PaymentLinkUpdate struct {
+	Title        *string      `json:"title,omitempty"`
+	Amount       *float64     `json:"amount,omitempty"` // set to 0 to switch to an open amount
+	Description  *string      `json:"description,omitempty"`
+	RedirectURL  *string      `json:"redirect_url,omitempty"`
+	MobileTarrif *TarriffType `json:"mobile_tarrif,omitempty"`
+	CardTarrif   *TarriffType `json:"card_tarrif,omitempty"`
+	ExpiresAt    *time.Time   `json:"expires_at,omitempty"`
+	IsActive     *bool        `json:"is_active,omitempty"`
+	Styles       *Styles      `json:"styles,omitempty"`
+}
+
+// PaymentLink represents a reusable, shareable payment link
+type PaymentLink struct {
+	LinkID       string     `json:"link_id"`
+	Title        string     `json:"title"`
+	URL          string     `json:"url"`
+	Currency     string     `json:"currency"`
+	Amount       float64    `json:"amount"` // 0 for open-amount links
+	Description  string     `json:"description"`
+	RedirectURL  string     `json:"redirect_url"`
+	MobileTarrif string     `json:"mobile_tarrif"`
+	CardTarrif   string     `json:"card_tarrif"`
+	IsActive     bool       `json:"is_active"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Styles       *Styles    `json:"styles"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// PaginatedPaymentLinks represents the paginated response for listing payment links
+type PaginatedPaymentLinks struct {
+	Count    int           `json:"count"`
+	Next     *string       `json:"next"`
+	Previous *string       `json:"previous"`
+	Results  []PaymentLink `json:"results"`
+}
+
+// ListPaymentLinksParams defines optional filters and pagination for listing payment links
+type ListPaymentLinksParams struct {
+	Page     *int   // optional page number
+	PageSize *int   // optional page size
+	Currency string // optional filter by currency
+	IsActive *bool  // optional filter by active state
+}
+
+// CreatePaymentLink creates a reusable payment link
+func (c *Client) CreatePaymentLink(req *PaymentLinkRequest, opts ...CallOption) (*PaymentLink, error) {
+	return invoke(c, &Invocation{Operation: OpPaymentLinkCreate, Request: req}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaymentLink, error) {
+			req, err := requestAs[*PaymentLinkRequest](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.createPaymentLink(req, opts)
+		})
+}
+
+// createPaymentLink validates the request and posts it to the payment links endpoint
+func (c *Client) createPaymentLink(req *PaymentLinkRequest, opts []CallOption) (*PaymentLink, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to create payment links")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	if req.Title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	if req.Currency == "" {
+		return nil, fmt.Errorf("currency is required")
+	}
+	if err := req.Currency.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Amount < 0 {
+		return nil, fmt.Errorf("amount cannot be negative")
+	}
+
+	var link PaymentLink
+	err := c.call(&RequestOptions{
+		Method:   POST,
+		Endpoint: "api/v1/paymentlinks/",
+		Body:     req,
+		UseToken: true,
+	}, &link, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ListPaymentLinks retrieves a paginated list of payment links with optional filters
+func (c *Client) ListPaymentLinks(params *ListPaymentLinksParams, opts ...CallOption) (*PaginatedPaymentLinks, error) {
+	return invoke(c, &Invocation{Operation: OpPaymentLinksList, Request: params}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaginatedPaymentLinks, error) {
+			params, err := requestAs[*ListPaymentLinksParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listPaymentLinks(params, opts)
+		})
+}
+
+// listPaymentLinks builds the query and fetches one page of payment links
+func (c *Client) listPaymentLinks(params *ListPaymentLinksParams, opts []CallOption) (*PaginatedPaymentLinks, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to list payment links")
+	}
+
+	queryParams := make(map[string]string)
+	if params != nil {
+		setPageParams(queryParams, params.Page, params.PageSize)
+		if params.Currency != "" {
+			queryParams["currency"] = params.Currency
+		}
+		if params.IsActive != nil {
+			queryParams["is_active"] = strconv.FormatBool(*params.IsActive)
+		}
+	}
+
+	var result PaginatedPaymentLinks
+	err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    "api/v1/paymentlinks/",
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &result, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPaymentLink retrieves a single payment link by its ID
+func (c *Client) GetPaymentLink(linkID string, opts ...CallOption) (*PaymentLink, error) {
+	return invoke(c, &Invocation{Operation: OpPaymentLinkGet, ResourceID: linkID}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaymentLink, error) {
+			return c.getPaymentLink(inv.ResourceID, opts)
+		})
+}
+
+// getPaymentLink fetches a single payment link
+func (c *Client) getPaymentLink(linkID string, opts []CallOption) (*PaymentLink, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to get payment link")
+	}
+	if linkID == "" {
+		return nil, fmt.Errorf("linkID is required")
+	}
+
+	var link PaymentLink
+	if err := c.call(&RequestOptions{Method: GET, Endpoint: paymentLinkEndpoint(linkID), UseToken: true}, &link, opts); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// UpdatePaymentLink changes the non-nil fields of a payment link
+func (c *Client) UpdatePaymentLink(linkID string, update *PaymentLinkUpdate, opts ...CallOption) (*PaymentLink, error) {
+	return invoke(c, &Invocation{Operation: OpPaymentLinkUpdate, Request: update, ResourceID: linkID}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaymentLink, error) {
+			update, err := requestAs[*PaymentLinkUpdate](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.updatePaymentLink(inv.ResourceID, update, opts)
+		})
+}
+
+// DeactivatePaymentLink stops a payment link from accepting further payments
+func (c *Client) DeactivatePaymentLink(linkID string, opts ...CallOption) (*PaymentLink, error) {
+	inactive := false
+	update := &PaymentLinkUpdate{IsActive: &inactive}
+	return invoke(c, &Invocation{Operation: OpPaymentLinkDeactivate, Request: update, ResourceID: linkID}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaymentLink, error) {
+			update, err := requestAs[*PaymentLinkUpdate](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.updatePaymentLink(inv.ResourceID, update, opts)
+		})
+}
+
+// updatePaymentLink sends a partial update for a payment link
+func (c *Client) updatePaymentLink(linkID string, update *PaymentLinkUpdate, opts []CallOption) (*PaymentLink, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to update payment link")
+	}
+	if linkID == "" {
+		return nil, fmt.Errorf("linkID is required")
+	}
+	if update == nil {
+		return nil, fmt.Errorf("update is required")
+	}
+	if update.Amount != nil && *update.Amount < 0 {
+		return nil, fmt.Errorf("amount cannot be negative")
+	}
+
+	var link PaymentLink
+	err := c.call(&RequestOptions{
+		Method:   PATCH,
+		Endpoint: paymentLinkEndpoint(linkID),
+		Body:     update,
+		UseToken: true,
+	}, &link, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func paymentLinkEndpoint(linkID string) string {
+	return fmt.Sprintf("api/v1/paymentlinks/%s/", url.PathEscape(linkID))
+}
+
+// Helper methods for PaymentLink
+
+// IsOpenAmount reports whether the customer chooses the amount to pay
+func (l *PaymentLink) IsOpenAmount() bool {
+	return l.Amount == 0
+}
+
+// IsExpired reports whether the link's expiry has passed at the given time
+func (l *PaymentLink) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// AcceptsPayments reports whether the link is active and not expired
+func (l *PaymentLink) AcceptsPayments(now time.Time) bool {
+	return l.IsActive && !l.IsExpired(now)
+}
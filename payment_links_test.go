@@ -0,0 +1,108 @@
+package intasend
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// requestCase is a client call and the HTTP request it should send
+type requestCase struct {
+	name   string
+	call   func(c *Client) error
+	method string
+	path   string
+	query  string // encoded with sorted keys, as url.Values does
+	body   string // JSON, empty for no body
+}
+
+// runRequestCases makes each call against a server answering response and
+// checks the method, path, query and body it received
+func runRequestCases(t *testing.T, response string, cases []requestCase) {
+	t.Helper()
+	var method, path, query, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, query, body = r.Method, r.URL.EscapedPath(), r.URL.RawQuery, string(data)
+		w.Write([]byte(response))
+	}))
+	defer srv.Close()
+
+	client := NewClient("pk", "token", true, false)
+	client.BaseURL = srv.URL
+
+	for _, tc := range cases {
+		method, path, query, body = "", "", "", ""
+		if err := tc.call(client); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if method != tc.method || path != tc.path || query != tc.query {
+			t.Errorf("%s: expected %s %s?%s, got %s %s?%s", tc.name, tc.method, tc.path, tc.query, method, path, query)
+		}
+		if strings.TrimSpace(body) != tc.body {
+			t.Errorf("%s: expected body %s, got %s", tc.name, tc.body, body)
+		}
+	}
+}
+
+func TestPaymentLinkRequests(t *testing.T) {
+	page, size, active := 2, 50, true
+	title := "Donations"
+	runRequestCases(t, `{"link_id":"LNK1"}`, []requestCase{
+		{
+			name: "create",
+			call: func(c *Client) error {
+				_, err := c.CreatePaymentLink(&PaymentLinkRequest{Title: "Donations", Currency: CurrencyKES, Amount: 500})
+				return err
+			},
+			method: "POST",
+			path:   "/api/v1/paymentlinks/",
+			body:   `{"title":"Donations","currency":"KES","amount":500}`,
+		},
+		{
+			name: "list",
+			call: func(c *Client) error {
+				_, err := c.ListPaymentLinks(&ListPaymentLinksParams{Page: &page, PageSize: &size, Currency: "KES", IsActive: &active})
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/paymentlinks/",
+			query:  "currency=KES&is_active=true&page=2&page_size=50",
+		},
+		{
+			name: "get",
+			call: func(c *Client) error {
+				link, err := c.GetPaymentLink("LNK/1")
+				if err == nil && link.LinkID != "LNK1" {
+					t.Errorf("Expected the response to be decoded, got %+v", link)
+				}
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/paymentlinks/LNK%2F1/",
+		},
+		{
+			name: "update",
+			call: func(c *Client) error {
+				_, err := c.UpdatePaymentLink("LNK1", &PaymentLinkUpdate{Title: &title})
+				return err
+			},
+			method: "PATCH",
+			path:   "/api/v1/paymentlinks/LNK1/",
+			body:   `{"title":"Donations"}`,
+		},
+		{
+			name: "deactivate",
+			call: func(c *Client) error {
+				_, err := c.DeactivatePaymentLink("LNK1")
+				return err
+			},
+			method: "PATCH",
+			path:   "/api/v1/paymentlinks/LNK1/",
+			body:   `{"is_active":false}`,
+		},
+	})
+}
@@ -38,23 +38,6 @@ type Meta struct {
 	UpdatedAt       string   `json:"updated_at"`       // Meta update timestamp
 }
 
-// Customer represents customer information in the payment status
-type Customer struct {
-	ID          string `json:"id"`           // Customer ID (e.g., "ZOEW022")
-	PhoneNumber string `json:"phone_number"` // Customer phone number
-	Email       string `json:"email"`        // Customer email address
-	FirstName   string `json:"first_name"`   // Customer first name
-	LastName    string `json:"last_name"`    // Customer last name
-	Country     string `json:"country"`      // Country code (e.g., "KE")
-	Address     string `json:"address"`      // Customer address
-	City        string `json:"city"`         // Customer city
-	State       string `json:"state"`        // Customer state/province
-	ZipCode     string `json:"zipcode"`      // Customer zip/postal code
-	Provider    string `json:"provider"`     // Payment provider used
-	CreatedAt   string `json:"created_at"`   // Customer record creation timestamp
-	UpdatedAt   string `json:"updated_at"`   // Customer record update timestamp
-}
-
 // Payment status constants
 const (
 	StatusPending    = "PENDING"    // Payment is pending/waiting for completion
@@ -145,16 +128,7 @@ func (ps *PaymentStatus) GetCustomerPhone() string {
 
 // GetCustomerName returns the customer's full name
 func (ps *PaymentStatus) GetCustomerName() string {
-	firstName := ps.Meta.Customer.FirstName
-	lastName := ps.Meta.Customer.LastName
-	if firstName != "" && lastName != "" {
-		return firstName + " " + lastName
-	} else if firstName != "" {
-		return firstName
-	} else if lastName != "" {
-		return lastName
-	}
-	return ""
+	return ps.Meta.Customer.FullName()
 }
 
 // GetInvoiceID returns the invoice ID for tracking
@@ -0,0 +1,391 @@
+package intasend
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultPayoutBatchSize is the number of transactions put in each send-money
+// request when a payout file is split into batches
+const DefaultPayoutBatchSize = 100
+
+// MaxPayoutAmount is the largest amount ParsePayoutCSV and ParsePayoutXLSX
+// accept for one transaction. It catches typos and exponent notation long
+// before IntaSend's own limits would.
+const MaxPayoutAmount = 1_000_000_000
+
+// PayoutColumns names the spreadsheet column that holds each transaction
+// field. Matching is case-insensitive and ignores surrounding spaces; leave a
+// field empty if the file has no such column.
+type PayoutColumns struct {
+	Name             string
+	Account          string // required
+	Amount           string // required
+	Narrative        string
+	IDNumber         string
+	BankCode         string
+	AccountType      string
+	AccountReference string
+	CategoryName     string
+}
+
+// DefaultPayoutColumns uses the SendMoneyTransaction JSON field names as headers
+var DefaultPayoutColumns = PayoutColumns{
+	Name:             "name",
+	Account:          "account",
+	Amount:           "amount",
+	Narrative:        "narrative",
+	IDNumber:         "id_number",
+	BankCode:         "bank_code",
+	AccountType:      "account_type",
+	AccountReference: "account_reference",
+	CategoryName:     "category_name",
+}
+
+// PayoutImportOptions controls how a payout file is read and validated
+type PayoutImportOptions struct {
+	Columns  *PayoutColumns    // optional; every column it names must be present. Defaults to DefaultPayoutColumns, of which only account and amount are required
+	Provider SendMoneyProvider // optional, enables provider-specific checks such as required bank codes
+	Sheet    string            // optional XLSX sheet name, defaults to the first sheet
+}
+
+// PayoutRowError describes why one row of a payout file was rejected
+type PayoutRowError struct {
+	Row    int    // 1-based row number in the file, counting the header row
+	Column string // header of the offending column, if the error concerns one
+	Err    error
+}
+
+func (e *PayoutRowError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("row %d: %s: %v", e.Row, e.Column, e.Err)
+	}
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *PayoutRowError) Unwrap() error {
+	return e.Err
+}
+
+// PayoutImportError collects every invalid row of a payout file
+type PayoutImportError struct {
+	Rows []*PayoutRowError
+}
+
+func (e *PayoutImportError) Error() string {
+	const shown = 5
+	msgs := make([]string, 0, shown)
+	for i, row := range e.Rows {
+		if i == shown {
+			msgs = append(msgs, fmt.Sprintf("and %d more", len(e.Rows)-shown))
+			break
+		}
+		msgs = append(msgs, row.Error())
+	}
+	return fmt.Sprintf("%d invalid payout row(s): %s", len(e.Rows), strings.Join(msgs, "; "))
+}
+
+// Unwrap exposes the row errors to errors.Is and errors.As
+func (e *PayoutImportError) Unwrap() []error {
+	errs := make([]error, len(e.Rows))
+	for i, row := range e.Rows {
+		errs[i] = row
+	}
+	return errs
+}
+
+// PayoutImport is the validated content of a payout file
+type PayoutImport struct {
+	Transactions []SendMoneyTransaction
+	Rows         []int   // source row number of each transaction
+	Total        float64 // sum of all amounts
+}
+
+// LoadPayoutFile reads a payout file, choosing the format from its extension
+// (.csv or .xlsx)
+func LoadPayoutFile(path string, opts *PayoutImportOptions) (*PayoutImport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return ParsePayoutCSV(f, opts)
+	case ".xlsx":
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return ParsePayoutXLSX(f, info.Size(), opts)
+	default:
+		return nil, fmt.Errorf("unsupported payout file extension %q (use .csv or .xlsx)", ext)
+	}
+}
+
+// ParsePayoutCSV reads payout transactions from CSV with a header row
+func ParsePayoutCSV(r io.Reader, opts *PayoutImportOptions) (*PayoutImport, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payout CSV: %w", err)
+	}
+	return parsePayoutRows(records, opts)
+}
+
+// ParsePayoutXLSX reads payout transactions from an Excel workbook whose
+// first row is a header row
+func ParsePayoutXLSX(r io.ReaderAt, size int64, opts *PayoutImportOptions) (*PayoutImport, error) {
+	var sheet string
+	if opts != nil {
+		sheet = opts.Sheet
+	}
+	records, err := readXLSXSheet(r, size, sheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payout workbook: %w", err)
+	}
+	return parsePayoutRows(records, opts)
+}
+
+// payoutField ties a mapped column to the transaction field it fills
+type payoutField struct {
+	header string
+	set    func(tx *SendMoneyTransaction, v string)
+}
+
+func parsePayoutRows(records [][]string, opts *PayoutImportOptions) (*PayoutImport, error) {
+	if opts == nil {
+		opts = &PayoutImportOptions{}
+	}
+	cols := DefaultPayoutColumns
+	if opts.Columns != nil {
+		cols = *opts.Columns
+	}
+	if cols.Account == "" || cols.Amount == "" {
+		return nil, fmt.Errorf("account and amount columns are required")
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("payout file is empty")
+	}
+
+	index := make(map[string]int)
+	for i, h := range records[0] {
+		index[normalizeHeader(h)] = i
+	}
+	fields := []payoutField{
+		{cols.Name, func(tx *SendMoneyTransaction, v string) { tx.Name = v }},
+		{cols.Account, func(tx *SendMoneyTransaction, v string) { tx.Account = v }},
+		{cols.Amount, func(tx *SendMoneyTransaction, v string) { tx.Amount = v }},
+		{cols.Narrative, func(tx *SendMoneyTransaction, v string) { tx.Narrative = v }},
+		{cols.IDNumber, func(tx *SendMoneyTransaction, v string) { tx.IDNumber = v }},
+		{cols.BankCode, func(tx *SendMoneyTransaction, v string) { tx.BankCode = v }},
+		{cols.AccountType, func(tx *SendMoneyTransaction, v string) { tx.AccountType = SendMoneyAccountType(v) }},
+		{cols.AccountReference, func(tx *SendMoneyTransaction, v string) { tx.AccountReference = v }},
+		{cols.CategoryName, func(tx *SendMoneyTransaction, v string) { tx.CategoryName = v }},
+	}
+	columns := make([]int, len(fields))
+	var missing []string
+	for i, f := range fields {
+		columns[i] = -1
+		if f.header == "" {
+			continue
+		}
+		col, ok := index[normalizeHeader(f.header)]
+		if !ok {
+			missing = append(missing, f.header)
+			continue
+		}
+		columns[i] = col
+	}
+	// Only the required columns must be present; optional ones that are
+	// mapped by default are commonly left out of spreadsheets
+	for _, h := range missing {
+		if h == cols.Account || h == cols.Amount || opts.Columns != nil {
+			return nil, fmt.Errorf("column %q not found in header row", h)
+		}
+	}
+
+	result := &PayoutImport{}
+	importErr := &PayoutImportError{}
+	for i, record := range records[1:] {
+		row := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+		var tx SendMoneyTransaction
+		for j, f := range fields {
+			if col := columns[j]; col >= 0 && col < len(record) {
+				f.set(&tx, strings.TrimSpace(record[col]))
+			}
+		}
+		amount, rowErrs := validatePayoutRow(&tx, row, cols, opts.Provider)
+		if len(rowErrs) > 0 {
+			importErr.Rows = append(importErr.Rows, rowErrs...)
+			continue
+		}
+		result.Transactions = append(result.Transactions, tx)
+		result.Rows = append(result.Rows, row)
+		result.Total += amount
+	}
+	if len(importErr.Rows) > 0 {
+		return nil, importErr
+	}
+	if len(result.Transactions) == 0 {
+		return nil, fmt.Errorf("payout file has no transactions")
+	}
+	return result, nil
+}
+
+// validatePayoutRow checks and normalises one transaction, returning its amount
+func validatePayoutRow(tx *SendMoneyTransaction, row int, cols PayoutColumns, provider SendMoneyProvider) (float64, []*PayoutRowError) {
+	var errs []*PayoutRowError
+	fail := func(column string, err error) {
+		errs = append(errs, &PayoutRowError{Row: row, Column: column, Err: err})
+	}
+
+	if tx.AccountType != "" {
+		accountType, ok := parseAccountType(string(tx.AccountType))
+		if !ok {
+			fail(cols.AccountType, fmt.Errorf("unknown account type %q", tx.AccountType))
+		}
+		tx.AccountType = accountType
+	}
+	if provider == ProviderMPESAB2B && tx.AccountType == "" {
+		fail(cols.AccountType, fmt.Errorf("account type is required for %s", provider))
+	}
+
+	switch {
+	case tx.Account == "":
+		fail(cols.Account, fmt.Errorf("account is required"))
+	case tx.AccountType == AccountTypePhone || (tx.AccountType == "" && (provider == ProviderMPESAB2C || provider == ProviderIntaSendXB)):
+		phone := strings.TrimPrefix(strings.NewReplacer(" ", "", "-", "").Replace(tx.Account), "+")
+		if !isDigits(phone) || len(phone) < 10 || len(phone) > 15 {
+			fail(cols.Account, fmt.Errorf("invalid phone number %q", tx.Account))
+		}
+		tx.Account = phone
+	case tx.AccountType == AccountTypeTillNumber || tx.AccountType == AccountTypePaybill:
+		if !isDigits(tx.Account) {
+			fail(cols.Account, fmt.Errorf("%s must be numeric, got %q", tx.AccountType, tx.Account))
+		}
+	}
+
+	needsBank := provider == ProviderBankTransfer || tx.AccountType == AccountTypeBankAccount
+	switch {
+	case needsBank && tx.BankCode == "":
+		fail(cols.BankCode, fmt.Errorf("bank code is required for bank transfers"))
+	case tx.BankCode != "" && !isDigits(tx.BankCode):
+		fail(cols.BankCode, fmt.Errorf("bank code must be numeric, got %q", tx.BankCode))
+	}
+
+	amount, err := parsePayoutAmount(tx.Amount)
+	if err != nil {
+		fail(cols.Amount, err)
+	} else {
+		tx.Amount = strconv.FormatFloat(amount, 'f', -1, 64)
+	}
+	return amount, errs
+}
+
+// parsePayoutAmount accepts amounts as typed into spreadsheets, e.g. "1,250.50"
+func parsePayoutAmount(s string) (float64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("amount is required")
+	}
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("amount must be greater than zero, got %s", s)
+	}
+	if amount > MaxPayoutAmount {
+		return 0, fmt.Errorf("amount %s exceeds the maximum of %d", s, MaxPayoutAmount)
+	}
+	if cents := amount * 100; math.Abs(cents-math.Round(cents)) > 1e-6 {
+		return 0, fmt.Errorf("amount %s has more than two decimal places", s)
+	}
+	return amount, nil
+}
+
+// parseAccountType matches an account type case-insensitively
+func parseAccountType(s string) (SendMoneyAccountType, bool) {
+	for _, t := range []SendMoneyAccountType{AccountTypeTillNumber, AccountTypePaybill, AccountTypeBankAccount, AccountTypePhone} {
+		if strings.EqualFold(s, string(t)) {
+			return t, true
+		}
+	}
+	return SendMoneyAccountType(s), false
+}
+
+// SplitPayoutBatches divides transactions into requests of at most size
+// transactions (DefaultPayoutBatchSize if size <= 0), each a copy of base.
+// When more than one batch results, each gets base.BatchReference suffixed
+// with its position, e.g. "payroll-1", "payroll-2".
+func SplitPayoutBatches(base *SendMoneyRequest, txs []SendMoneyTransaction, size int) ([]*SendMoneyRequest, error) {
+	if base == nil {
+		return nil, fmt.Errorf("base request is required")
+	}
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("at least one transaction is required")
+	}
+	if size <= 0 {
+		size = DefaultPayoutBatchSize
+	}
+	if len(txs) > size && base.BatchReference == "" {
+		return nil, fmt.Errorf("batch reference is required to split a payout into batches")
+	}
+
+	var batches []*SendMoneyRequest
+	for start := 0; start < len(txs); start += size {
+		end := min(start+size, len(txs))
+		batch := *base
+		batch.Transactions = append([]SendMoneyTransaction(nil), txs[start:end]...)
+		if len(txs) > size {
+			batch.BatchReference = fmt.Sprintf("%s-%d", base.BatchReference, start/size+1)
+		}
+		batches = append(batches, &batch)
+	}
+	return batches, nil
+}
+
+// Batches splits the imported transactions using SplitPayoutBatches
+func (p *PayoutImport) Batches(base *SendMoneyRequest, size int) ([]*SendMoneyRequest, error) {
+	return SplitPayoutBatches(base, p.Transactions, size)
+}
+
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package intasend
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePayoutCSV(t *testing.T) {
+	data := "Full Name,Phone,Amount (KES),Note\n" +
+		"Jane Doe,+254 712 345678,\"1,250.50\",Salary\n" +
+		",,,\n" +
+		"John Doe,254798765432,300,Bonus\n"
+	cols := &PayoutColumns{Name: "full name", Account: "Phone", Amount: "Amount (KES)", Narrative: "note"}
+
+	imp, err := ParsePayoutCSV(strings.NewReader(data), &PayoutImportOptions{Columns: cols, Provider: ProviderMPESAB2C})
+	if err != nil {
+		t.Fatalf("ParsePayoutCSV failed: %v", err)
+	}
+	if len(imp.Transactions) != 2 || imp.Total != 1550.50 {
+		t.Fatalf("Expected 2 transactions totalling 1550.50, got %d totalling %v", len(imp.Transactions), imp.Total)
+	}
+	first := imp.Transactions[0]
+	if first.Name != "Jane Doe" || first.Account != "254712345678" || first.Amount != "1250.5" || first.Narrative != "Salary" {
+		t.Errorf("Unexpected first transaction: %+v", first)
+	}
+	if imp.Rows[1] != 4 {
+		t.Errorf("Expected the blank row to be skipped but counted, got rows %v", imp.Rows)
+	}
+}
+
+func TestParsePayoutCSVRowErrors(t *testing.T) {
+	data := "account,amount,account_type,bank_code\n" +
+		"1234567890,100,BankAccount,\n" +
+		"555123,abc,PayBill,\n" +
+		"987654,10.005,Wallet,\n" +
+		"1234567890,50,bankaccount,01\n" +
+		"555123,NaN,PayBill,\n" +
+		"555123,Inf,PayBill,\n" +
+		"555123,1e308,PayBill,\n"
+
+	_, err := ParsePayoutCSV(strings.NewReader(data), nil)
+	var importErr *PayoutImportError
+	if !errors.As(err, &importErr) {
+		t.Fatalf("Expected a PayoutImportError, got %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, row := range importErr.Rows {
+		got[row.Error()] = true
+	}
+	for _, want := range []string{
+		"row 2: bank_code: bank code is required for bank transfers",
+		`row 3: amount: invalid amount "abc"`,
+		`row 4: account_type: unknown account type "Wallet"`,
+		"row 4: amount: amount 10.005 has more than two decimal places",
+		`row 6: amount: invalid amount "NaN"`,
+		`row 7: amount: invalid amount "Inf"`,
+		"row 8: amount: amount 1e308 exceeds the maximum of 1000000000",
+	} {
+		if !got[want] {
+			t.Errorf("Expected error %q, got %v", want, importErr.Rows)
+		}
+	}
+	if len(importErr.Rows) != 7 {
+		t.Errorf("Expected row 5 to be valid, got %d errors", len(importErr.Rows))
+	}
+}
+
+func TestParsePayoutCSVMissingColumn(t *testing.T) {
+	_, err := ParsePayoutCSV(strings.NewReader("account,value\n254712345678,10\n"), nil)
+	if err == nil || !strings.Contains(err.Error(), `column "amount" not found`) {
+		t.Errorf("Expected a missing column error, got %v", err)
+	}
+}
+
+func TestParsePayoutXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Notes" sheetId="1" r:id="rId1"/><sheet name="Payroll" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>Name</t></si><si><t>Account</t></si><si><t>Amount</t></si><si><r><t>Jane </t></r><r><t>Doe</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>ignore me</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+			<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>2.54712345678E+11</v></c><c r="C2"><v>1500</v></c></row>
+			<row r="4"><c r="B4" t="inlineStr"><is><t>254798765432</t></is></c><c r="C4"><v>99.5</v></c></row>
+		</sheetData></worksheet>`,
+	}
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	imp, err := ParsePayoutXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()), &PayoutImportOptions{Sheet: "Payroll"})
+	if err != nil {
+		t.Fatalf("ParsePayoutXLSX failed: %v", err)
+	}
+	if len(imp.Transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, got %+v", imp.Transactions)
+	}
+	if tx := imp.Transactions[0]; tx.Name != "Jane Doe" || tx.Account != "254712345678" || tx.Amount != "1500" {
+		t.Errorf("Unexpected first transaction: %+v", tx)
+	}
+	if imp.Rows[1] != 4 {
+		t.Errorf("Expected the second transaction to come from row 4, got %v", imp.Rows)
+	}
+}
+
+func TestSplitPayoutBatches(t *testing.T) {
+	txs := make([]SendMoneyTransaction, 5)
+	base := &SendMoneyRequest{Currency: CurrencyKES, Provider: ProviderMPESAB2C, BatchReference: "payroll"}
+
+	batches, err := SplitPayoutBatches(base, txs, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 3 || len(batches[2].Transactions) != 1 {
+		t.Fatalf("Expected batches of 2, 2 and 1, got %d batches", len(batches))
+	}
+	for i, want := range []string{"payroll-1", "payroll-2", "payroll-3"} {
+		if batches[i].BatchReference != want || batches[i].Currency != CurrencyKES {
+			t.Errorf("Batch %d: got reference %q", i, batches[i].BatchReference)
+		}
+	}
+
+	single, err := SplitPayoutBatches(base, txs, 0)
+	if err != nil || len(single) != 1 || single[0].BatchReference != "payroll" {
+		t.Errorf("Expected one batch keeping its reference, got %v, %v", single, err)
+	}
+
+	if _, err := SplitPayoutBatches(&SendMoneyRequest{}, txs, 2); err == nil {
+		t.Error("Expected splitting without a batch reference to fail")
+	}
+}
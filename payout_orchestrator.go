@@ -0,0 +1,493 @@
+package intasend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults used by NewPayoutOrchestrator when the config leaves them unset
+const (
+	DefaultPayoutConcurrency  = 4
+	DefaultPayoutPollInterval = 10 * time.Second
+)
+
+// PayoutChunk is one send-money batch of a larger payout and its progress.
+// A chunk without a TrackingID has not been accepted yet and is submitted
+// again, under the same IdempotencyKey, when the run resumes.
+type PayoutChunk struct {
+	Index          int                          `json:"index"`
+	IdempotencyKey string                       `json:"idempotency_key"`
+	Request        *SendMoneyRequest            `json:"request"`
+	TrackingID     string                       `json:"tracking_id,omitempty"`
+	Status         string                       `json:"status,omitempty"`
+	Transactions   []SendMoneyTransactionStatus `json:"transactions,omitempty"`
+	Rejected       bool                         `json:"rejected,omitempty"` // the API refused the batch; it is not retried
+	Error          string                       `json:"error,omitempty"`    // last submit or poll error
+}
+
+// Final reports whether the chunk needs no more submitting or polling
+func (c *PayoutChunk) Final() bool {
+	return c.Rejected || (c.TrackingID != "" && isFinalPayoutStatus(c.Status))
+}
+
+// PayoutCheckpoint is the persisted state of a payout run
+type PayoutCheckpoint struct {
+	RunID       string        `json:"run_id"`
+	Fingerprint string        `json:"fingerprint"` // hash of the request and transactions the run was planned from
+	Chunks      []PayoutChunk `json:"chunks"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// PayoutCheckpointStore persists payout runs so they can resume after a crash
+type PayoutCheckpointStore interface {
+	// LoadCheckpoint returns the saved run, or nil and no error if there is none
+	LoadCheckpoint(ctx context.Context, runID string) (*PayoutCheckpoint, error)
+	SaveCheckpoint(ctx context.Context, cp *PayoutCheckpoint) error
+}
+
+// FileCheckpointStore keeps each run as <Dir>/<run ID>.json
+type FileCheckpointStore struct {
+	Dir string
+}
+
+// LoadCheckpoint implements PayoutCheckpointStore
+func (s *FileCheckpointStore) LoadCheckpoint(ctx context.Context, runID string) (*PayoutCheckpoint, error) {
+	data, err := os.ReadFile(s.path(runID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp PayoutCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", s.path(runID), err)
+	}
+	return &cp, nil
+}
+
+// SaveCheckpoint implements PayoutCheckpointStore. The file is replaced
+// atomically so a crash mid-write leaves the previous checkpoint intact.
+func (s *FileCheckpointStore) SaveCheckpoint(ctx context.Context, cp *PayoutCheckpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, cp.RunID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(cp.RunID))
+}
+
+func (s *FileCheckpointStore) path(runID string) string {
+	return filepath.Join(s.Dir, runID+".json")
+}
+
+// PayoutOrchestratorConfig configures a PayoutOrchestrator
+type PayoutOrchestratorConfig struct {
+	Client Payouts // required
+
+	ChunkSize    int           // transactions per batch, defaults to DefaultPayoutBatchSize
+	Concurrency  int           // batches submitted at once, defaults to DefaultPayoutConcurrency
+	PollInterval time.Duration // time between status checks, defaults to DefaultPayoutPollInterval
+
+	Checkpoints PayoutCheckpointStore // optional; without it a run cannot resume
+	Preflight   *PayoutPreflight      // optional, refuses to submit chunks the source wallet cannot cover
+	OnChunk     func(PayoutChunk)     // optional, called whenever a chunk is submitted or changes status
+}
+
+// PayoutOrchestrator sends payouts too large for one request. It splits the
+// transactions into chunks, submits them concurrently with an idempotency key
+// each, polls every tracking ID until the batch reaches a final state and
+// reports the outcome per recipient.
+type PayoutOrchestrator struct {
+	cfg PayoutOrchestratorConfig
+}
+
+// NewPayoutOrchestrator creates an orchestrator from the given configuration
+func NewPayoutOrchestrator(cfg PayoutOrchestratorConfig) (*PayoutOrchestrator, error) {
+	if cfg.Client == nil {
+		return nil, fmt.Errorf("payouts client is required")
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = DefaultPayoutBatchSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultPayoutConcurrency
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPayoutPollInterval
+	}
+	return &PayoutOrchestrator{cfg: cfg}, nil
+}
+
+// PayoutRecipient is the outcome for one transaction of a payout
+type PayoutRecipient struct {
+	Chunk             int                  `json:"chunk"`
+	TrackingID        string               `json:"tracking_id,omitempty"`
+	Transaction       SendMoneyTransaction `json:"transaction"`
+	TransactionID     string               `json:"transaction_id,omitempty"`
+	Status            string               `json:"status"`
+	StatusDescription string               `json:"status_description,omitempty"`
+}
+
+// PayoutReport aggregates a payout run by recipient
+type PayoutReport struct {
+	RunID     string            `json:"run_id"`
+	Chunks    []PayoutChunk     `json:"chunks"`
+	Succeeded []PayoutRecipient `json:"succeeded"`
+	Failed    []PayoutRecipient `json:"failed"`
+	Pending   []PayoutRecipient `json:"pending"` // not final when the run stopped
+
+	SucceededAmount float64 `json:"succeeded_amount"`
+	FailedAmount    float64 `json:"failed_amount"`
+}
+
+// Done reports whether every recipient reached a final state
+func (r *PayoutReport) Done() bool {
+	return len(r.Pending) == 0
+}
+
+// Run pays txs in chunks built from base (currency, provider, approval and so
+// on) and waits for every chunk to finish. runID names the run: the batch
+// reference defaults to it, and calling Run again with the same runID and
+// input resumes from the checkpoint, re-polling accepted chunks instead of
+// submitting them twice.
+//
+// When the preflight refuses the payout nothing is submitted and Run returns
+// its *InsufficientFundsError. Once chunks are being sent Run returns a report
+// even when it fails. The error joins every chunk that could not be submitted
+// or tracked, plus context cancellation; running again picks up where it
+// stopped.
+func (o *PayoutOrchestrator) Run(ctx context.Context, runID string, base *SendMoneyRequest, txs []SendMoneyTransaction) (*PayoutReport, error) {
+	if runID == "" {
+		return nil, fmt.Errorf("run ID is required")
+	}
+	if base == nil {
+		return nil, fmt.Errorf("request payload is required")
+	}
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("at least one transaction is required")
+	}
+
+	fingerprint, err := payoutFingerprint(base, txs)
+	if err != nil {
+		return nil, err
+	}
+	var cp *PayoutCheckpoint
+	if o.cfg.Checkpoints != nil {
+		if cp, err = o.cfg.Checkpoints.LoadCheckpoint(ctx, runID); err != nil {
+			return nil, fmt.Errorf("loading checkpoint: %w", err)
+		}
+		if cp != nil && cp.Fingerprint != fingerprint {
+			return nil, fmt.Errorf("checkpoint %s was created for a different payout", runID)
+		}
+	}
+	if cp == nil {
+		if cp, err = o.plan(runID, fingerprint, base, txs); err != nil {
+			return nil, err
+		}
+		if o.cfg.Checkpoints != nil {
+			if err := o.cfg.Checkpoints.SaveCheckpoint(ctx, cp); err != nil {
+				return nil, fmt.Errorf("saving checkpoint: %w", err)
+			}
+		}
+	}
+
+	if o.cfg.Preflight != nil {
+		if err := o.preflight(ctx, cp); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &payoutRun{o: o, cp: cp}
+	sem := make(chan struct{}, o.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i := range cp.Chunks {
+		if cp.Chunks[i].Final() {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := r.process(ctx, i, sem); err != nil {
+				r.fail(fmt.Errorf("chunk %d: %w", i+1, err))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		r.fail(ctx.Err())
+	}
+	return newPayoutReport(cp), errors.Join(r.errs...)
+}
+
+// plan splits the payout into chunks, each with a stable idempotency key
+func (o *PayoutOrchestrator) plan(runID, fingerprint string, base *SendMoneyRequest, txs []SendMoneyTransaction) (*PayoutCheckpoint, error) {
+	req := *base
+	if req.BatchReference == "" {
+		req.BatchReference = runID
+	}
+	batches, err := SplitPayoutBatches(&req, txs, o.cfg.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	cp := &PayoutCheckpoint{RunID: runID, Fingerprint: fingerprint, CreatedAt: now, UpdatedAt: now}
+	for i, batch := range batches {
+		cp.Chunks = append(cp.Chunks, PayoutChunk{
+			Index:          i,
+			IdempotencyKey: fmt.Sprintf("%s-%d-%s", runID, i+1, fingerprint[:16]),
+			Request:        batch,
+		})
+	}
+	return cp, nil
+}
+
+// preflight checks that the wallet covers every chunk still to be submitted
+func (o *PayoutOrchestrator) preflight(ctx context.Context, cp *PayoutCheckpoint) error {
+	var req *SendMoneyRequest
+	for _, chunk := range cp.Chunks {
+		if chunk.TrackingID != "" || chunk.Rejected {
+			continue
+		}
+		if req == nil {
+			copied := *chunk.Request
+			copied.Transactions = nil
+			req = &copied
+		}
+		req.Transactions = append(req.Transactions, chunk.Request.Transactions...)
+	}
+	if req == nil {
+		return nil
+	}
+	_, err := o.cfg.Preflight.Check(ctx, req)
+	return err
+}
+
+// payoutRun holds the shared state of one Run call
+type payoutRun struct {
+	o *PayoutOrchestrator
+
+	mu   sync.Mutex
+	cp   *PayoutCheckpoint
+	errs []error
+}
+
+// process submits chunk i if needed, holding a slot in sem, then polls it
+// until it is final
+func (r *payoutRun) process(ctx context.Context, i int, sem chan struct{}) error {
+	r.mu.Lock()
+	chunk := r.cp.Chunks[i]
+	r.mu.Unlock()
+
+	if chunk.TrackingID == "" {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil // reported once by Run
+		}
+		resp, err := r.o.cfg.Client.InitiateSendMoney(chunk.Request, WithContext(ctx), WithIdempotencyKey(chunk.IdempotencyKey))
+		<-sem
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			rejected := isRejection(err)
+			r.update(ctx, i, func(c *PayoutChunk) {
+				c.Rejected = rejected
+				c.Error = err.Error()
+			})
+			if rejected {
+				return nil // reported per recipient
+			}
+			return err
+		}
+		if resp.TrackingID == "" {
+			return fmt.Errorf("send-money response has no tracking ID")
+		}
+		chunk = r.update(ctx, i, func(c *PayoutChunk) {
+			c.TrackingID = resp.TrackingID
+			c.Status = resp.Status
+			c.Transactions = resp.Transactions
+			c.Error = ""
+		})
+	}
+
+	for !chunk.Final() {
+		timer := time.NewTimer(r.o.cfg.PollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+		resp, err := r.o.cfg.Client.GetSendMoneyStatus(chunk.TrackingID, WithContext(ctx))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			r.update(ctx, i, func(c *PayoutChunk) { c.Error = err.Error() })
+			if isRejection(err) {
+				return fmt.Errorf("tracking %s: %w", chunk.TrackingID, err)
+			}
+			continue // transient, try again next interval
+		}
+		if resp.Status == chunk.Status && chunk.Error == "" {
+			continue
+		}
+		chunk = r.update(ctx, i, func(c *PayoutChunk) {
+			c.Status = resp.Status
+			if len(resp.Transactions) > 0 {
+				c.Transactions = resp.Transactions
+			}
+			c.Error = ""
+		})
+	}
+	return nil
+}
+
+// update applies fn to chunk i, saves the checkpoint and notifies OnChunk.
+// Checkpoint failures are reported by Run; the payout itself carries on.
+func (r *payoutRun) update(ctx context.Context, i int, fn func(*PayoutChunk)) PayoutChunk {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.cp.Chunks[i])
+	r.cp.UpdatedAt = time.Now()
+	chunk := r.cp.Chunks[i]
+	if store := r.o.cfg.Checkpoints; store != nil {
+		if err := store.SaveCheckpoint(context.WithoutCancel(ctx), r.cp); err != nil {
+			r.errs = append(r.errs, fmt.Errorf("saving checkpoint: %w", err))
+		}
+	}
+	if r.o.cfg.OnChunk != nil {
+		r.o.cfg.OnChunk(chunk)
+	}
+	return chunk
+}
+
+func (r *payoutRun) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+// isRejection reports whether the API refused the request outright, so
+// repeating it would not help. Throttling and timeouts are worth retrying.
+func isRejection(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+// isFinalPayoutStatus reports whether a batch or transaction status is terminal
+func isFinalPayoutStatus(status string) bool {
+	switch strings.ToUpper(status) {
+	case StatusComplete, "COMPLETED", "SUCCESSFUL", StatusFailed, StatusCancelled, "REJECTED":
+		return true
+	}
+	return false
+}
+
+func isSuccessfulPayoutStatus(status string) bool {
+	switch strings.ToUpper(status) {
+	case StatusComplete, "COMPLETED", "SUCCESSFUL":
+		return true
+	}
+	return false
+}
+
+// payoutFingerprint hashes the payout input so a checkpoint is never resumed
+// with different transactions
+func payoutFingerprint(base *SendMoneyRequest, txs []SendMoneyTransaction) (string, error) {
+	req := *base
+	req.Transactions = txs
+	data, err := json.Marshal(&req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func newPayoutReport(cp *PayoutCheckpoint) *PayoutReport {
+	report := &PayoutReport{
+		RunID:     cp.RunID,
+		Chunks:    append([]PayoutChunk(nil), cp.Chunks...),
+		Succeeded: []PayoutRecipient{},
+		Failed:    []PayoutRecipient{},
+		Pending:   []PayoutRecipient{},
+	}
+	for _, chunk := range cp.Chunks {
+		// The API lists transactions in the order they were sent
+		matched := len(chunk.Transactions) == len(chunk.Request.Transactions)
+		for j, tx := range chunk.Request.Transactions {
+			rcpt := PayoutRecipient{Chunk: chunk.Index, TrackingID: chunk.TrackingID, Transaction: tx, Status: chunk.Status}
+			if matched {
+				st := chunk.Transactions[j]
+				rcpt.TransactionID = st.TransactionID
+				rcpt.StatusDescription = st.StatusDescription
+				if isFinalPayoutStatus(st.Status) || !chunk.Final() {
+					rcpt.Status = st.Status
+				}
+			}
+			if chunk.Rejected {
+				rcpt.Status = "REJECTED"
+				rcpt.StatusDescription = chunk.Error
+			}
+			amount, _ := strconv.ParseFloat(tx.Amount, 64)
+			switch {
+			case !chunk.Final():
+				if rcpt.Status == "" {
+					rcpt.Status = "NOT_SUBMITTED"
+				}
+				if rcpt.StatusDescription == "" {
+					rcpt.StatusDescription = chunk.Error
+				}
+				report.Pending = append(report.Pending, rcpt)
+			case isSuccessfulPayoutStatus(rcpt.Status):
+				report.Succeeded = append(report.Succeeded, rcpt)
+				report.SucceededAmount += amount
+			default:
+				report.Failed = append(report.Failed, rcpt)
+				report.FailedAmount += amount
+			}
+		}
+	}
+	return report
+}
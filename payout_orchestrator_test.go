@@ -0,0 +1,203 @@
+package intasend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubPayouts is a send-money API whose batches complete on the second status
+// check. Batches whose reference is in fail end FAILED, and submit may return
+// an error for a given reference instead of accepting it.
+type stubPayouts struct {
+	mu       sync.Mutex
+	fail     map[string]bool
+	submit   func(req *SendMoneyRequest) error
+	batches  map[string]*SendMoneyResponse
+	keys     map[string]string // idempotency key -> tracking ID
+	polls    map[string]int
+	submits  int
+	inFlight int
+	maxIn    int
+}
+
+func newStubPayouts() *stubPayouts {
+	return &stubPayouts{
+		fail:    make(map[string]bool),
+		batches: make(map[string]*SendMoneyResponse),
+		keys:    make(map[string]string),
+		polls:   make(map[string]int),
+	}
+}
+
+func (s *stubPayouts) InitiateSendMoney(req *SendMoneyRequest, opts ...CallOption) (*SendMoneyResponse, error) {
+	key := newCallOptions(opts).headers["Idempotency-Key"]
+	s.mu.Lock()
+	s.submits++
+	s.inFlight++
+	if s.inFlight > s.maxIn {
+		s.maxIn = s.inFlight
+	}
+	s.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	if s.submit != nil {
+		if err := s.submit(req); err != nil {
+			return nil, err
+		}
+	}
+	if id, ok := s.keys[key]; ok {
+		return s.batches[id], nil
+	}
+	resp := &SendMoneyResponse{TrackingID: fmt.Sprintf("TRK%d", len(s.batches)+1), BatchReference: req.BatchReference, Status: StatusPending}
+	for _, tx := range req.Transactions {
+		resp.Transactions = append(resp.Transactions, SendMoneyTransactionStatus{TransactionID: "T-" + tx.Account, Status: StatusPending, Account: tx.Account})
+	}
+	s.batches[resp.TrackingID] = resp
+	s.keys[key] = resp.TrackingID
+	return resp, nil
+}
+
+func (s *stubPayouts) GetSendMoneyStatus(trackingID string, opts ...CallOption) (*SendMoneyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch, ok := s.batches[trackingID]
+	if !ok {
+		return nil, &APIError{StatusCode: 404, Message: "payout not found"}
+	}
+	s.polls[trackingID]++
+	if s.polls[trackingID] >= 2 {
+		batch.Status = StatusComplete
+		if s.fail[batch.BatchReference] {
+			batch.Status = StatusFailed
+		}
+		for i := range batch.Transactions {
+			batch.Transactions[i].Status = batch.Status
+		}
+	}
+	copied := *batch
+	copied.Transactions = append([]SendMoneyTransactionStatus(nil), batch.Transactions...)
+	return &copied, nil
+}
+
+func payoutTransactions(n int) []SendMoneyTransaction {
+	txs := make([]SendMoneyTransaction, n)
+	for i := range txs {
+		txs[i] = SendMoneyTransaction{Name: fmt.Sprintf("Recipient %d", i+1), Account: fmt.Sprintf("2547000000%02d", i+1), Amount: "100"}
+	}
+	return txs
+}
+
+func TestPayoutOrchestratorRun(t *testing.T) {
+	stub := newStubPayouts()
+	stub.fail["payroll-2"] = true
+	stub.submit = func(req *SendMoneyRequest) error {
+		if req.BatchReference == "payroll-4" {
+			return &APIError{StatusCode: 400, Message: "insufficient balance"}
+		}
+		return nil
+	}
+	orch, err := NewPayoutOrchestrator(PayoutOrchestratorConfig{Client: stub, ChunkSize: 3, Concurrency: 2, PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := &SendMoneyRequest{Currency: CurrencyKES, Provider: ProviderMPESAB2C}
+	report, err := orch.Run(context.Background(), "payroll", base, payoutTransactions(11))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Chunks) != 4 || !report.Done() {
+		t.Fatalf("Expected 4 finished chunks, got %+v", report.Chunks)
+	}
+	// Chunks 1 and 3 complete; 2 fails and 4 is rejected on submit
+	if len(report.Succeeded) != 6 || report.SucceededAmount != 600 || len(report.Failed) != 5 || report.FailedAmount != 500 {
+		t.Errorf("Expected 6 succeeded and 5 failed, got %d and %d", len(report.Succeeded), len(report.Failed))
+	}
+	if got := report.Succeeded[0]; got.TrackingID == "" || got.TransactionID != "T-254700000001" || got.Status != StatusComplete {
+		t.Errorf("Unexpected first recipient: %+v", got)
+	}
+	last := report.Failed[len(report.Failed)-1]
+	if last.Status != "REJECTED" || !strings.Contains(last.StatusDescription, "insufficient balance") {
+		t.Errorf("Expected the rejected chunk to carry its error, got %+v", last)
+	}
+	if stub.maxIn > 2 {
+		t.Errorf("Expected at most 2 concurrent submissions, got %d", stub.maxIn)
+	}
+}
+
+func TestPayoutOrchestratorResume(t *testing.T) {
+	store := &FileCheckpointStore{Dir: t.TempDir()}
+	stub := newStubPayouts()
+	down := true
+	stub.submit = func(req *SendMoneyRequest) error {
+		if down && req.BatchReference == "payroll-2" {
+			return &APIError{StatusCode: 503, Message: "service unavailable"}
+		}
+		return nil
+	}
+	cfg := PayoutOrchestratorConfig{Client: stub, ChunkSize: 2, Concurrency: 1, PollInterval: time.Millisecond, Checkpoints: store}
+	orch, err := NewPayoutOrchestrator(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := &SendMoneyRequest{Currency: CurrencyKES, Provider: ProviderMPESAB2C}
+	txs := payoutTransactions(4)
+	report, err := orch.Run(context.Background(), "payroll", base, txs)
+	if err == nil || !strings.Contains(err.Error(), "chunk 2") {
+		t.Fatalf("Expected chunk 2 to fail, got %v", err)
+	}
+	if report.Done() || len(report.Succeeded) != 2 || len(report.Pending) != 2 {
+		t.Fatalf("Expected chunk 2 pending, got %+v", report)
+	}
+
+	cp, err := store.LoadCheckpoint(context.Background(), "payroll")
+	if err != nil || cp == nil || cp.Chunks[1].TrackingID != "" || cp.Chunks[1].Error == "" {
+		t.Fatalf("Expected a checkpoint with chunk 2 unsubmitted, got %+v, %v", cp, err)
+	}
+
+	// A fresh orchestrator picks up from the checkpoint and only sends chunk 2
+	down = false
+	submitted := stub.submits
+	orch, _ = NewPayoutOrchestrator(cfg)
+	report, err = orch.Run(context.Background(), "payroll", base, txs)
+	if err != nil {
+		t.Fatalf("Resumed run failed: %v", err)
+	}
+	if stub.submits != submitted+1 || len(report.Succeeded) != 4 {
+		t.Errorf("Expected one more submission and 4 recipients paid, got %d submissions and %+v", stub.submits-submitted, report)
+	}
+
+	if _, err := orch.Run(context.Background(), "payroll", base, txs[:3]); err == nil || !strings.Contains(err.Error(), "different payout") {
+		t.Errorf("Expected resuming with other transactions to fail, got %v", err)
+	}
+}
+
+func TestPayoutOrchestratorCancel(t *testing.T) {
+	stub := newStubPayouts()
+	ctx, cancel := context.WithCancel(context.Background())
+	orch, _ := NewPayoutOrchestrator(PayoutOrchestratorConfig{
+		Client:       stub,
+		PollInterval: time.Hour,
+		OnChunk: func(c PayoutChunk) {
+			if c.TrackingID != "" {
+				cancel()
+			}
+		},
+	})
+
+	report, err := orch.Run(ctx, "payroll", &SendMoneyRequest{Currency: CurrencyKES, Provider: ProviderMPESAB2C}, payoutTransactions(2))
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatalf("Expected the run to stop on cancellation, got %v", err)
+	}
+	if len(report.Pending) != 2 || report.Pending[0].TrackingID != "TRK1" || report.Pending[0].Status != StatusPending {
+		t.Errorf("Expected both recipients pending on TRK1, got %+v", report.Pending)
+	}
+}
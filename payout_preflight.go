@@ -0,0 +1,216 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInsufficientFunds is matched by InsufficientFundsError
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// PayoutTariffBand charges Fixed plus Percent of the amount for transactions
+// from Min up to and including Max. A zero Max has no upper limit and a zero
+// Cap leaves the charge uncapped.
+type PayoutTariffBand struct {
+	Min     float64 `json:"min" yaml:"min"`
+	Max     float64 `json:"max,omitempty" yaml:"max,omitempty"`
+	Fixed   float64 `json:"fixed,omitempty" yaml:"fixed,omitempty"`
+	Percent float64 `json:"percent,omitempty" yaml:"percent,omitempty"` // e.g. 1.5 for 1.5%
+	Cap     float64 `json:"cap,omitempty" yaml:"cap,omitempty"`
+}
+
+// PayoutTariff lists the charge bands for one provider and currency
+type PayoutTariff struct {
+	Provider SendMoneyProvider  `json:"provider" yaml:"provider"`
+	Currency CurrencyType       `json:"currency" yaml:"currency"`
+	Bands    []PayoutTariffBand `json:"bands" yaml:"bands"`
+}
+
+// PayoutTariffs is a tariff table. IntaSend pricing depends on the account
+// and changes over time, so the SDK ships no defaults; copy the rates from
+// your dashboard or agreement.
+type PayoutTariffs []PayoutTariff
+
+// LoadPayoutTariffs reads a tariff table from a YAML or JSON file holding a
+// list of tariffs
+func LoadPayoutTariffs(path string) (PayoutTariffs, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tariffs PayoutTariffs
+	if err := yaml.Unmarshal(data, &tariffs); err != nil {
+		return nil, fmt.Errorf("invalid tariff file %s: %w", path, err)
+	}
+	return tariffs, nil
+}
+
+// Charge returns the expected charge for sending amount through provider
+func (t PayoutTariffs) Charge(provider SendMoneyProvider, currency CurrencyType, amount float64) (float64, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount %v", amount)
+	}
+	for _, tariff := range t {
+		if !strings.EqualFold(string(tariff.Provider), string(provider)) || !strings.EqualFold(string(tariff.Currency), string(currency)) {
+			continue
+		}
+		for _, band := range tariff.Bands {
+			if amount < band.Min || (band.Max > 0 && amount > band.Max) {
+				continue
+			}
+			charge := band.Fixed + amount*band.Percent/100
+			if band.Cap > 0 && charge > band.Cap {
+				charge = band.Cap
+			}
+			return math.Round(charge*100) / 100, nil
+		}
+		return 0, fmt.Errorf("no %s %s tariff band covers %.2f", provider, currency, amount)
+	}
+	return 0, fmt.Errorf("no tariff for %s in %s", provider, currency)
+}
+
+// PayoutChargeLine is the estimate for one transaction
+type PayoutChargeLine struct {
+	Index   int     `json:"index"`
+	Account string  `json:"account"`
+	Amount  float64 `json:"amount"`
+	Charge  float64 `json:"charge"`
+}
+
+// PayoutEstimate is the outcome of a pre-flight check
+type PayoutEstimate struct {
+	WalletID         string             `json:"wallet_id"`
+	Currency         CurrencyType       `json:"currency"`
+	Provider         SendMoneyProvider  `json:"provider"`
+	Lines            []PayoutChargeLine `json:"lines"`
+	Amount           float64            `json:"amount"`  // sum of the transaction amounts
+	Charges          float64            `json:"charges"` // sum of the expected charges
+	Total            float64            `json:"total"`   // Amount plus Charges
+	AvailableBalance float64            `json:"available_balance"`
+	Shortfall        float64            `json:"shortfall"` // how much more the wallet needs, 0 when funds suffice
+}
+
+// Sufficient reports whether the wallet covers the payout and its charges
+func (e *PayoutEstimate) Sufficient() bool {
+	return e.Shortfall <= 0
+}
+
+// InsufficientFundsError is returned when the source wallet cannot cover a
+// payout. The estimate breaks the shortfall down per transaction.
+type InsufficientFundsError struct {
+	Estimate *PayoutEstimate
+}
+
+func (e *InsufficientFundsError) Error() string {
+	est := e.Estimate
+	return fmt.Sprintf("insufficient funds in wallet %s: payout needs %.2f %s (%.2f in %d transactions + %.2f charges), available %.2f, short by %.2f",
+		est.WalletID, est.Total, est.Currency, est.Amount, len(est.Lines), est.Charges, est.AvailableBalance, est.Shortfall)
+}
+
+// Is reports whether target is ErrInsufficientFunds
+func (e *InsufficientFundsError) Is(target error) bool {
+	return target == ErrInsufficientFunds
+}
+
+// PayoutPreflight checks a send-money request against the source wallet
+// before anything is submitted
+type PayoutPreflight struct {
+	Wallets Wallets       // required, used to look up the source wallet
+	Tariffs PayoutTariffs // required, used to estimate charges
+}
+
+// Check estimates the total cost of req and compares it with the available
+// balance of req.WalletID, or of the currency's disbursing settlement wallet
+// when unset. The estimate is returned with an *InsufficientFundsError when
+// the wallet falls short.
+func (p *PayoutPreflight) Check(ctx context.Context, req *SendMoneyRequest) (*PayoutEstimate, error) {
+	if p.Wallets == nil {
+		return nil, fmt.Errorf("wallets client is required")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request payload is required")
+	}
+	if len(req.Transactions) == 0 {
+		return nil, fmt.Errorf("at least one transaction is required")
+	}
+
+	est := &PayoutEstimate{Currency: req.Currency, Provider: req.Provider}
+	for i, tx := range req.Transactions {
+		amount, err := strconv.ParseFloat(tx.Amount, 64)
+		if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+			return nil, fmt.Errorf("transactions[%d]: invalid amount %q", i, tx.Amount)
+		}
+		charge, err := p.Tariffs.Charge(req.Provider, req.Currency, amount)
+		if err != nil {
+			return nil, fmt.Errorf("transactions[%d]: %w", i, err)
+		}
+		est.Lines = append(est.Lines, PayoutChargeLine{Index: i, Account: tx.Account, Amount: amount, Charge: charge})
+		est.Amount += amount
+		est.Charges += charge
+	}
+	est.Amount = math.Round(est.Amount*100) / 100
+	est.Charges = math.Round(est.Charges*100) / 100
+	est.Total = math.Round((est.Amount+est.Charges)*100) / 100
+	if math.IsNaN(est.Total) || math.IsInf(est.Total, 0) {
+		return nil, fmt.Errorf("payout total is not a finite amount")
+	}
+
+	wallet, err := p.sourceWallet(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	est.WalletID = wallet.WalletID
+	est.AvailableBalance = wallet.AvailableBalance
+	if short := math.Round((est.Total-wallet.AvailableBalance)*100) / 100; short > 0 {
+		est.Shortfall = short
+		return est, &InsufficientFundsError{Estimate: est}
+	}
+	return est, nil
+}
+
+// maxWalletPages bounds the wallet pages sourceWallet reads, in case the
+// API keeps returning a next link
+const maxWalletPages = 50
+
+// sourceWallet finds the wallet the payout would be debited from. It stops
+// early on a page that repeats wallets already seen.
+func (p *PayoutPreflight) sourceWallet(ctx context.Context, req *SendMoneyRequest) (*WalletResp, error) {
+	params := &ListWalletsParams{Currency: string(req.Currency)}
+	seen := make(map[string]bool)
+	for page := 1; ; page++ {
+		if page > maxWalletPages {
+			return nil, fmt.Errorf("listing wallets: gave up after %d pages", maxWalletPages)
+		}
+		params.Page = &page
+		resp, err := p.Wallets.ListWallets(params, WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("listing wallets: %w", err)
+		}
+		repeated := len(resp.Results) > 0
+		for i, w := range resp.Results {
+			if !seen[w.WalletID] {
+				seen[w.WalletID], repeated = true, false
+			}
+			if req.WalletID != "" && w.WalletID == req.WalletID {
+				return &resp.Results[i], nil
+			}
+			if req.WalletID == "" && w.CanDisburse && strings.EqualFold(w.WalletType, string(WalletTypeFilterSettlement)) {
+				return &resp.Results[i], nil
+			}
+		}
+		if repeated || !HasNextPage(resp.Next) || len(resp.Results) == 0 {
+			break
+		}
+	}
+	if req.WalletID != "" {
+		return nil, fmt.Errorf("wallet %s not found in %s", req.WalletID, req.Currency)
+	}
+	return nil, fmt.Errorf("no %s settlement wallet can disburse", req.Currency)
+}
@@ -0,0 +1,190 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubWallets serves wallets one per page
+type stubWallets struct {
+	wallets []WalletResp
+}
+
+func (s *stubWallets) ListWallets(params *ListWalletsParams, opts ...CallOption) (*PaginatedWallets, error) {
+	page := 1
+	if params != nil && params.Page != nil {
+		page = *params.Page
+	}
+	resp := &PaginatedWallets{Count: len(s.wallets)}
+	if page <= len(s.wallets) {
+		resp.Results = s.wallets[page-1 : page]
+	}
+	if page < len(s.wallets) {
+		next := "next"
+		resp.Next = &next
+	}
+	return resp, nil
+}
+
+func (s *stubWallets) ListWalletTransactions(walletID string, params *WalletTransactionsParams, opts ...CallOption) (*TransactionResp, error) {
+	return &TransactionResp{}, nil
+}
+
+var testTariffs = PayoutTariffs{{
+	Provider: ProviderMPESAB2C,
+	Currency: CurrencyKES,
+	Bands: []PayoutTariffBand{
+		{Min: 1, Max: 100, Fixed: 10},
+		{Min: 100.01, Max: 1000, Fixed: 15},
+		{Min: 1000.01, Percent: 1.5, Cap: 50},
+	},
+}}
+
+func TestPayoutTariffsCharge(t *testing.T) {
+	for _, tc := range []struct {
+		amount, want float64
+	}{
+		{100, 10},
+		{100.5, 15},
+		{2000, 30},
+		{10000, 50},
+	} {
+		got, err := testTariffs.Charge(ProviderMPESAB2C, "kes", tc.amount)
+		if err != nil || got != tc.want {
+			t.Errorf("Charge(%v) = %v, %v; want %v", tc.amount, got, err, tc.want)
+		}
+	}
+	if _, err := testTariffs.Charge(ProviderMPESAB2C, CurrencyKES, 0.5); err == nil {
+		t.Error("Expected an amount below every band to fail")
+	}
+	if _, err := testTariffs.Charge(ProviderBankTransfer, CurrencyKES, 500); err == nil || !strings.Contains(err.Error(), "no tariff for BANK") {
+		t.Errorf("Expected a missing tariff error, got %v", err)
+	}
+}
+
+func TestLoadPayoutTariffs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tariffs.yaml")
+	body := "- provider: MPESA-B2C\n  currency: KES\n  bands:\n    - {min: 1, max: 1000, fixed: 15}\n"
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tariffs, err := LoadPayoutTariffs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if charge, err := tariffs.Charge(ProviderMPESAB2C, CurrencyKES, 500); err != nil || charge != 15 {
+		t.Errorf("Expected a 15 charge, got %v, %v", charge, err)
+	}
+}
+
+func TestPayoutPreflight(t *testing.T) {
+	wallets := &stubWallets{wallets: []WalletResp{
+		{WalletID: "W1", Currency: "KES", WalletType: "WORKING", CanDisburse: true, AvailableBalance: 5000},
+		{WalletID: "W2", Currency: "KES", WalletType: "SETTLEMENT", CanDisburse: true, AvailableBalance: 1100},
+	}}
+	p := &PayoutPreflight{Wallets: wallets, Tariffs: testTariffs}
+	req := &SendMoneyRequest{Currency: CurrencyKES, Provider: ProviderMPESAB2C, Transactions: payoutTransactions(10)}
+
+	// 10 x 100 plus 10 x 10 charges against the settlement wallet's 1,100
+	est, err := p.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if est.WalletID != "W2" || est.Amount != 1000 || est.Charges != 100 || est.Total != 1100 || !est.Sufficient() {
+		t.Errorf("Unexpected estimate: %+v", est)
+	}
+
+	req.Transactions = payoutTransactions(11)
+	est, err = p.Check(context.Background(), req)
+	var short *InsufficientFundsError
+	if !errors.As(err, &short) || !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("Expected an InsufficientFundsError, got %v", err)
+	}
+	if est.Shortfall != 110 || len(short.Estimate.Lines) != 11 {
+		t.Errorf("Expected a 110 shortfall over 11 lines, got %+v", est)
+	}
+	want := "insufficient funds in wallet W2: payout needs 1210.00 KES (1100.00 in 11 transactions + 110.00 charges), available 1100.00, short by 110.00"
+	if err.Error() != want {
+		t.Errorf("Unexpected error:\n got %s\nwant %s", err, want)
+	}
+
+	// An explicit wallet is used even if it is not the settlement wallet
+	req.WalletID = "W1"
+	if est, err := p.Check(context.Background(), req); err != nil || est.WalletID != "W1" {
+		t.Errorf("Expected wallet W1 to cover the payout, got %+v, %v", est, err)
+	}
+	// Amounts that are not finite never count as covered
+	for _, amount := range []string{"NaN", "+Inf"} {
+		bad := &SendMoneyRequest{Currency: CurrencyKES, Provider: ProviderMPESAB2C, Transactions: []SendMoneyTransaction{{Account: "254700000000", Amount: amount}}}
+		if est, err := p.Check(context.Background(), bad); err == nil || est != nil {
+			t.Errorf("Expected amount %s to be rejected, got %+v", amount, est)
+		}
+	}
+	if _, err := testTariffs.Charge(ProviderMPESAB2C, CurrencyKES, math.NaN()); err == nil {
+		t.Error("Expected a NaN charge lookup to fail")
+	}
+
+	req.WalletID = "W9"
+	if _, err := p.Check(context.Background(), req); err == nil || !strings.Contains(err.Error(), "wallet W9 not found") {
+		t.Errorf("Expected an unknown wallet error, got %v", err)
+	}
+}
+
+// endlessWallets always links to a next page. Unless fresh is set, it
+// ignores the page number and repeats the same wallet.
+type endlessWallets struct {
+	stubWallets
+	fresh bool
+	calls int
+}
+
+func (s *endlessWallets) ListWallets(params *ListWalletsParams, opts ...CallOption) (*PaginatedWallets, error) {
+	s.calls++
+	id := "W1"
+	if s.fresh {
+		id = fmt.Sprint("W", *params.Page)
+	}
+	next := "next"
+	return &PaginatedWallets{Next: &next, Results: []WalletResp{{WalletID: id, Currency: "KES", WalletType: "WORKING"}}}, nil
+}
+
+func TestPayoutPreflightWalletPaging(t *testing.T) {
+	req := &SendMoneyRequest{Currency: CurrencyKES, Provider: ProviderMPESAB2C, Transactions: payoutTransactions(1)}
+
+	repeating := &endlessWallets{}
+	_, err := (&PayoutPreflight{Wallets: repeating, Tariffs: testTariffs}).Check(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "no KES settlement wallet") || repeating.calls != 2 {
+		t.Errorf("Expected to stop at the repeated page, got %v after %d calls", err, repeating.calls)
+	}
+
+	endless := &endlessWallets{fresh: true}
+	_, err = (&PayoutPreflight{Wallets: endless, Tariffs: testTariffs}).Check(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "gave up after") || endless.calls != maxWalletPages {
+		t.Errorf("Expected to give up after %d pages, got %v after %d calls", maxWalletPages, err, endless.calls)
+	}
+}
+
+func TestPayoutOrchestratorPreflight(t *testing.T) {
+	stub := newStubPayouts()
+	wallets := &stubWallets{wallets: []WalletResp{{WalletID: "W1", Currency: "KES", WalletType: "SETTLEMENT", CanDisburse: true, AvailableBalance: 500}}}
+	orch, _ := NewPayoutOrchestrator(PayoutOrchestratorConfig{
+		Client:    stub,
+		ChunkSize: 2,
+		Preflight: &PayoutPreflight{Wallets: wallets, Tariffs: testTariffs},
+	})
+
+	base := &SendMoneyRequest{Currency: CurrencyKES, Provider: ProviderMPESAB2C}
+	report, err := orch.Run(context.Background(), "payroll", base, payoutTransactions(5))
+	if !errors.Is(err, ErrInsufficientFunds) || report != nil {
+		t.Fatalf("Expected the run to be refused, got %v", err)
+	}
+	if stub.submits != 0 {
+		t.Errorf("Expected nothing submitted, got %d submissions", stub.submits)
+	}
+}
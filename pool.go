@@ -0,0 +1,235 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCredentialRefresh is how long a ClientPool caches a tenant's
+// credentials when ClientPoolConfig leaves RefreshInterval unset
+const DefaultCredentialRefresh = 5 * time.Minute
+
+// DefaultCredentialRetry is how long a ClientPool waits after failing to load
+// a tenant's credentials before asking the provider again
+const DefaultCredentialRetry = 30 * time.Second
+
+// ClientPoolConfig configures a ClientPool
+type ClientPoolConfig struct {
+	Credentials CredentialProvider // required, loads each tenant's keys
+
+	Transport       http.RoundTripper // shared by every tenant's client; defaults to NewTransport()
+	Timeout         time.Duration     // per-request timeout, defaults to 30s
+	RefreshInterval time.Duration     // how long credentials are cached before being reloaded; negative disables refresh
+	RetryInterval   time.Duration     // how long to wait after a failed load before retrying, defaults to DefaultCredentialRetry; negative retries on every Get
+
+	RateLimit *RateLimiterConfig               // optional, each tenant gets its own RateLimiter with this config
+	Metrics   func(tenantID string) Metrics    // optional, returns the metrics sink for a tenant
+	Configure func(tenantID string, c *Client) // optional, called on every client the pool builds
+}
+
+// ClientPool hands out clients keyed by tenant ID. All clients share one HTTP
+// transport, each tenant keeps its own rate limiter and metrics across
+// credential rotations, and credentials are reloaded after RefreshInterval or
+// as soon as IntaSend rejects them with 401.
+type ClientPool struct {
+	cfg        ClientPoolConfig
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu      sync.Mutex
+	tenants map[string]*tenantClient
+}
+
+type tenantClient struct {
+	mu       sync.Mutex
+	client   *Client
+	creds    Credentials
+	loadedAt time.Time
+	stale    bool // credentials were rejected and must be reloaded
+
+	failedAt time.Time // when the provider last failed, cleared by a successful load
+	failure  error
+
+	limiter *RateLimiter
+	metrics Metrics
+}
+
+// NewTransport returns an http.Transport tuned for many clients talking to
+// the same IntaSend hosts
+func NewTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 200
+	t.MaxIdleConnsPerHost = 100
+	t.IdleConnTimeout = 90 * time.Second
+	return t
+}
+
+// NewClientPool creates a pool from the given configuration
+func NewClientPool(cfg ClientPoolConfig) (*ClientPool, error) {
+	if cfg.Credentials == nil {
+		return nil, fmt.Errorf("credential provider is required")
+	}
+	if cfg.Transport == nil {
+		cfg.Transport = NewTransport()
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = DefaultCredentialRefresh
+	}
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = DefaultCredentialRetry
+	}
+	return &ClientPool{
+		cfg:        cfg,
+		httpClient: &http.Client{Transport: cfg.Transport, Timeout: cfg.Timeout},
+		now:        time.Now,
+		tenants:    make(map[string]*tenantClient),
+	}, nil
+}
+
+// Get returns the client for a tenant, loading or refreshing its credentials
+// as needed. If a scheduled refresh fails, the current client is returned so
+// a flaky secret store does not take payments down; rejected credentials are
+// never reused. After the provider fails, it is not asked again until
+// RetryInterval has passed, and Get returns the same outcome meanwhile.
+func (p *ClientPool) Get(ctx context.Context, tenantID string) (*Client, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+	t := p.tenant(tenantID)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil && !t.stale && !p.expired(t) {
+		return t.client, nil
+	}
+	err := t.failure
+	if !p.retrying(t) {
+		err = p.load(ctx, tenantID, t)
+	}
+	if err != nil {
+		if t.client != nil && !t.stale {
+			return t.client, nil
+		}
+		return nil, err
+	}
+	return t.client, nil
+}
+
+// Refresh reloads a tenant's credentials immediately, e.g. from a rotation webhook
+func (p *ClientPool) Refresh(ctx context.Context, tenantID string) error {
+	t := p.tenant(tenantID)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return p.load(ctx, tenantID, t)
+}
+
+// Invalidate forgets a tenant, including its rate limiter state
+func (p *ClientPool) Invalidate(tenantID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tenants, tenantID)
+}
+
+// Tenants returns the IDs of tenants the pool currently holds, sorted
+func (p *ClientPool) Tenants() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := make([]string, 0, len(p.tenants))
+	for id := range p.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CloseIdleConnections closes idle connections on the shared transport
+func (p *ClientPool) CloseIdleConnections() {
+	p.httpClient.CloseIdleConnections()
+}
+
+func (p *ClientPool) tenant(tenantID string) *tenantClient {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t, ok := p.tenants[tenantID]
+	if !ok {
+		t = &tenantClient{}
+		if p.cfg.RateLimit != nil {
+			t.limiter = NewRateLimiter(*p.cfg.RateLimit)
+		}
+		if p.cfg.Metrics != nil {
+			t.metrics = p.cfg.Metrics(tenantID)
+		}
+		p.tenants[tenantID] = t
+	}
+	return t
+}
+
+func (p *ClientPool) expired(t *tenantClient) bool {
+	return p.cfg.RefreshInterval > 0 && p.now().Sub(t.loadedAt) >= p.cfg.RefreshInterval
+}
+
+// retrying reports whether the provider failed less than RetryInterval ago
+func (p *ClientPool) retrying(t *tenantClient) bool {
+	return t.failure != nil && p.now().Sub(t.failedAt) < p.cfg.RetryInterval
+}
+
+// load fetches credentials and rebuilds the client if they changed; t.mu must be held
+func (p *ClientPool) load(ctx context.Context, tenantID string, t *tenantClient) error {
+	creds, err := p.cfg.Credentials.Credentials(ctx, tenantID)
+	if err == nil && (creds == nil || creds.PublishableKey == "" && creds.Token == "") {
+		err = fmt.Errorf("credentials for tenant %q are empty", tenantID)
+	} else if err != nil {
+		err = fmt.Errorf("failed to load credentials for tenant %q: %w", tenantID, err)
+	}
+	if err != nil {
+		t.failedAt, t.failure = p.now(), err
+		return err
+	}
+
+	t.loadedAt, t.failure = p.now(), nil
+	if t.client != nil && *creds == t.creds {
+		if t.stale {
+			return fmt.Errorf("credentials for tenant %q were rejected and have not been rotated", tenantID)
+		}
+		return nil
+	}
+
+	// Build a new client rather than mutating the old one, so requests
+	// already in flight keep a consistent set of keys
+	client := NewClient(creds.PublishableKey, string(creds.Token), creds.Test, false)
+	client.HTTPClient = p.httpClient
+	client.RateLimiter = t.limiter
+	client.Metrics = t.metrics
+	client.Use(p.rejectedCredentials(t, client))
+	if p.cfg.Configure != nil {
+		p.cfg.Configure(tenantID, client)
+	}
+
+	t.client, t.creds, t.stale = client, *creds, false
+	return nil
+}
+
+// rejectedCredentials marks a tenant stale when IntaSend answers 401, so the
+// next Get reloads its credentials
+func (p *ClientPool) rejectedCredentials(t *tenantClient, client *Client) Interceptor {
+	return func(ctx context.Context, inv *Invocation, next Handler) (interface{}, error) {
+		out, err := next(ctx, inv)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+			t.mu.Lock()
+			if t.client == client {
+				t.stale = true
+			}
+			t.mu.Unlock()
+		}
+		return out, err
+	}
+}
@@ -0,0 +1,185 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientPoolRotation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer acme-v2" && r.Header.Get("Authorization") != "Bearer globex" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"detail":"Invalid token"}`))
+			return
+		}
+		w.Write([]byte(`{"results":[]}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	writeTenants := func(acmeToken string, mtime time.Time) {
+		data := `{"acme":{"publishable_key":"pk-acme","token":"` + acmeToken + `","test":true},` +
+			`"globex":{"publishable_key":"pk-globex","token":"globex"}}`
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, mtime, mtime)
+	}
+	writeTenants("acme-v1", time.Now().Add(-time.Hour))
+
+	pool, err := NewClientPool(ClientPoolConfig{
+		Credentials: NewFileCredentialProvider(path),
+		RateLimit:   &RateLimiterConfig{Default: RateLimit{Rate: 100, Burst: 10}},
+		Configure: func(tenantID string, c *Client) {
+			c.BaseURL = srv.URL
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	acme, err := pool.Get(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	globex, err := pool.Get(ctx, "globex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acme.HTTPClient != globex.HTTPClient {
+		t.Error("Expected tenants to share the HTTP client")
+	}
+	if acme.RateLimiter == nil || acme.RateLimiter == globex.RateLimiter {
+		t.Error("Expected each tenant to get its own rate limiter")
+	}
+	if !acme.Test || globex.Test {
+		t.Error("Expected test mode to follow tenant credentials")
+	}
+	if again, _ := pool.Get(ctx, "acme"); again != acme {
+		t.Error("Expected cached client for acme")
+	}
+
+	// The old key is rejected, which marks the tenant stale
+	var apiErr *APIError
+	if _, err := acme.ListWallets(nil); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected 401, got %v", err)
+	}
+	if _, err := pool.Get(ctx, "acme"); err == nil {
+		t.Fatal("Expected an error while rejected credentials are unchanged")
+	}
+
+	writeTenants("acme-v2", time.Now())
+	rotated, err := pool.Get(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated == acme || rotated.Token != "acme-v2" {
+		t.Fatalf("Expected a new client with rotated token, got %q", rotated.Token)
+	}
+	if rotated.RateLimiter != acme.RateLimiter {
+		t.Error("Expected rate limiter to survive rotation")
+	}
+	if _, err := rotated.ListWallets(nil); err != nil {
+		t.Fatalf("Expected rotated credentials to work, got %v", err)
+	}
+
+	if _, err := pool.Get(ctx, "initech"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("Expected ErrUnknownTenant, got %v", err)
+	}
+}
+
+func TestClientPoolRetryInterval(t *testing.T) {
+	calls, fail := 0, false
+	pool, err := NewClientPool(ClientPoolConfig{
+		Credentials: CredentialProviderFunc(func(ctx context.Context, tenantID string) (*Credentials, error) {
+			calls++
+			if fail {
+				return nil, errors.New("secret store unavailable")
+			}
+			return &Credentials{PublishableKey: "pk", Token: "token"}, nil
+		}),
+		RefreshInterval: time.Minute,
+		RetryInterval:   10 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	first, err := pool.Get(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed refresh keeps the current client and is not retried at once
+	fail = true
+	now = now.Add(time.Minute)
+	for range 3 {
+		if c, err := pool.Get(ctx, "acme"); err != nil || c != first {
+			t.Fatalf("Expected the current client while the store fails, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected one refresh attempt, got %d", calls-1)
+	}
+
+	// A tenant that never loaded gets the same error until the retry is due
+	for range 2 {
+		if _, err := pool.Get(ctx, "globex"); err == nil || !strings.Contains(err.Error(), "secret store unavailable") {
+			t.Fatalf("Expected the provider error, got %v", err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("Expected one attempt for the new tenant, got %d", calls-2)
+	}
+
+	fail = false
+	now = now.Add(10 * time.Second)
+	if _, err := pool.Get(ctx, "globex"); err != nil {
+		t.Fatalf("Expected a retry after RetryInterval, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("Expected the retry to reach the provider, got %d calls", calls)
+	}
+}
+
+func TestClientPoolNilCredentials(t *testing.T) {
+	pool, err := NewClientPool(ClientPoolConfig{
+		Credentials: CredentialProviderFunc(func(ctx context.Context, tenantID string) (*Credentials, error) {
+			return nil, nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Get(context.Background(), "acme"); err == nil || err.Error() != `credentials for tenant "acme" are empty` {
+		t.Errorf("Expected an empty credentials error, got %v", err)
+	}
+}
+
+func TestEnvCredentialProvider(t *testing.T) {
+	t.Setenv("PAY_ACME_LTD_PUBLISHABLE_KEY", "pk")
+	t.Setenv("PAY_ACME_LTD_TOKEN", "secret")
+	t.Setenv("PAY_ACME_LTD_TEST", "true")
+
+	creds, err := EnvCredentialProvider{Prefix: "PAY_"}.Credentials(context.Background(), "acme-ltd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *creds != (Credentials{PublishableKey: "pk", Token: "secret", Test: true}) {
+		t.Errorf("Unexpected credentials: %+v", creds)
+	}
+	if _, err := (EnvCredentialProvider{Prefix: "PAY_"}).Credentials(context.Background(), "other"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("Expected ErrUnknownTenant, got %v", err)
+	}
+}
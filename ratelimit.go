@@ -0,0 +1,256 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a token bucket: Rate requests per second with bursts of up to Burst
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiterConfig configures a RateLimiter
+type RateLimiterConfig struct {
+	Default       RateLimit                           // limit for families without an override
+	PerFamily     map[EndpointFamily]RateLimit        // optional per-family overrides
+	MaxConcurrent int                                 // optional cap on in-flight requests (0 = unlimited)
+	OnWait        func(EndpointFamily, time.Duration) // optional hook called whenever a request had to wait
+}
+
+// RateLimiterStats reports limiter activity for one endpoint family
+type RateLimiterStats struct {
+	Requests  int64         // requests admitted
+	Waited    int64         // requests that had to wait for a token or a slot
+	TotalWait time.Duration // cumulative time spent waiting
+	MaxWait   time.Duration // longest single wait
+	Throttled int64         // 429 responses observed
+}
+
+// DefaultRateLimit is used when a RateLimiterConfig leaves Default unset
+var DefaultRateLimit = RateLimit{Rate: 5, Burst: 10}
+
+// ErrRateLimitWait is matched (via errors.Is) by errors returned when a
+// request gave up waiting for the rate limiter, alongside the context error
+var ErrRateLimitWait = errors.New("rate limiter")
+
+// defaultThrottlePause is how long a family pauses after a 429 without Retry-After
+const defaultThrottlePause = time.Second
+
+// RateLimiter throttles requests per endpoint family and caps concurrency.
+// It adapts to X-RateLimit-* and Retry-After response headers.
+type RateLimiter struct {
+	cfg RateLimiterConfig
+	sem chan struct{}
+
+	mu      sync.Mutex
+	buckets map[EndpointFamily]*tokenBucket
+	stats   map[EndpointFamily]*RateLimiterStats
+}
+
+type tokenBucket struct {
+	limit       RateLimit
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewRateLimiter creates a rate limiter from the given configuration
+func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
+	if cfg.Default.Rate <= 0 {
+		cfg.Default = DefaultRateLimit
+	}
+	l := &RateLimiter{
+		cfg:     cfg,
+		buckets: make(map[EndpointFamily]*tokenBucket),
+		stats:   make(map[EndpointFamily]*RateLimiterStats),
+	}
+	if cfg.MaxConcurrent > 0 {
+		l.sem = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	return l
+}
+
+// Wait blocks until a request for the family may proceed. The returned
+// release function must be called once the request has completed.
+func (l *RateLimiter) Wait(ctx context.Context, family EndpointFamily) (func(), error) {
+	start := time.Now()
+
+	for {
+		l.mu.Lock()
+		delay := l.bucket(family).reserve(time.Now())
+		l.mu.Unlock()
+		if delay <= 0 {
+			break
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	release := func() {}
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-l.sem }) }
+	}
+
+	waited := time.Since(start)
+	l.mu.Lock()
+	st := l.stat(family)
+	st.Requests++
+	if waited > time.Millisecond {
+		st.Waited++
+		st.TotalWait += waited
+		if waited > st.MaxWait {
+			st.MaxWait = waited
+		}
+	}
+	l.mu.Unlock()
+
+	if waited > time.Millisecond && l.cfg.OnWait != nil {
+		l.cfg.OnWait(family, waited)
+	}
+	return release, nil
+}
+
+// Observe adjusts the family's bucket from a response's status and rate-limit headers
+func (l *RateLimiter) Observe(family EndpointFamily, statusCode int, headers http.Header) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(family)
+
+	if statusCode == http.StatusTooManyRequests {
+		l.stat(family).Throttled++
+		pause := defaultThrottlePause
+		if d, ok := parseRetryAfter(headers.Get("Retry-After"), now); ok {
+			pause = d
+		}
+		b.pause(now.Add(pause))
+		b.tokens = 0
+		return
+	}
+
+	if d, ok := parseRetryAfter(headers.Get("Retry-After"), now); ok {
+		b.pause(now.Add(d))
+	}
+
+	remaining, err := strconv.ParseFloat(headers.Get("X-RateLimit-Remaining"), 64)
+	if err != nil {
+		return
+	}
+	if remaining < b.tokens {
+		b.tokens = remaining
+	}
+	if remaining <= 0 {
+		if reset, ok := parseRateLimitReset(headers.Get("X-RateLimit-Reset"), now); ok {
+			b.pause(reset)
+		}
+	}
+}
+
+// Stats returns a snapshot of limiter activity per endpoint family
+func (l *RateLimiter) Stats() map[EndpointFamily]RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[EndpointFamily]RateLimiterStats, len(l.stats))
+	for family, st := range l.stats {
+		out[family] = *st
+	}
+	return out
+}
+
+func (l *RateLimiter) bucket(family EndpointFamily) *tokenBucket {
+	b, ok := l.buckets[family]
+	if !ok {
+		limit := l.cfg.Default
+		if override, ok := l.cfg.PerFamily[family]; ok && override.Rate > 0 {
+			limit = override
+		}
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+		b = &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+		l.buckets[family] = b
+	}
+	return b
+}
+
+func (l *RateLimiter) stat(family EndpointFamily) *RateLimiterStats {
+	st, ok := l.stats[family]
+	if !ok {
+		st = &RateLimiterStats{}
+		l.stats[family] = st
+	}
+	return st
+}
+
+// reserve takes a token if one is available and otherwise returns how long to wait
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.limit.Rate
+		if max := float64(b.limit.Burst); b.tokens > max {
+			b.tokens = max
+		}
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+func (b *tokenBucket) pause(until time.Time) {
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// parseRetryAfter handles both the delay-seconds and HTTP-date forms of Retry-After
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now), true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset handles X-RateLimit-Reset given either as a Unix
+// timestamp or as seconds until the window resets
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	secs, err := strconv.ParseFloat(value, 64)
+	if err != nil || secs < 0 {
+		return time.Time{}, false
+	}
+	if secs > 1e9 {
+		return time.Unix(int64(secs), 0), true
+	}
+	return now.Add(time.Duration(secs * float64(time.Second))), true
+}
@@ -0,0 +1,123 @@
+package intasend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEndpointFamilyFor(t *testing.T) {
+	cases := map[string]EndpointFamily{
+		"api/v1/checkout/":       FamilyCheckout,
+		"api/v1/payment/status/": FamilyPaymentStatus,
+		"https://api.intasend.com/api/v1/payment/intasend-xb-push/": FamilyCollection,
+		"https://api.intasend.com/api/v1/send-money/initiate/":      FamilySendMoney,
+		"api/v1/wallets/ABC/transactions/?page=2":                   FamilyWallets,
+		"/api/v1/transactions/":                                     FamilyTransactions,
+		"api/v1/invoices/XYZ/":                                      FamilyInvoices,
+		"api/v1/paymentlinks/LNK1/":                                 FamilyPaymentLinks,
+		"api/v1/subscriptions/SUB1/charges/":                        FamilySubscriptions,
+		"api/v1/unknown/":                                           FamilyOther,
+	}
+	for endpoint, want := range cases {
+		if got := EndpointFamilyFor(endpoint); got != want {
+			t.Errorf("EndpointFamilyFor(%q) = %s, want %s", endpoint, got, want)
+		}
+	}
+}
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Default: RateLimit{Rate: 1000, Burst: 1},
+		PerFamily: map[EndpointFamily]RateLimit{
+			FamilyInvoices: {Rate: 20, Burst: 2},
+		},
+	})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := limiter.Wait(context.Background(), FamilyInvoices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	// Two tokens are available immediately, the next two take 50ms each
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected limiter to delay requests, took %s", elapsed)
+	}
+
+	stats := limiter.Stats()[FamilyInvoices]
+	if stats.Requests != 4 || stats.Waited != 2 {
+		t.Errorf("Expected 4 requests with 2 waits, got %+v", stats)
+	}
+}
+
+func TestRateLimiterRetryAfter(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{Default: RateLimit{Rate: 1000, Burst: 10}})
+
+	headers := http.Header{}
+	headers.Set("Retry-After", "0.1")
+	limiter.Observe(FamilyPaymentStatus, http.StatusTooManyRequests, headers)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Wait(ctx, FamilyPaymentStatus); err == nil {
+		t.Error("Expected Wait to block until Retry-After elapsed")
+	}
+
+	// Other families are unaffected
+	release, err := limiter.Wait(context.Background(), FamilyInvoices)
+	if err != nil {
+		t.Fatalf("Expected other family to proceed, got %v", err)
+	}
+	release()
+
+	if got := limiter.Stats()[FamilyPaymentStatus].Throttled; got != 1 {
+		t.Errorf("Expected 1 throttled response, got %d", got)
+	}
+}
+
+func TestRateLimiterConcurrencyCap(t *testing.T) {
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte(`{"count":0,"results":[]}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient("pk", "token", true, false)
+	client.BaseURL = srv.URL
+	client.RateLimiter = NewRateLimiter(RateLimiterConfig{
+		Default:       RateLimit{Rate: 1000, Burst: 100},
+		MaxConcurrent: 2,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetInvoice("INV1"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 requests in flight, saw %d", peak)
+	}
+}
@@ -0,0 +1,41 @@
+package intasend
+
+import (
+	"encoding/json"
+)
+
+// redacted replaces secret values in formatted and serialised output
+const redacted = "[REDACTED]"
+
+// Secret holds a sensitive value such as an API token. It prints and
+// serialises as [REDACTED] so it cannot leak through %v, %+v, %#v, logs or
+// JSON/YAML dumps; call Reveal to get the actual value.
+type Secret string
+
+// Reveal returns the secret value
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// String implements fmt.Stringer
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString implements fmt.GoStringer
+func (s Secret) GoString() string {
+	return `intasend.Secret("` + s.String() + `")`
+}
+
+// MarshalJSON implements json.Marshaler
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
@@ -51,6 +51,7 @@ type SendMoneyRequest struct {
 	Currency        CurrencyType          `json:"currency"`
 	Provider        SendMoneyProvider     `json:"provider"`
 	DeviceID        string                `json:"device_id,omitempty"`
+	WalletID        string                `json:"wallet_id,omitempty"` // optional, debits this wallet instead of the currency's settlement wallet
 	CallbackURL     string                `json:"callback_url,omitempty"`
 	BatchReference  string                `json:"batch_reference,omitempty"`
 	Transactions    []SendMoneyTransaction `json:"transactions"`
@@ -94,7 +95,19 @@ type SendMoneyResponse struct {
 }
 
 // InitiateSendMoney initiates a send-money (disbursement) batch
-func (c *Client) InitiateSendMoney(req *SendMoneyRequest) (*SendMoneyResponse, error) {
+func (c *Client) InitiateSendMoney(req *SendMoneyRequest, opts ...CallOption) (*SendMoneyResponse, error) {
+	return invoke(c, &Invocation{Operation: OpSendMoneyInitiate, Request: req}, opts,
+		func(inv *Invocation, opts []CallOption) (*SendMoneyResponse, error) {
+			req, err := requestAs[*SendMoneyRequest](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.initiateSendMoney(req, opts)
+		})
+}
+
+// initiateSendMoney validates and submits the batch
+func (c *Client) initiateSendMoney(req *SendMoneyRequest, opts []CallOption) (*SendMoneyResponse, error) {
 	if c.Token == "" {
 		return nil, fmt.Errorf("token is required to initiate send-money")
 	}
@@ -107,13 +120,62 @@ func (c *Client) InitiateSendMoney(req *SendMoneyRequest) (*SendMoneyResponse, e
 	if req.Currency == "" {
 		return nil, fmt.Errorf("currency is required")
 	}
+	if err := req.Currency.Validate(); err != nil {
+		return nil, err
+	}
 	if len(req.Transactions) == 0 {
 		return nil, fmt.Errorf("at least one transaction is required")
 	}
 
 	endpoint := fmt.Sprintf("%s/api/v1/send-money/initiate/", c.APIBaseURL)
 	var resp SendMoneyResponse
-	if err := c.PostJSON(endpoint, req, true, false, &resp); err != nil {
+	if err := c.call(&RequestOptions{
+		Method:   POST,
+		Endpoint: endpoint,
+		Body:     req,
+		UseToken: true,
+	}, &resp, opts); err != nil {
+		if c.Metrics != nil {
+			c.Metrics.PayoutBatch(PayoutBatchSubmitFailed)
+		}
+		return nil, err
+	}
+	if c.Metrics != nil {
+		c.Metrics.PayoutBatch(resp.Status)
+	}
+	return &resp, nil
+}
+
+// SendMoneyStatusRequest identifies the send-money batch to check
+type SendMoneyStatusRequest struct {
+	TrackingID string `json:"tracking_id"`
+}
+
+// GetSendMoneyStatus fetches the current state of a send-money batch and its
+// transactions by tracking ID
+func (c *Client) GetSendMoneyStatus(trackingID string, opts ...CallOption) (*SendMoneyResponse, error) {
+	return invoke(c, &Invocation{Operation: OpSendMoneyStatus, ResourceID: trackingID}, opts,
+		func(inv *Invocation, opts []CallOption) (*SendMoneyResponse, error) {
+			return c.getSendMoneyStatus(inv.ResourceID, opts)
+		})
+}
+
+func (c *Client) getSendMoneyStatus(trackingID string, opts []CallOption) (*SendMoneyResponse, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to check send-money status")
+	}
+	if trackingID == "" {
+		return nil, fmt.Errorf("tracking ID is required")
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/send-money/status/", c.APIBaseURL)
+	var resp SendMoneyResponse
+	if err := c.call(&RequestOptions{
+		Method:   POST,
+		Endpoint: endpoint,
+		Body:     &SendMoneyStatusRequest{TrackingID: trackingID},
+		UseToken: true,
+	}, &resp, opts); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -0,0 +1,270 @@
+package intasend
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettlementDimension is a field a settlement report can be grouped by.
+// Summaries are always split by currency and transaction type.
+type SettlementDimension string
+
+const (
+	SettlementByDay      SettlementDimension = "day"
+	SettlementByProvider SettlementDimension = "provider"
+	SettlementByTariff   SettlementDimension = "tariff"
+)
+
+// SettlementSummary aggregates the completed transactions sharing one key.
+// Dimensions the report is not grouped by are left empty.
+type SettlementSummary struct {
+	Day       string  `json:"day,omitempty"` // YYYY-MM-DD in the report's location
+	Currency  string  `json:"currency"`
+	TransType string  `json:"trans_type"` // DEPOSIT for collections, CHARGE for fee rows
+	Provider  string  `json:"provider,omitempty"`
+	Tariff    string  `json:"tariff,omitempty"` // BUSINESS-PAYS or CUSTOMER-PAYS, inferred from the amounts
+	Count     int     `json:"count"`
+	Gross     float64 `json:"gross"`
+	Charges   float64 `json:"charges"`
+	Net       float64 `json:"net"`
+}
+
+// SettlementReport is the outcome of ReportSettlements
+type SettlementReport struct {
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"`
+	GroupBy    []SettlementDimension `json:"group_by"`
+	Summaries  []SettlementSummary   `json:"summaries"`  // sorted by day, currency, type, provider and tariff
+	Totals     []SettlementSummary   `json:"totals"`     // one per currency, across types
+	Skipped    int                   `json:"skipped"`    // transactions in the period that are not completed collections or charges
+	Duplicates int                   `json:"duplicates"` // CHARGE rows for fees already counted in a deposit's invoice charges
+}
+
+// SettlementReportOptions configures ReportSettlements
+type SettlementReportOptions struct {
+	WalletID string                // optional, limits the report to one wallet
+	GroupBy  []SettlementDimension // defaults to day, provider and tariff
+	Location *time.Location        // calendar days are cut in, defaults to UTC
+	PageSize int                   // transactions fetched per request, defaults to DefaultExportPageSize
+}
+
+// SettlementReportColumns returns the CSV header written by WriteCSV
+func SettlementReportColumns() []string {
+	return []string{"day", "currency", "trans_type", "provider", "tariff", "count", "gross", "charges", "net"}
+}
+
+// Record returns the summary as a CSV row in SettlementReportColumns order
+func (s *SettlementSummary) Record() []string {
+	amount := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	return []string{
+		s.Day, s.Currency, s.TransType, s.Provider, s.Tariff,
+		strconv.Itoa(s.Count), amount(s.Gross), amount(s.Charges), amount(s.Net),
+	}
+}
+
+// WriteCSV writes the summaries with a header row
+func (r *SettlementReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(SettlementReportColumns()); err != nil {
+		return err
+	}
+	for i := range r.Summaries {
+		if err := cw.Write(r.Summaries[i].Record()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReportSettlements totals the collections and fees of the transactions
+// created in [from, to). Each completed deposit contributes its invoice's
+// value, charges and net amount; each completed CHARGE row adds to charges
+// and subtracts from net, unless it belongs to an invoice whose deposit
+// already carried the charges. Other transactions are counted as skipped.
+func ReportSettlements(ctx context.Context, api Transactions, from, to time.Time, opts *SettlementReportOptions) (*SettlementReport, error) {
+	if api == nil {
+		return nil, fmt.Errorf("transactions client is required")
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("period end must be after its start")
+	}
+	var o SettlementReportOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.GroupBy == nil {
+		o.GroupBy = []SettlementDimension{SettlementByDay, SettlementByProvider, SettlementByTariff}
+	}
+	for _, d := range o.GroupBy {
+		switch d {
+		case SettlementByDay, SettlementByProvider, SettlementByTariff:
+		default:
+			return nil, fmt.Errorf("unknown settlement dimension %q", d)
+		}
+	}
+	if o.Location == nil {
+		o.Location = time.UTC
+	}
+	if o.PageSize <= 0 {
+		o.PageSize = DefaultExportPageSize
+	}
+
+	// The API filters by calendar date in its own time zone, so ask for a day
+	// either side and cut the period exactly here
+	params := &ListTransactionsParams{
+		PageSize: &o.PageSize,
+		WalletID: o.WalletID,
+		DateFrom: from.AddDate(0, 0, -1).Format(time.DateOnly),
+		DateTo:   to.AddDate(0, 0, 1).Format(time.DateOnly),
+	}
+	// A collection fee can appear both as the deposit's invoice charges and
+	// as a CHARGE row for the same invoice, so charges are only added once
+	// every deposit has been seen
+	report := &SettlementReport{From: from, To: to, GroupBy: o.GroupBy}
+	var lines []settlementEntry
+	charged := make(map[string]bool)
+	for page := 1; ; page++ {
+		params.Page = &page
+		resp, err := api.ListTransactions(params, WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("listing transactions: %w", err)
+		}
+		for i := range resp.Results {
+			tx := &resp.Results[i]
+			if tx.CreatedAt.Before(from) || !tx.CreatedAt.Before(to) {
+				continue
+			}
+			line, ok := settlementLine(tx)
+			if !ok {
+				report.Skipped++
+				continue
+			}
+			if tx.TransType == TransTypeDeposit && tx.Invoice != nil && line.Charges > 0 {
+				charged[tx.Invoice.InvoiceID] = true
+			}
+			lines = append(lines, settlementEntry{tx, line})
+		}
+		if !HasNextPage(resp.Next) || len(resp.Results) == 0 {
+			break
+		}
+	}
+
+	groups := make(map[SettlementSummary]*SettlementSummary)
+	totals := make(map[string]*SettlementSummary)
+	for _, e := range lines {
+		tx, line := e.tx, e.line
+		if tx.TransType == TransTypeCharge && tx.Invoice != nil && charged[tx.Invoice.InvoiceID] {
+			report.Duplicates++
+			continue
+		}
+		key := SettlementSummary{Currency: line.Currency, TransType: line.TransType}
+		for _, d := range o.GroupBy {
+			switch d {
+			case SettlementByDay:
+				key.Day = tx.CreatedAt.In(o.Location).Format(time.DateOnly)
+			case SettlementByProvider:
+				key.Provider = line.Provider
+			case SettlementByTariff:
+				key.Tariff = line.Tariff
+			}
+		}
+		if groups[key] == nil {
+			g := key
+			groups[key] = &g
+		}
+		if totals[line.Currency] == nil {
+			totals[line.Currency] = &SettlementSummary{Currency: line.Currency}
+		}
+		groups[key].add(line)
+		totals[line.Currency].add(line)
+	}
+
+	for _, g := range groups {
+		report.Summaries = append(report.Summaries, g.rounded())
+	}
+	sort.Slice(report.Summaries, func(i, j int) bool {
+		a, b := &report.Summaries[i], &report.Summaries[j]
+		return slices.Compare(
+			[]string{a.Day, a.Currency, a.TransType, a.Provider, a.Tariff},
+			[]string{b.Day, b.Currency, b.TransType, b.Provider, b.Tariff}) < 0
+	})
+	for _, t := range totals {
+		report.Totals = append(report.Totals, t.rounded())
+	}
+	sort.Slice(report.Totals, func(i, j int) bool { return report.Totals[i].Currency < report.Totals[j].Currency })
+	return report, nil
+}
+
+// settlementEntry is a transaction in the period and its contribution
+type settlementEntry struct {
+	tx   *Result
+	line SettlementSummary
+}
+
+// settlementLine maps one transaction to its contribution, or reports false
+// if it is neither a completed collection nor a completed charge
+func settlementLine(tx *Result) (SettlementSummary, bool) {
+	if !strings.EqualFold(tx.Status, TransStatusCompleted) {
+		return SettlementSummary{}, false
+	}
+	line := SettlementSummary{Currency: tx.Currency, TransType: tx.TransType, Count: 1}
+	switch tx.TransType {
+	case TransTypeDeposit:
+		line.Gross, line.Net = tx.Value, tx.Value
+		if inv := tx.Invoice; inv != nil {
+			line.Provider = inv.Provider
+			line.Gross, line.Charges = inv.Value, inv.Charges
+			if net, err := strconv.ParseFloat(inv.NetAmount, 64); err == nil {
+				line.Net = net
+			}
+			line.Tariff = string(inferTariff(line.Gross, line.Charges, line.Net))
+		}
+	case TransTypeCharge:
+		line.Charges = math.Abs(tx.Value)
+		line.Net = -line.Charges
+	default:
+		return SettlementSummary{}, false
+	}
+	return line, true
+}
+
+// inferTariff works out who bore a collection's fees. The API does not
+// report it, but when the business pays, net is the value less charges, and
+// when the customer pays, the charges come on top and net equals the value.
+// It returns "" when there are no charges or the amounts fit neither.
+func inferTariff(gross, charges, net float64) TarriffType {
+	const cent = 0.005
+	switch {
+	case charges <= 0:
+		return ""
+	case math.Abs(gross-charges-net) < cent:
+		return BUSINESS_PAYS
+	case math.Abs(gross-net) < cent:
+		return CUSTOMER_PAYS
+	}
+	return ""
+}
+
+func (s *SettlementSummary) add(line SettlementSummary) {
+	s.Count += line.Count
+	s.Gross += line.Gross
+	s.Charges += line.Charges
+	s.Net += line.Net
+}
+
+func (s *SettlementSummary) rounded() SettlementSummary {
+	r := *s
+	r.Gross = math.Round(r.Gross*100) / 100
+	r.Charges = math.Round(r.Charges*100) / 100
+	r.Net = math.Round(r.Net*100) / 100
+	return r
+}
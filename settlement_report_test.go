@@ -0,0 +1,117 @@
+package intasend
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func settlementFixture() []Result {
+	day1 := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	deposit := func(at time.Time, provider string, value, charges float64, net string) Result {
+		return Result{
+			TransactionID: "TXN", Currency: "KES", Value: value, TransType: TransTypeDeposit, Status: TransStatusCompleted, CreatedAt: at,
+			Invoice: &InvoiceTx{InvoiceID: "INV", Provider: provider, Value: value, Charges: charges, NetAmount: net},
+		}
+	}
+	return []Result{
+		deposit(day1, "M-PESA", 1000, 35, "965.00"),
+		deposit(day1.Add(time.Hour), "M-PESA", 500, 17.5, "482.50"),
+		deposit(day1, "CARD-PAYMENT", 2000, 60, "2000.00"),
+		deposit(day2, "M-PESA", 100, 3.5, "96.50"),
+		{TransactionID: "FEE", Currency: "KES", Value: -25, TransType: TransTypeCharge, Status: TransStatusCompleted, CreatedAt: day2},
+		{TransactionID: "OUT", Currency: "KES", Value: -300, TransType: TransTypeWithdrawal, Status: TransStatusCompleted, CreatedAt: day2},
+		{TransactionID: "PEND", Currency: "KES", Value: 50, TransType: TransTypeDeposit, Status: TransStatusPending, CreatedAt: day2},
+		deposit(day2.AddDate(0, 0, 5), "M-PESA", 999, 1, "998.00"), // outside the period
+	}
+}
+
+func TestReportSettlements(t *testing.T) {
+	api := &stubTransactions{txs: settlementFixture()}
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3)
+
+	report, err := ReportSettlements(context.Background(), api, from, to, &SettlementReportOptions{WalletID: "WKES001", PageSize: 3})
+	if err != nil {
+		t.Fatalf("ReportSettlements failed: %v", err)
+	}
+	if len(api.params) != 3 || api.params[0].DateFrom != "2024-02-29" || api.params[0].DateTo != "2024-03-05" || api.params[0].WalletID != "WKES001" {
+		t.Errorf("Unexpected list filters: %+v", api.params)
+	}
+
+	want := []SettlementSummary{
+		{Day: "2024-03-01", Currency: "KES", TransType: TransTypeDeposit, Provider: "CARD-PAYMENT", Tariff: "CUSTOMER-PAYS", Count: 1, Gross: 2000, Charges: 60, Net: 2000},
+		{Day: "2024-03-01", Currency: "KES", TransType: TransTypeDeposit, Provider: "M-PESA", Tariff: "BUSINESS-PAYS", Count: 2, Gross: 1500, Charges: 52.5, Net: 1447.5},
+		{Day: "2024-03-02", Currency: "KES", TransType: TransTypeCharge, Count: 1, Charges: 25, Net: -25},
+		{Day: "2024-03-02", Currency: "KES", TransType: TransTypeDeposit, Provider: "M-PESA", Tariff: "BUSINESS-PAYS", Count: 1, Gross: 100, Charges: 3.5, Net: 96.5},
+	}
+	if len(report.Summaries) != len(want) {
+		t.Fatalf("Expected %d summaries, got %+v", len(want), report.Summaries)
+	}
+	for i := range want {
+		if report.Summaries[i] != want[i] {
+			t.Errorf("Summary %d:\n got %+v\nwant %+v", i, report.Summaries[i], want[i])
+		}
+	}
+	total := SettlementSummary{Currency: "KES", Count: 5, Gross: 3600, Charges: 141, Net: 3519}
+	if len(report.Totals) != 1 || report.Totals[0] != total || report.Skipped != 2 {
+		t.Errorf("Unexpected totals %+v, skipped %d", report.Totals, report.Skipped)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || lines[0] != "day,currency,trans_type,provider,tariff,count,gross,charges,net" ||
+		lines[3] != "2024-03-02,KES,CHARGE,,,1,0.00,25.00,-25.00" {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestReportSettlementsGroupBy(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	report, err := ReportSettlements(context.Background(), &stubTransactions{txs: settlementFixture()}, from, from.AddDate(0, 0, 3),
+		&SettlementReportOptions{GroupBy: []SettlementDimension{SettlementByProvider}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Summaries) != 3 || report.Summaries[2].Provider != "M-PESA" || report.Summaries[2].Count != 3 || report.Summaries[2].Day != "" {
+		t.Errorf("Expected deposits grouped by provider only, got %+v", report.Summaries)
+	}
+
+	if _, err := ReportSettlements(context.Background(), &stubTransactions{}, from, from, nil); err == nil {
+		t.Error("Expected an empty period to fail")
+	}
+	if _, err := ReportSettlements(context.Background(), &stubTransactions{}, from, from.AddDate(0, 0, 1), &SettlementReportOptions{GroupBy: []SettlementDimension{"week"}}); err == nil {
+		t.Error("Expected an unknown dimension to fail")
+	}
+}
+
+func TestReportSettlementsChargeRows(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	txs := []Result{
+		// The fee row is listed before its deposit, which already carries it
+		{TransactionID: "FEE1", Currency: "KES", Value: -35, TransType: TransTypeCharge, Status: TransStatusCompleted, CreatedAt: at,
+			Invoice: &InvoiceTx{InvoiceID: "INV1"}},
+		{TransactionID: "TXN1", Currency: "KES", Value: 1000, TransType: TransTypeDeposit, Status: TransStatusCompleted, CreatedAt: at,
+			Invoice: &InvoiceTx{InvoiceID: "INV1", Provider: "M-PESA", Value: 1000, Charges: 35, NetAmount: "965.00"}},
+		// A fee row for an invoice whose deposit reported no charges counts
+		{TransactionID: "TXN2", Currency: "KES", Value: 500, TransType: TransTypeDeposit, Status: TransStatusCompleted, CreatedAt: at,
+			Invoice: &InvoiceTx{InvoiceID: "INV2", Provider: "M-PESA", Value: 500, NetAmount: "500.00"}},
+		{TransactionID: "FEE2", Currency: "KES", Value: -17.5, TransType: TransTypeCharge, Status: TransStatusCompleted, CreatedAt: at,
+			Invoice: &InvoiceTx{InvoiceID: "INV2"}},
+	}
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	report, err := ReportSettlements(context.Background(), &stubTransactions{txs: txs}, from, from.AddDate(0, 0, 1), &SettlementReportOptions{GroupBy: []SettlementDimension{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := SettlementSummary{Currency: "KES", Count: 3, Gross: 1500, Charges: 52.5, Net: 1447.5}
+	if len(report.Totals) != 1 || report.Totals[0] != total || report.Duplicates != 1 {
+		t.Errorf("Expected the deposit's fee counted once, got %+v with %d duplicates", report.Totals, report.Duplicates)
+	}
+}
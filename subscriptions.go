@@ -0,0 +1,480 @@
+package intasend
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// BillingInterval is how often a plan charges its subscribers
+type BillingInterval string
+
+// Billing intervals
+const (
+	IntervalDaily   BillingInterval = "DAILY"
+	IntervalWeekly  BillingInterval = "WEEKLY"
+	IntervalMonthly BillingInterval = "MONTHLY"
+	IntervalYearly  BillingInterval = "YEARLY"
+)
+
+// Subscription status constants
+const (
+	SubscriptionPending   = "PENDING"   // waiting for the customer to authorise the first charge
+	SubscriptionActive    = "ACTIVE"    // charged every billing period
+	SubscriptionPaused    = "PAUSED"    // no charges until resumed
+	SubscriptionPastDue   = "PAST_DUE"  // the latest renewal failed and is being retried
+	SubscriptionCancelled = "CANCELLED" // no further charges
+)
+
+// PlanRequest is the payload for creating a billing plan
+type PlanRequest struct {
+	Name          string          `json:"name"`
+	Amount        float64         `json:"amount"`
+	Currency      CurrencyType    `json:"currency"`
+	Interval      BillingInterval `json:"interval"`
+	IntervalCount int             `json:"interval_count,omitempty"` // periods between charges, defaults to 1
+	TrialDays     int             `json:"trial_days,omitempty"`
+	Description   string          `json:"description,omitempty"`
+}
+
+// Plan is a billing plan customers subscribe to
+type Plan struct {
+	PlanID        string          `json:"plan_id"`
+	Name          string          `json:"name"`
+	Amount        float64         `json:"amount"`
+	Currency      string          `json:"currency"`
+	Interval      BillingInterval `json:"interval"`
+	IntervalCount int             `json:"interval_count"`
+	TrialDays     int             `json:"trial_days"`
+	Description   string          `json:"description"`
+	IsActive      bool            `json:"is_active"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// PaginatedPlans represents the paginated response for listing plans
+type PaginatedPlans struct {
+	Count    int     `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []Plan  `json:"results"`
+}
+
+// ListPlansParams defines optional filters and pagination for listing plans
+type ListPlansParams struct {
+	Page     *int   // optional page number
+	PageSize *int   // optional page size
+	Currency string // optional filter by currency
+}
+
+// SubscriptionRequest is the payload for subscribing a customer to a plan.
+// Identify the customer either by CustomerID or by their contact details.
+type SubscriptionRequest struct {
+	PlanID      string            `json:"plan_id"`
+	CustomerID  string            `json:"customer_id,omitempty"`
+	Email       string            `json:"email,omitempty"`
+	PhoneNumber string            `json:"phone_number,omitempty"`
+	FirstName   string            `json:"first_name,omitempty"`
+	LastName    string            `json:"last_name,omitempty"`
+	Method      PaymentMethodType `json:"method,omitempty"`
+	StartAt     *time.Time        `json:"start_at,omitempty"` // optional, defaults to now
+	APIRef      string            `json:"api_ref,omitempty"`
+	RedirectURL string            `json:"redirect_url,omitempty"`
+}
+
+// Subscription is a customer's subscription to a plan
+type Subscription struct {
+	SubscriptionID     string     `json:"subscription_id"`
+	PlanID             string     `json:"plan_id"`
+	CustomerID         string     `json:"customer_id"`
+	Status             string     `json:"status"`
+	URL                string     `json:"url"` // checkout where the customer authorises the first charge
+	APIRef             string     `json:"api_ref"`
+	CurrentPeriodStart *time.Time `json:"current_period_start"`
+	CurrentPeriodEnd   *time.Time `json:"current_period_end"`
+	NextChargeAt       *time.Time `json:"next_charge_at"`
+	CancelAtPeriodEnd  bool       `json:"cancel_at_period_end"`
+	PausedAt           *time.Time `json:"paused_at"`
+	CancelledAt        *time.Time `json:"cancelled_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// PaginatedSubscriptions represents the paginated response for listing subscriptions
+type PaginatedSubscriptions struct {
+	Count    int            `json:"count"`
+	Next     *string        `json:"next"`
+	Previous *string        `json:"previous"`
+	Results  []Subscription `json:"results"`
+}
+
+// ListSubscriptionsParams defines optional filters and pagination for listing subscriptions
+type ListSubscriptionsParams struct {
+	Page       *int   // optional page number
+	PageSize   *int   // optional page size
+	PlanID     string // optional filter by plan
+	CustomerID string // optional filter by customer
+	Status     string // optional filter by status (ACTIVE, PAUSED, CANCELLED, etc.)
+}
+
+// CancelSubscriptionRequest holds options for cancelling a subscription
+type CancelSubscriptionRequest struct {
+	AtPeriodEnd bool   `json:"at_period_end,omitempty"` // keep the subscription until the paid period ends
+	Reason      string `json:"reason,omitempty"`
+}
+
+// RecurringCharge is one billing-cycle charge of a subscription. InvoiceID
+// refers to the InvoiceItem (and CollectionCallback) for the payment.
+type RecurringCharge struct {
+	ChargeID       string     `json:"charge_id"`
+	SubscriptionID string     `json:"subscription_id"`
+	PlanID         string     `json:"plan_id"`
+	InvoiceID      string     `json:"invoice_id"`
+	Amount         float64    `json:"amount"`
+	Currency       string     `json:"currency"`
+	State          string     `json:"state"` // state of the invoice (PENDING, COMPLETE, FAILED, etc.)
+	Attempt        int        `json:"attempt"`
+	FailedReason   *string    `json:"failed_reason"`
+	PeriodStart    time.Time  `json:"period_start"`
+	PeriodEnd      time.Time  `json:"period_end"`
+	ChargedAt      *time.Time `json:"charged_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// PaginatedCharges represents the paginated response for listing recurring charges
+type PaginatedCharges struct {
+	Count    int               `json:"count"`
+	Next     *string           `json:"next"`
+	Previous *string           `json:"previous"`
+	Results  []RecurringCharge `json:"results"`
+}
+
+// ListChargesParams defines optional filters and pagination for listing recurring charges
+type ListChargesParams struct {
+	Page     *int   // optional page number
+	PageSize *int   // optional page size
+	State    string // optional filter by invoice state
+}
+
+// CreatePlan creates a billing plan
+func (c *Client) CreatePlan(req *PlanRequest, opts ...CallOption) (*Plan, error) {
+	return invoke(c, &Invocation{Operation: OpPlanCreate, Request: req}, opts,
+		func(inv *Invocation, opts []CallOption) (*Plan, error) {
+			req, err := requestAs[*PlanRequest](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.createPlan(req, opts)
+		})
+}
+
+// createPlan validates the plan and posts it
+func (c *Client) createPlan(req *PlanRequest, opts []CallOption) (*Plan, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to create plans")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+	if req.Currency == "" {
+		return nil, fmt.Errorf("currency is required")
+	}
+	if err := req.Currency.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Interval == "" {
+		return nil, fmt.Errorf("interval is required")
+	}
+	if req.IntervalCount < 0 {
+		return nil, fmt.Errorf("interval count cannot be negative")
+	}
+
+	var plan Plan
+	err := c.call(&RequestOptions{
+		Method:   POST,
+		Endpoint: "api/v1/subscriptions/plans/",
+		Body:     req,
+		UseToken: true,
+	}, &plan, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// ListPlans retrieves a paginated list of billing plans
+func (c *Client) ListPlans(params *ListPlansParams, opts ...CallOption) (*PaginatedPlans, error) {
+	return invoke(c, &Invocation{Operation: OpPlansList, Request: params}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaginatedPlans, error) {
+			params, err := requestAs[*ListPlansParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listPlans(params, opts)
+		})
+}
+
+// listPlans builds the query and fetches one page of plans
+func (c *Client) listPlans(params *ListPlansParams, opts []CallOption) (*PaginatedPlans, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to list plans")
+	}
+
+	queryParams := make(map[string]string)
+	if params != nil {
+		setPageParams(queryParams, params.Page, params.PageSize)
+		if params.Currency != "" {
+			queryParams["currency"] = params.Currency
+		}
+	}
+
+	var result PaginatedPlans
+	err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    "api/v1/subscriptions/plans/",
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &result, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPlan retrieves a single billing plan by its ID
+func (c *Client) GetPlan(planID string, opts ...CallOption) (*Plan, error) {
+	return invoke(c, &Invocation{Operation: OpPlanGet, ResourceID: planID}, opts,
+		func(inv *Invocation, opts []CallOption) (*Plan, error) {
+			return c.getPlan(inv.ResourceID, opts)
+		})
+}
+
+// getPlan fetches a single billing plan
+func (c *Client) getPlan(planID string, opts []CallOption) (*Plan, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to get plan")
+	}
+	if planID == "" {
+		return nil, fmt.Errorf("planID is required")
+	}
+
+	endpoint := fmt.Sprintf("api/v1/subscriptions/plans/%s/", url.PathEscape(planID))
+	var plan Plan
+	if err := c.call(&RequestOptions{Method: GET, Endpoint: endpoint, UseToken: true}, &plan, opts); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// CreateSubscription subscribes a customer to a plan. Unless the customer has
+// a saved card, send them to the returned URL to authorise the first charge.
+func (c *Client) CreateSubscription(req *SubscriptionRequest, opts ...CallOption) (*Subscription, error) {
+	return invoke(c, &Invocation{Operation: OpSubscriptionCreate, Request: req}, opts,
+		func(inv *Invocation, opts []CallOption) (*Subscription, error) {
+			req, err := requestAs[*SubscriptionRequest](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.createSubscription(req, opts)
+		})
+}
+
+// createSubscription validates the subscription and posts it
+func (c *Client) createSubscription(req *SubscriptionRequest, opts []CallOption) (*Subscription, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to create subscriptions")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	if req.PlanID == "" {
+		return nil, fmt.Errorf("plan ID is required")
+	}
+	if req.CustomerID == "" && req.Email == "" && req.PhoneNumber == "" {
+		return nil, fmt.Errorf("customer ID, email or phone number is required")
+	}
+
+	var sub Subscription
+	err := c.call(&RequestOptions{
+		Method:   POST,
+		Endpoint: "api/v1/subscriptions/",
+		Body:     req,
+		UseToken: true,
+	}, &sub, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListSubscriptions retrieves a paginated list of subscriptions with optional filters
+func (c *Client) ListSubscriptions(params *ListSubscriptionsParams, opts ...CallOption) (*PaginatedSubscriptions, error) {
+	return invoke(c, &Invocation{Operation: OpSubscriptionsList, Request: params}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaginatedSubscriptions, error) {
+			params, err := requestAs[*ListSubscriptionsParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listSubscriptions(params, opts)
+		})
+}
+
+// listSubscriptions builds the query and fetches one page of subscriptions
+func (c *Client) listSubscriptions(params *ListSubscriptionsParams, opts []CallOption) (*PaginatedSubscriptions, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to list subscriptions")
+	}
+
+	queryParams := make(map[string]string)
+	if params != nil {
+		setPageParams(queryParams, params.Page, params.PageSize)
+		if params.PlanID != "" {
+			queryParams["plan_id"] = params.PlanID
+		}
+		if params.CustomerID != "" {
+			queryParams["customer_id"] = params.CustomerID
+		}
+		if params.Status != "" {
+			queryParams["status"] = params.Status
+		}
+	}
+
+	var result PaginatedSubscriptions
+	err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    "api/v1/subscriptions/",
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &result, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSubscription retrieves a single subscription by its ID
+func (c *Client) GetSubscription(subscriptionID string, opts ...CallOption) (*Subscription, error) {
+	return invoke(c, &Invocation{Operation: OpSubscriptionGet, ResourceID: subscriptionID}, opts,
+		func(inv *Invocation, opts []CallOption) (*Subscription, error) {
+			return c.subscriptionAction(GET, inv.ResourceID, "", nil, opts)
+		})
+}
+
+// CancelSubscription stops a subscription, either now or at the end of the
+// paid period. req may be nil to cancel immediately.
+func (c *Client) CancelSubscription(subscriptionID string, req *CancelSubscriptionRequest, opts ...CallOption) (*Subscription, error) {
+	return invoke(c, &Invocation{Operation: OpSubscriptionCancel, Request: req, ResourceID: subscriptionID}, opts,
+		func(inv *Invocation, opts []CallOption) (*Subscription, error) {
+			req, err := requestAs[*CancelSubscriptionRequest](inv)
+			if err != nil {
+				return nil, err
+			}
+			if req == nil {
+				req = &CancelSubscriptionRequest{}
+			}
+			return c.subscriptionAction(POST, inv.ResourceID, "cancel/", req, opts)
+		})
+}
+
+// PauseSubscription suspends charges until the subscription is resumed
+func (c *Client) PauseSubscription(subscriptionID string, opts ...CallOption) (*Subscription, error) {
+	return invoke(c, &Invocation{Operation: OpSubscriptionPause, ResourceID: subscriptionID}, opts,
+		func(inv *Invocation, opts []CallOption) (*Subscription, error) {
+			return c.subscriptionAction(POST, inv.ResourceID, "pause/", struct{}{}, opts)
+		})
+}
+
+// ResumeSubscription restarts charges on a paused subscription
+func (c *Client) ResumeSubscription(subscriptionID string, opts ...CallOption) (*Subscription, error) {
+	return invoke(c, &Invocation{Operation: OpSubscriptionResume, ResourceID: subscriptionID}, opts,
+		func(inv *Invocation, opts []CallOption) (*Subscription, error) {
+			return c.subscriptionAction(POST, inv.ResourceID, "resume/", struct{}{}, opts)
+		})
+}
+
+// subscriptionAction fetches a subscription or applies a state change to it
+func (c *Client) subscriptionAction(method HTTPMethod, subscriptionID, action string, body interface{}, opts []CallOption) (*Subscription, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to manage subscriptions")
+	}
+	if subscriptionID == "" {
+		return nil, fmt.Errorf("subscriptionID is required")
+	}
+
+	endpoint := fmt.Sprintf("api/v1/subscriptions/%s/%s", url.PathEscape(subscriptionID), action)
+	var sub Subscription
+	if err := c.call(&RequestOptions{Method: method, Endpoint: endpoint, Body: body, UseToken: true}, &sub, opts); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListSubscriptionCharges retrieves the recurring charges of a subscription, newest first
+func (c *Client) ListSubscriptionCharges(subscriptionID string, params *ListChargesParams, opts ...CallOption) (*PaginatedCharges, error) {
+	return invoke(c, &Invocation{Operation: OpSubscriptionCharges, Request: params, ResourceID: subscriptionID}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaginatedCharges, error) {
+			params, err := requestAs[*ListChargesParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listSubscriptionCharges(inv.ResourceID, params, opts)
+		})
+}
+
+// listSubscriptionCharges builds the query and fetches one page of charges
+func (c *Client) listSubscriptionCharges(subscriptionID string, params *ListChargesParams, opts []CallOption) (*PaginatedCharges, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("token is required to list subscription charges")
+	}
+	if subscriptionID == "" {
+		return nil, fmt.Errorf("subscriptionID is required")
+	}
+
+	queryParams := make(map[string]string)
+	if params != nil {
+		setPageParams(queryParams, params.Page, params.PageSize)
+		if params.State != "" {
+			queryParams["state"] = params.State
+		}
+	}
+
+	var result PaginatedCharges
+	err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    fmt.Sprintf("api/v1/subscriptions/%s/charges/", url.PathEscape(subscriptionID)),
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &result, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Helper methods for Subscription and RecurringCharge
+
+// IsActive reports whether the subscription is being charged (including past-due retries)
+func (s *Subscription) IsActive() bool {
+	return s.Status == SubscriptionActive || s.Status == SubscriptionPastDue
+}
+
+// IsCancelled reports whether the subscription has been cancelled
+func (s *Subscription) IsCancelled() bool {
+	return s.Status == SubscriptionCancelled
+}
+
+// IsPaid reports whether the charge's invoice completed
+func (r *RecurringCharge) IsPaid() bool {
+	return r.State == StatusCompleted || r.State == StatusComplete
+}
+
+// IsSubscriptionPayment reports whether the callback is for a recurring subscription charge
+func (cb *CollectionCallback) IsSubscriptionPayment() bool {
+	return cb.SubscriptionID != ""
+}
@@ -0,0 +1,117 @@
+package intasend
+
+import "testing"
+
+func TestSubscriptionRequests(t *testing.T) {
+	page, size := 3, 20
+	runRequestCases(t, `{}`, []requestCase{
+		{
+			name: "create plan",
+			call: func(c *Client) error {
+				_, err := c.CreatePlan(&PlanRequest{Name: "Gold", Amount: 1000, Currency: CurrencyKES, Interval: IntervalMonthly})
+				return err
+			},
+			method: "POST",
+			path:   "/api/v1/subscriptions/plans/",
+			body:   `{"name":"Gold","amount":1000,"currency":"KES","interval":"MONTHLY"}`,
+		},
+		{
+			name: "list plans",
+			call: func(c *Client) error {
+				_, err := c.ListPlans(&ListPlansParams{Page: &page, Currency: "KES"})
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/subscriptions/plans/",
+			query:  "currency=KES&page=3",
+		},
+		{
+			name: "get plan",
+			call: func(c *Client) error {
+				_, err := c.GetPlan("PLN1")
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/subscriptions/plans/PLN1/",
+		},
+		{
+			name: "subscribe",
+			call: func(c *Client) error {
+				_, err := c.CreateSubscription(&SubscriptionRequest{PlanID: "PLN1", CustomerID: "CUS1", APIRef: "gold-1"})
+				return err
+			},
+			method: "POST",
+			path:   "/api/v1/subscriptions/",
+			body:   `{"plan_id":"PLN1","customer_id":"CUS1","api_ref":"gold-1"}`,
+		},
+		{
+			name: "list subscriptions",
+			call: func(c *Client) error {
+				_, err := c.ListSubscriptions(&ListSubscriptionsParams{PageSize: &size, PlanID: "PLN1", CustomerID: "CUS1", Status: SubscriptionActive})
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/subscriptions/",
+			query:  "customer_id=CUS1&page_size=20&plan_id=PLN1&status=ACTIVE",
+		},
+		{
+			name: "get subscription",
+			call: func(c *Client) error {
+				_, err := c.GetSubscription("SUB1")
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/subscriptions/SUB1/",
+		},
+		{
+			name: "cancel now",
+			call: func(c *Client) error {
+				_, err := c.CancelSubscription("SUB1", nil)
+				return err
+			},
+			method: "POST",
+			path:   "/api/v1/subscriptions/SUB1/cancel/",
+			body:   `{}`,
+		},
+		{
+			name: "cancel at period end",
+			call: func(c *Client) error {
+				_, err := c.CancelSubscription("SUB1", &CancelSubscriptionRequest{AtPeriodEnd: true, Reason: "downgrade"})
+				return err
+			},
+			method: "POST",
+			path:   "/api/v1/subscriptions/SUB1/cancel/",
+			body:   `{"at_period_end":true,"reason":"downgrade"}`,
+		},
+		{
+			name: "pause",
+			call: func(c *Client) error {
+				_, err := c.PauseSubscription("SUB1")
+				return err
+			},
+			method: "POST",
+			path:   "/api/v1/subscriptions/SUB1/pause/",
+			body:   `{}`,
+		},
+		{
+			name: "resume",
+			call: func(c *Client) error {
+				_, err := c.ResumeSubscription("SUB1")
+				return err
+			},
+			method: "POST",
+			path:   "/api/v1/subscriptions/SUB1/resume/",
+			body:   `{}`,
+		},
+		{
+			name: "charges",
+			call: func(c *Client) error {
+				_, err := c.ListSubscriptionCharges("SUB1", &ListChargesParams{Page: &page, PageSize: &size, State: StatusFailed})
+				return err
+			},
+			method: "GET",
+			path:   "/api/v1/subscriptions/SUB1/charges/",
+			query:  "page=3&page_size=20&state=FAILED",
+		},
+	})
+}
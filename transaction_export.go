@@ -0,0 +1,393 @@
+package intasend
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ExportFormat selects the file format of a transaction export
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"
+	ExportNDJSON  ExportFormat = "ndjson" // one JSON object per line
+	ExportParquet ExportFormat = "parquet"
+)
+
+// Defaults used by ExportTransactions when the options leave them unset
+const (
+	DefaultExportFileRows = 100000
+	DefaultExportPageSize = 100
+)
+
+// TransactionRecord is a transaction flattened to the export schema. Invoice
+// fields are empty, and Charges zero, for transactions without an invoice.
+type TransactionRecord struct {
+	TransactionID  string    `json:"transaction_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	TransType      string    `json:"trans_type"`
+	Status         string    `json:"status"`
+	Currency       string    `json:"currency"`
+	Value          float64   `json:"value"`
+	RunningBalance float64   `json:"running_balance"`
+	Narrative      string    `json:"narrative"`
+	InvoiceID      string    `json:"invoice_id"`
+	InvoiceState   string    `json:"invoice_state"`
+	Provider       string    `json:"provider"`
+	Charges        float64   `json:"charges"`
+	NetAmount      string    `json:"net_amount"`
+	Account        string    `json:"account"`
+	APIRef         string    `json:"api_ref"`
+	MpesaReference string    `json:"mpesa_reference"`
+	ClearingStatus string    `json:"clearing_status"`
+}
+
+// transactionColumns is the export schema, in the order of TransactionRecord
+var transactionColumns = []parquetColumn{
+	{"transaction_id", parquetString},
+	{"created_at", parquetTimestamp},
+	{"updated_at", parquetTimestamp},
+	{"trans_type", parquetString},
+	{"status", parquetString},
+	{"currency", parquetString},
+	{"value", parquetDouble},
+	{"running_balance", parquetDouble},
+	{"narrative", parquetString},
+	{"invoice_id", parquetString},
+	{"invoice_state", parquetString},
+	{"provider", parquetString},
+	{"charges", parquetDouble},
+	{"net_amount", parquetString},
+	{"account", parquetString},
+	{"api_ref", parquetString},
+	{"mpesa_reference", parquetString},
+	{"clearing_status", parquetString},
+}
+
+// TransactionExportColumns returns the export column names in order. The
+// schema is stable: columns are only ever appended.
+func TransactionExportColumns() []string {
+	names := make([]string, len(transactionColumns))
+	for i, col := range transactionColumns {
+		names[i] = col.name
+	}
+	return names
+}
+
+// FlattenTransaction converts a transaction to its export record
+func FlattenTransaction(tx *Result) TransactionRecord {
+	rec := TransactionRecord{
+		TransactionID:  tx.TransactionID,
+		CreatedAt:      tx.CreatedAt,
+		UpdatedAt:      tx.UpdatedAt,
+		TransType:      tx.TransType,
+		Status:         tx.Status,
+		Currency:       tx.Currency,
+		Value:          tx.Value,
+		RunningBalance: tx.RunningBalance,
+		Narrative:      tx.Narrative,
+	}
+	if inv := tx.Invoice; inv != nil {
+		rec.InvoiceID = inv.InvoiceID
+		rec.InvoiceState = inv.State
+		rec.Provider = inv.Provider
+		rec.Charges = inv.Charges
+		rec.NetAmount = inv.NetAmount
+		rec.Account = inv.Account
+		rec.APIRef = inv.APIRef
+		rec.ClearingStatus = inv.ClearingStatus
+		if inv.MpesaReference != nil {
+			rec.MpesaReference = fmt.Sprint(inv.MpesaReference)
+		}
+	}
+	return rec
+}
+
+// values returns the record's fields in column order
+func (r *TransactionRecord) values() []any {
+	return []any{
+		r.TransactionID, r.CreatedAt, r.UpdatedAt, r.TransType, r.Status, r.Currency,
+		r.Value, r.RunningBalance, r.Narrative, r.InvoiceID, r.InvoiceState, r.Provider,
+		r.Charges, r.NetAmount, r.Account, r.APIRef, r.MpesaReference, r.ClearingStatus,
+	}
+}
+
+// TransactionWriter writes transaction records in one export format
+type TransactionWriter interface {
+	Write(rec *TransactionRecord) error
+	// Close flushes buffered rows and writes any trailer. It does not close
+	// the underlying io.Writer.
+	Close() error
+}
+
+// parquetGroupRows is how many rows a Parquet writer buffers per row group
+const parquetGroupRows = 10000
+
+// NewTransactionWriter returns a writer for format. CSV output starts with a
+// header row; Parquet rows are buffered and written in row groups of 10,000.
+func NewTransactionWriter(w io.Writer, format ExportFormat) (TransactionWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvTransactionWriter{w: csv.NewWriter(w)}, nil
+	case ExportNDJSON:
+		return &ndjsonTransactionWriter{enc: json.NewEncoder(w)}, nil
+	case ExportParquet:
+		return &parquetTransactionWriter{p: newParquetWriter(w, transactionColumns), groupRows: parquetGroupRows}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type csvTransactionWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvTransactionWriter) Write(rec *TransactionRecord) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	values := rec.values()
+	row := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case string:
+			row[i] = v
+		case float64:
+			row[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case time.Time:
+			if !v.IsZero() {
+				row[i] = v.Format(time.RFC3339)
+			}
+		}
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvTransactionWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvTransactionWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write(TransactionExportColumns())
+}
+
+type ndjsonTransactionWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonTransactionWriter) Write(rec *TransactionRecord) error {
+	return n.enc.Encode(rec)
+}
+
+func (n *ndjsonTransactionWriter) Close() error {
+	return nil
+}
+
+type parquetTransactionWriter struct {
+	p         *parquetWriter
+	groupRows int64
+}
+
+func (w *parquetTransactionWriter) Write(rec *TransactionRecord) error {
+	if err := w.p.writeRow(rec.values()...); err != nil {
+		return err
+	}
+	if w.p.rows >= w.groupRows {
+		return w.p.flush()
+	}
+	return nil
+}
+
+func (w *parquetTransactionWriter) Close() error {
+	return w.p.close()
+}
+
+// TransactionExportOptions configures ExportTransactions
+type TransactionExportOptions struct {
+	Format   ExportFormat // required
+	Dir      string       // output directory, created if missing; defaults to the working directory
+	Prefix   string       // file name prefix, defaults to "transactions"
+	FileRows int          // maximum rows per file, defaults to DefaultExportFileRows
+	PageSize int          // transactions fetched per request, defaults to DefaultExportPageSize
+}
+
+// TransactionExportResult lists the files an export wrote
+type TransactionExportResult struct {
+	Files []string `json:"files"`
+	Rows  int      `json:"rows"`
+}
+
+// maxExportPages bounds the pages ExportTransactions reads, in case the API
+// keeps returning a next link
+const maxExportPages = 100_000
+
+// ExportTransactions pages through every transaction matching params (for
+// example WalletID, or DateFrom and DateTo) and writes them to numbered files
+// such as transactions-0001.csv. Rows are streamed to the current file, and a
+// new file is started every FileRows rows, so memory use does not grow with
+// the size of the export. At least one file is written, even when nothing
+// matches. If the export fails, the files it wrote are removed. params.Page
+// is ignored and params.PageSize overrides opts.PageSize.
+func ExportTransactions(ctx context.Context, api Transactions, params *ListTransactionsParams, opts *TransactionExportOptions) (*TransactionExportResult, error) {
+	if api == nil {
+		return nil, fmt.Errorf("transactions client is required")
+	}
+	if opts == nil {
+		return nil, fmt.Errorf("export options are required")
+	}
+	o := *opts
+	if o.Prefix == "" {
+		o.Prefix = "transactions"
+	}
+	if o.FileRows <= 0 {
+		o.FileRows = DefaultExportFileRows
+	}
+	if o.PageSize <= 0 {
+		o.PageSize = DefaultExportPageSize
+	}
+	if _, err := NewTransactionWriter(io.Discard, o.Format); err != nil {
+		return nil, err
+	}
+	if o.Dir != "" {
+		if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	var query ListTransactionsParams
+	if params != nil {
+		query = *params
+	}
+	if query.PageSize == nil {
+		query.PageSize = &o.PageSize
+	}
+
+	e := &transactionExport{opts: &o, result: &TransactionExportResult{}}
+	defer e.abort()
+	for page := 1; ; page++ {
+		if page > maxExportPages {
+			return nil, fmt.Errorf("gave up after %d pages", maxExportPages)
+		}
+		query.Page = &page
+		resp, err := api.ListTransactions(&query, WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		for i := range resp.Results {
+			rec := FlattenTransaction(&resp.Results[i])
+			if err := e.write(&rec); err != nil {
+				return nil, err
+			}
+		}
+		if !HasNextPage(resp.Next) || len(resp.Results) == 0 {
+			break
+		}
+	}
+	if e.file == nil {
+		if err := e.open(); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.closeFile(); err != nil {
+		return nil, err
+	}
+	e.done = true
+	return e.result, nil
+}
+
+// transactionExport tracks the file being written
+type transactionExport struct {
+	opts   *TransactionExportOptions
+	result *TransactionExportResult
+	file   *os.File
+	writer TransactionWriter
+	rows   int  // rows in the current file
+	done   bool // every file was written and closed
+}
+
+func (e *transactionExport) write(rec *TransactionRecord) error {
+	if e.file != nil && e.rows >= e.opts.FileRows {
+		if err := e.closeFile(); err != nil {
+			return err
+		}
+	}
+	if e.file == nil {
+		if err := e.open(); err != nil {
+			return err
+		}
+	}
+	if err := e.writer.Write(rec); err != nil {
+		return fmt.Errorf("%s: %w", e.file.Name(), err)
+	}
+	e.rows++
+	e.result.Rows++
+	return nil
+}
+
+func (e *transactionExport) open() error {
+	name := filepath.Join(e.opts.Dir, fmt.Sprintf("%s-%04d.%s", e.opts.Prefix, len(e.result.Files)+1, e.opts.Format))
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	e.writer, _ = NewTransactionWriter(f, e.opts.Format)
+	e.file = f
+	e.rows = 0
+	e.result.Files = append(e.result.Files, name)
+	return nil
+}
+
+func (e *transactionExport) closeFile() error {
+	f := e.file
+	e.file = nil
+	if err := e.writer.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return f.Close()
+}
+
+// abort closes and removes the files of a failed export, so a partial
+// export is not mistaken for a complete one
+func (e *transactionExport) abort() {
+	if e.done {
+		return
+	}
+	if e.file != nil {
+		e.file.Close()
+	}
+	for _, name := range e.result.Files {
+		os.Remove(name)
+	}
+}
+
+// HasNextPage reports whether the Next link of a paginated response points
+// to another page. It accepts the *string links of invoices and wallets as
+// well as the untyped links of transactions.
+func HasNextPage(next interface{}) bool {
+	switch v := next.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case *string:
+		return v != nil && *v != ""
+	}
+	return true
+}
@@ -0,0 +1,384 @@
+package intasend
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubTransactions serves txs in pages of the requested size
+type stubTransactions struct {
+	txs      []Result
+	params   []ListTransactionsParams
+	failPage int  // page that fails, if any
+	endless  bool // every page links to another
+}
+
+func (s *stubTransactions) ListTransactions(params *ListTransactionsParams, opts ...CallOption) (*TransactionResp, error) {
+	s.params = append(s.params, *params)
+	if *params.Page == s.failPage {
+		return nil, fmt.Errorf("connection reset")
+	}
+	if s.endless {
+		return &TransactionResp{Results: s.txs[:1], Next: "next"}, nil
+	}
+	start := (*params.Page - 1) * *params.PageSize
+	end := min(start+*params.PageSize, len(s.txs))
+	resp := &TransactionResp{Count: int64(len(s.txs)), Results: s.txs[min(start, end):end]}
+	if end < len(s.txs) {
+		resp.Next = "next"
+	}
+	return resp, nil
+}
+
+func (s *stubTransactions) GetTransaction(transactionID string, opts ...CallOption) (*Result, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func exportFixture(n int) []Result {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	txs := make([]Result, n)
+	for i := range txs {
+		txs[i] = Result{
+			TransactionID:  fmt.Sprintf("TXN%d", i+1),
+			Currency:       "KES",
+			Value:          float64(100 * (i + 1)),
+			RunningBalance: float64(100 * (i + 1)),
+			Narrative:      "Payment received",
+			TransType:      TransTypeDeposit,
+			Status:         TransStatusCompleted,
+			CreatedAt:      created.Add(time.Duration(i) * time.Hour),
+			UpdatedAt:      created.Add(time.Duration(i) * time.Hour),
+		}
+		if i%2 == 0 {
+			txs[i].Invoice = &InvoiceTx{
+				InvoiceID:      fmt.Sprintf("INV%d", i+1),
+				State:          StatusComplete,
+				Provider:       "M-PESA",
+				Charges:        3.5,
+				NetAmount:      "96.50",
+				Account:        "254712345678",
+				APIRef:         "order-1",
+				MpesaReference: "QK7ABC123",
+			}
+		}
+	}
+	return txs
+}
+
+func TestExportTransactionsCSV(t *testing.T) {
+	api := &stubTransactions{txs: exportFixture(5)}
+	dir := t.TempDir()
+	params := &ListTransactionsParams{WalletID: "WKES001", DateFrom: "2024-03-01", DateTo: "2024-03-31"}
+
+	res, err := ExportTransactions(context.Background(), api, params, &TransactionExportOptions{Format: ExportCSV, Dir: dir, Prefix: "march", FileRows: 2, PageSize: 3})
+	if err != nil {
+		t.Fatalf("ExportTransactions failed: %v", err)
+	}
+	if res.Rows != 5 || len(res.Files) != 3 || filepath.Base(res.Files[2]) != "march-0003.csv" {
+		t.Fatalf("Expected 5 rows in 3 files, got %+v", res)
+	}
+	if len(api.params) != 2 || api.params[1].WalletID != "WKES001" || api.params[1].DateTo != "2024-03-31" {
+		t.Errorf("Expected the filters on both pages, got %+v", api.params)
+	}
+
+	f, err := os.Open(res.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(TransactionExportColumns(), ",") {
+		t.Fatalf("Expected a header and 2 rows, got %v", rows)
+	}
+	want := "TXN1,2024-03-01T09:30:00Z,2024-03-01T09:30:00Z,DEPOSIT,COMPLETED,KES,100,100,Payment received,INV1,COMPLETE,M-PESA,3.5,96.50,254712345678,order-1,QK7ABC123,"
+	if got := strings.Join(rows[1], ","); got != want {
+		t.Errorf("Unexpected first row:\n got %s\nwant %s", got, want)
+	}
+	if rows[2][9] != "" || rows[2][12] != "0" {
+		t.Errorf("Expected empty invoice fields without an invoice, got %v", rows[2])
+	}
+}
+
+func TestExportTransactionsFailure(t *testing.T) {
+	dir := t.TempDir()
+	api := &stubTransactions{txs: exportFixture(5), failPage: 2}
+	_, err := ExportTransactions(context.Background(), api, nil, &TransactionExportOptions{Format: ExportCSV, Dir: dir, FileRows: 2, PageSize: 3})
+	if err == nil || !strings.Contains(err.Error(), "page 2: connection reset") {
+		t.Fatalf("Expected the page error, got %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected the partial files to be removed, found %d", len(files))
+	}
+
+	endless := &stubTransactions{txs: exportFixture(1), endless: true}
+	_, err = ExportTransactions(context.Background(), endless, nil, &TransactionExportOptions{Format: ExportNDJSON, Dir: dir})
+	if err == nil || !strings.Contains(err.Error(), "gave up after") || len(endless.params) != maxExportPages {
+		t.Errorf("Expected to give up after %d pages, got %v after %d calls", maxExportPages, err, len(endless.params))
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected the partial files to be removed, found %d", len(files))
+	}
+}
+
+func TestExportTransactionsNDJSON(t *testing.T) {
+	dir := t.TempDir()
+	res, err := ExportTransactions(context.Background(), &stubTransactions{txs: exportFixture(3)}, nil, &TransactionExportOptions{Format: ExportNDJSON, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(res.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var recs []TransactionRecord
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec TransactionRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+	if len(recs) != 3 || recs[2].MpesaReference != "QK7ABC123" || recs[1].Provider != "" {
+		t.Errorf("Unexpected records: %+v", recs)
+	}
+
+	empty, err := ExportTransactions(context.Background(), &stubTransactions{}, nil, &TransactionExportOptions{Format: ExportNDJSON, Dir: dir, Prefix: "empty"})
+	if err != nil || len(empty.Files) != 1 || empty.Rows != 0 {
+		t.Errorf("Expected one empty file, got %+v, %v", empty, err)
+	}
+	if _, err := ExportTransactions(context.Background(), &stubTransactions{}, nil, &TransactionExportOptions{Format: "xml"}); err == nil {
+		t.Error("Expected an unknown format to fail")
+	}
+}
+
+func TestExportTransactionsParquet(t *testing.T) {
+	dir := t.TempDir()
+	txs := exportFixture(5)
+	res, err := ExportTransactions(context.Background(), &stubTransactions{txs: txs}, nil, &TransactionExportOptions{Format: ExportParquet, Dir: dir, FileRows: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %v", res.Files)
+	}
+
+	cols, rows := readParquet(t, res.Files[1])
+	if rows != 2 {
+		t.Fatalf("Expected 2 rows in the second file, got %d", rows)
+	}
+	if got := cols["transaction_id"]; fmt.Sprint(got) != "[TXN4 TXN5]" {
+		t.Errorf("Unexpected transaction IDs: %v", got)
+	}
+	if got := cols["charges"]; fmt.Sprint(got) != "[0 3.5]" {
+		t.Errorf("Unexpected charges: %v", got)
+	}
+	if got := cols["created_at"][1]; got != txs[4].CreatedAt.UnixMilli() {
+		t.Errorf("Expected created_at %d, got %v", txs[4].CreatedAt.UnixMilli(), got)
+	}
+	if got := cols["mpesa_reference"]; fmt.Sprint(got) != "[ QK7ABC123]" {
+		t.Errorf("Unexpected mpesa references: %q", got)
+	}
+}
+
+// readParquet decodes a file written by parquetWriter, returning each
+// column's values and the row count from the footer
+func readParquet(t *testing.T, path string) (map[string][]any, int64) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatalf("Missing Parquet magic in %s", path)
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &compactReader{b: data[len(data)-8-size : len(data)-8]}
+	meta := footer.readStruct()
+
+	schema := meta[2].([]any)
+	types := make(map[string]int64)
+	var names []string
+	for _, el := range schema[1:] {
+		el := el.(map[int16]any)
+		names = append(names, el[4].(string))
+		types[el[4].(string)] = el[1].(int64)
+	}
+
+	cols := make(map[string][]any)
+	for _, g := range meta[4].([]any) {
+		for i, c := range g.(map[int16]any)[1].([]any) {
+			md := c.(map[int16]any)[3].(map[int16]any)
+			page := &compactReader{b: data, pos: int(md[9].(int64))}
+			header := page.readStruct()
+			n := int(header[5].(map[int16]any)[1].(int64))
+			values := data[page.pos : page.pos+int(header[3].(int64))]
+			name := names[i]
+			for j := 0; j < n; j++ {
+				switch types[name] {
+				case 6: // BYTE_ARRAY
+					l := int(binary.LittleEndian.Uint32(values))
+					cols[name] = append(cols[name], string(values[4:4+l]))
+					values = values[4+l:]
+				case 5: // DOUBLE
+					cols[name] = append(cols[name], math.Float64frombits(binary.LittleEndian.Uint64(values)))
+					values = values[8:]
+				case 2: // INT64
+					cols[name] = append(cols[name], int64(binary.LittleEndian.Uint64(values)))
+					values = values[8:]
+				}
+			}
+		}
+	}
+	return cols, meta[3].(int64)
+}
+
+// compactReader decodes the Thrift compact protocol into maps keyed by field ID
+type compactReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *compactReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var id int16
+	for {
+		b := r.b[r.pos]
+		r.pos++
+		if b == 0 {
+			return fields
+		}
+		if delta := int16(b >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.readValue(b & 0x0f)
+	}
+}
+
+func (r *compactReader) readValue(typ byte) any {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 4, 5, 6:
+		return r.zigzag()
+	case 8:
+		n := int(r.uvarint())
+		s := string(r.b[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case 9:
+		h := r.b[r.pos]
+		r.pos++
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = r.readValue(h & 0x0f)
+		}
+		return list
+	case 12:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unsupported thrift type %d", typ))
+}
+
+// testdata/parquet-go.parquet was written by github.com/xitongsys/parquet-go
+// v1.6.2, uncompressed and PLAIN encoded, from rows of
+//
+//	struct {
+//		ID        string  `parquet:"name=transaction_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN"`
+//		CreatedAt int64   `parquet:"name=created_at, type=INT64, convertedtype=TIMESTAMP_MILLIS, encoding=PLAIN"`
+//		Value     float64 `parquet:"name=value, type=DOUBLE, encoding=PLAIN"`
+//	}
+//
+// parquetWriter must describe the same columns and values as that file.
+func TestParquetWriterMatchesParquetGo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ours.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newParquetWriter(f, []parquetColumn{{"transaction_id", parquetString}, {"created_at", parquetTimestamp}, {"value", parquetDouble}})
+	for _, row := range [][]any{
+		{"TXN1", time.UnixMilli(1709283600000), 1000.0},
+		{"TXN2", time.UnixMilli(1709287200000), -35.5},
+		{"", time.UnixMilli(1709370000000), 0.25},
+	} {
+		if err := p.writeRow(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// Compare type, repetition, name, children and converted type of every
+	// schema element; parquet-go also writes logical types, which we omit
+	golden, ours := readParquetSchema(t, "testdata/parquet-go.parquet"), readParquetSchema(t, path)
+	if len(golden) != len(ours) {
+		t.Fatalf("Expected %d schema elements, got %d", len(golden), len(ours))
+	}
+	for i := 1; i < len(golden); i++ {
+		for _, id := range []int16{1, 3, 4, 5, 6} {
+			if golden[i][id] != ours[i][id] {
+				t.Errorf("Schema element %d field %d: got %v, want %v", i, id, ours[i][id], golden[i][id])
+			}
+		}
+	}
+	if golden[0][5] != ours[0][5] {
+		t.Errorf("Expected %v root children, got %v", golden[0][5], ours[0][5])
+	}
+
+	wantCols, wantRows := readParquet(t, "testdata/parquet-go.parquet")
+	gotCols, gotRows := readParquet(t, path)
+	if gotRows != wantRows || fmt.Sprint(gotCols) != fmt.Sprint(wantCols) {
+		t.Errorf("Got %d rows %v, want %d rows %v", gotRows, gotCols, wantRows, wantCols)
+	}
+}
+
+// readParquetSchema returns the schema elements from a file's footer
+func readParquetSchema(t *testing.T, path string) []map[int16]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := (&compactReader{b: data[len(data)-8-size : len(data)-8]}).readStruct()
+	var schema []map[int16]any
+	for _, el := range meta[2].([]any) {
+		schema = append(schema, el.(map[int16]any))
+	}
+	return schema
+}
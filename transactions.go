@@ -38,7 +38,19 @@ const (
 )
 
 // ListTransactions retrieves a paginated list of all transactions with optional filters
-func (c *Client) ListTransactions(params *ListTransactionsParams) (*TransactionResp, error) {
+func (c *Client) ListTransactions(params *ListTransactionsParams, opts ...CallOption) (*TransactionResp, error) {
+	return invoke(c, &Invocation{Operation: OpTransactionsList, Request: params}, opts,
+		func(inv *Invocation, opts []CallOption) (*TransactionResp, error) {
+			params, err := requestAs[*ListTransactionsParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listTransactions(params, opts)
+		})
+}
+
+// listTransactions builds the query and fetches one page of transactions
+func (c *Client) listTransactions(params *ListTransactionsParams, opts []CallOption) (*TransactionResp, error) {
 	if c.Token == "" {
 		return nil, fmt.Errorf("token is required to list transactions")
 	}
@@ -83,7 +95,12 @@ func (c *Client) ListTransactions(params *ListTransactionsParams) (*TransactionR
 
 	// Use the HTTP wrapper to make the request
 	var result TransactionResp
-	err := c.GetJSON("api/v1/transactions/", queryParams, true, &result)
+	err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    "api/v1/transactions/",
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +109,15 @@ func (c *Client) ListTransactions(params *ListTransactionsParams) (*TransactionR
 }
 
 // GetTransaction retrieves a single transaction by its ID
-func (c *Client) GetTransaction(transactionID string) (*Result, error) {
+func (c *Client) GetTransaction(transactionID string, opts ...CallOption) (*Result, error) {
+	return invoke(c, &Invocation{Operation: OpTransactionGet, ResourceID: transactionID}, opts,
+		func(inv *Invocation, opts []CallOption) (*Result, error) {
+			return c.getTransaction(inv.ResourceID, opts)
+		})
+}
+
+// getTransaction fetches a single transaction
+func (c *Client) getTransaction(transactionID string, opts []CallOption) (*Result, error) {
 	if c.Token == "" {
 		return nil, fmt.Errorf("token is required to get transaction")
 	}
@@ -103,7 +128,7 @@ func (c *Client) GetTransaction(transactionID string) (*Result, error) {
 	// Use the common HTTP JSON helper
 	endpoint := fmt.Sprintf("api/v1/transactions/%s/", url.PathEscape(transactionID))
 	var transaction Result
-	if err := c.GetJSON(endpoint, nil, true, &transaction); err != nil {
+	if err := c.call(&RequestOptions{Method: GET, Endpoint: endpoint, UseToken: true}, &transaction, opts); err != nil {
 		return nil, err
 	}
 
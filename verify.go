@@ -0,0 +1,158 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// verifyInvoiceID is looked up to exercise publishable-key auth; IntaSend
+// answers "not found" for it once the key itself is accepted
+const verifyInvoiceID = "INTASEND-SDK-VERIFY"
+
+// CheckResult is the outcome of one credential check
+type CheckResult string
+
+// Credential check outcomes
+const (
+	CheckPassed       CheckResult = "passed"       // IntaSend accepted the credential
+	CheckRejected     CheckResult = "rejected"     // IntaSend answered 401 or 403
+	CheckMismatch     CheckResult = "mismatch"     // the key belongs to the other environment; nothing was sent
+	CheckSkipped      CheckResult = "skipped"      // the credential is not configured
+	CheckInconclusive CheckResult = "inconclusive" // transport error or unexpected response
+)
+
+// CredentialCheck reports how one credential fared against IntaSend
+type CredentialCheck struct {
+	Credential     string        // "publishable key" or "token"
+	Result         CheckResult   // outcome of the check
+	KeyEnvironment Environment   // environment implied by the key prefix
+	Endpoint       string        // endpoint used for the probe
+	StatusCode     int           // HTTP status of the probe, 0 if nothing was received
+	RequestID      string        // IntaSend request ID of the probe, if any
+	Latency        time.Duration // round-trip time of the probe
+	Err            error         // why the check did not pass, nil if it did
+}
+
+// CredentialsReport is the result of VerifyCredentials
+type CredentialsReport struct {
+	Environment    Environment // environment the client targets
+	BaseURL        string
+	APIBaseURL     string
+	PublishableKey CredentialCheck
+	Token          CredentialCheck
+}
+
+// OK reports whether every configured credential passed and at least one was checked
+func (r *CredentialsReport) OK() bool {
+	checked := false
+	for _, check := range []CredentialCheck{r.PublishableKey, r.Token} {
+		switch check.Result {
+		case CheckPassed:
+			checked = true
+		case CheckSkipped:
+		default:
+			return false
+		}
+	}
+	return checked
+}
+
+// Problems lists a human-readable line for each check that did not pass
+func (r *CredentialsReport) Problems() []string {
+	var problems []string
+	for _, check := range []CredentialCheck{r.PublishableKey, r.Token} {
+		if check.Result != CheckPassed && check.Result != CheckSkipped {
+			problems = append(problems, fmt.Sprintf("%s %s: %v", check.Credential, check.Result, check.Err))
+		}
+	}
+	return problems
+}
+
+// VerifyCredentials confirms that IntaSend accepts the client's publishable
+// key and token using cheap read-only requests. The report is returned even
+// when verification fails; the error then summarises its Problems.
+func (c *Client) VerifyCredentials(ctx context.Context) (*CredentialsReport, error) {
+	report := &CredentialsReport{
+		Environment: c.Environment(),
+		BaseURL:     c.BaseURL,
+		APIBaseURL:  c.APIBaseURL,
+	}
+
+	report.PublishableKey = c.probe(ctx, "publishable key", c.PublishableKey, true, &RequestOptions{
+		Method:    POST,
+		Endpoint:  "api/v1/payment/status/",
+		Body:      map[string]string{"invoice_id": verifyInvoiceID},
+		UseAPIKey: true,
+	})
+	report.Token = c.probe(ctx, "token", c.Token, false, &RequestOptions{
+		Method:      GET,
+		Endpoint:    "api/v1/wallets/",
+		QueryParams: map[string]string{"page_size": "1"},
+		UseToken:    true,
+	})
+
+	if report.OK() {
+		return report, nil
+	}
+	if problems := report.Problems(); len(problems) > 0 {
+		return report, fmt.Errorf("credential verification failed: %s", strings.Join(problems, "; "))
+	}
+	return report, fmt.Errorf("credential verification failed: no credentials configured")
+}
+
+// Ping verifies the client's credentials, returning nil if IntaSend accepts them
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.VerifyCredentials(ctx)
+	return err
+}
+
+// probe sends one verification request and classifies the outcome. With
+// lookupProbe, 404 also counts as accepted: the request addresses an invoice
+// that does not exist, so getting that far means auth succeeded. A 400 proves
+// nothing, since it may come from validation that runs before auth.
+func (c *Client) probe(ctx context.Context, name, key string, lookupProbe bool, opts *RequestOptions) CredentialCheck {
+	check := CredentialCheck{
+		Credential:     name,
+		KeyEnvironment: KeyEnvironment(key),
+		Endpoint:       opts.Endpoint,
+	}
+	if key == "" {
+		check.Result = CheckSkipped
+		return check
+	}
+
+	// Verification always checks the key's environment, StrictKeys or not
+	opts.Context = ctx
+	err := c.checkRequestKeys(opts)
+	var resp *HTTPResponse
+	if err == nil {
+		resp, err = c.DoRequest(opts)
+	}
+	var mismatch *KeyMismatchError
+	switch {
+	case errors.As(err, &mismatch):
+		check.Result, check.Err = CheckMismatch, err
+		return check
+	case err != nil:
+		check.Result, check.Err = CheckInconclusive, err
+		return check
+	}
+
+	check.StatusCode = resp.StatusCode
+	check.RequestID = resp.RequestID()
+	check.Latency = resp.Latency
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		check.Result, check.Err = CheckRejected, c.handleErrorResponse(resp)
+	case resp.StatusCode < 300,
+		lookupProbe && resp.StatusCode == http.StatusNotFound:
+		check.Result = CheckPassed
+	default:
+		check.Result, check.Err = CheckInconclusive, c.handleErrorResponse(resp)
+	}
+	return check
+}
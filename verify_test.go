@@ -0,0 +1,104 @@
+package intasend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKeyEnvironment(t *testing.T) {
+	cases := map[string]Environment{
+		"ISPubKey_test_123":    EnvironmentSandbox,
+		"ISSecretKey_test_123": EnvironmentSandbox,
+		"ISPubKey_live_123":    EnvironmentLive,
+		"ISSecretKey_live_123": EnvironmentLive,
+		"pk":                   EnvironmentUnknown,
+	}
+	for key, want := range cases {
+		if got := KeyEnvironment(key); got != want {
+			t.Errorf("KeyEnvironment(%q) = %s, want %s", key, got, want)
+		}
+	}
+}
+
+func TestVerifyCredentials(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Request-ID", "req-"+r.Method)
+		switch r.URL.Path {
+		case "/api/v1/payment/status/":
+			if r.Header.Get("X-IntaSend-Public-API-Key") != "ISPubKey_test_good" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"detail":"Invalid public key."}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail":"Invoice not found"}`))
+		case "/api/v1/wallets/":
+			if r.Header.Get("Authorization") != "Bearer ISSecretKey_test_good" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"detail":"Invalid token."}`))
+				return
+			}
+			w.Write([]byte(`{"count":0,"results":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	newClient := func(pk, token string, test bool) *Client {
+		c := NewClient(pk, token, test, false)
+		c.BaseURL, c.APIBaseURL = srv.URL, srv.URL
+		return c
+	}
+	ctx := context.Background()
+
+	report, err := newClient("ISPubKey_test_good", "ISSecretKey_test_good", true).VerifyCredentials(ctx)
+	if err != nil || !report.OK() {
+		t.Fatalf("Expected valid credentials, got %v (%+v)", err, report)
+	}
+	if report.Token.RequestID != "req-GET" || report.PublishableKey.StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	report, err = newClient("ISPubKey_test_good", "ISSecretKey_test_revoked", true).VerifyCredentials(ctx)
+	if err == nil || report.Token.Result != CheckRejected || report.PublishableKey.Result != CheckPassed {
+		t.Errorf("Expected rejected token, got %v (%+v)", err, report)
+	}
+
+	// A 400 does not show that the key was accepted
+	badRequest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"detail":"Invalid request."}`))
+	}))
+	defer badRequest.Close()
+	c := NewClient("ISPubKey_test_good", "", true, false)
+	c.BaseURL, c.APIBaseURL = badRequest.URL, badRequest.URL
+	report, err = c.VerifyCredentials(ctx)
+	if err == nil || report.PublishableKey.Result != CheckInconclusive || report.PublishableKey.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a 400 to fail verification, got %v (%+v)", err, report)
+	}
+
+	// Live keys against the sandbox fail before anything is sent
+	requests = 0
+	report, err = newClient("ISPubKey_live_abc", "", true).VerifyCredentials(ctx)
+	var mismatch *KeyMismatchError
+	if !errors.As(report.PublishableKey.Err, &mismatch) || mismatch.KeyEnvironment != EnvironmentLive || err == nil {
+		t.Errorf("Expected key mismatch, got %v (%+v)", err, report)
+	}
+	if report.Token.Result != CheckSkipped || requests != 0 {
+		t.Errorf("Expected no requests and a skipped token check, got %d requests (%+v)", requests, report)
+	}
+
+	// Other requests only check keys when asked to
+	strict := newClient("ISPubKey_test_good", "ISSecretKey_live_abc", true)
+	if _, err := strict.ListWallets(nil); errors.As(err, &mismatch) {
+		t.Errorf("Expected ListWallets to reach the server without StrictKeys, got %v", err)
+	}
+	strict.StrictKeys = true
+	if _, err := strict.ListWallets(nil); !errors.As(err, &mismatch) {
+		t.Errorf("Expected ListWallets to fail fast with a key mismatch, got %v", err)
+	}
+}
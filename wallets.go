@@ -7,7 +7,19 @@ import (
 )
 
 // ListWallets retrieves a paginated list of wallets with optional filters
-func (c *Client) ListWallets(params *ListWalletsParams) (*PaginatedWallets, error) {
+func (c *Client) ListWallets(params *ListWalletsParams, opts ...CallOption) (*PaginatedWallets, error) {
+	return invoke(c, &Invocation{Operation: OpWalletsList, Request: params}, opts,
+		func(inv *Invocation, opts []CallOption) (*PaginatedWallets, error) {
+			params, err := requestAs[*ListWalletsParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listWallets(params, opts)
+		})
+}
+
+// listWallets builds the query and fetches one page of wallets
+func (c *Client) listWallets(params *ListWalletsParams, opts []CallOption) (*PaginatedWallets, error) {
 	if c.Token == "" {
 		return nil, fmt.Errorf("token is required to list wallets")
 	}
@@ -40,7 +52,12 @@ func (c *Client) ListWallets(params *ListWalletsParams) (*PaginatedWallets, erro
 
 	// Use the HTTP wrapper to make the request
 	var result PaginatedWallets
-	err := c.GetJSON("api/v1/wallets/", queryParams, true, &result)
+	err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    "api/v1/wallets/",
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +71,19 @@ type WalletTransactionsParams struct {
 }
 
 // ListWalletTransactions retrieves transactions performed under a specific wallet
-func (c *Client) ListWalletTransactions(walletID string, params *WalletTransactionsParams) (*TransactionResp, error) {
+func (c *Client) ListWalletTransactions(walletID string, params *WalletTransactionsParams, opts ...CallOption) (*TransactionResp, error) {
+	return invoke(c, &Invocation{Operation: OpWalletTransactionsList, Request: params, ResourceID: walletID}, opts,
+		func(inv *Invocation, opts []CallOption) (*TransactionResp, error) {
+			params, err := requestAs[*WalletTransactionsParams](inv)
+			if err != nil {
+				return nil, err
+			}
+			return c.listWalletTransactions(inv.ResourceID, params, opts)
+		})
+}
+
+// listWalletTransactions fetches one page of a wallet statement
+func (c *Client) listWalletTransactions(walletID string, params *WalletTransactionsParams, opts []CallOption) (*TransactionResp, error) {
 	if c.Token == "" {
 		return nil, fmt.Errorf("token is required to list wallet transactions")
 	}
@@ -71,7 +100,12 @@ func (c *Client) ListWalletTransactions(walletID string, params *WalletTransacti
 	// Use the common HTTP JSON helper
 	endpoint := fmt.Sprintf("api/v1/wallets/%s/transactions/", url.PathEscape(walletID))
 	var txns TransactionResp
-	if err := c.GetJSON(endpoint, queryParams, true, &txns); err != nil {
+	if err := c.call(&RequestOptions{
+		Method:      GET,
+		Endpoint:    endpoint,
+		QueryParams: queryParams,
+		UseToken:    true,
+	}, &txns, opts); err != nil {
 		return nil, err
 	}
 	return &txns, nil